@@ -0,0 +1,147 @@
+// Package cache provides a persistent, content-addressed store for fetched context sources.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrMiss is returned by Store.Get when no usable entry exists for a key.
+var ErrMiss = errors.New("cache miss")
+
+// Entry is a cached materialization of a single context source.
+type Entry struct {
+	StoredAt time.Time `json:"stored_at"`
+	Files    []File    `json:"files"`
+}
+
+// File is one file produced by a cached context source. Content is kept as
+// bytes so binary payloads (e.g. URL downloads) survive a round trip.
+type File struct {
+	Path    string `json:"path"`
+	Content []byte `json:"content"`
+}
+
+// Store keeps cache entries as JSON files under a root directory, sharded by
+// the first two characters of the key.
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+// New returns a Store rooted at dir. The directory is created lazily on first write.
+func New(dir string) (*Store, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("cache directory cannot be empty")
+	}
+	return &Store{dir: filepath.Clean(dir), now: time.Now}, nil
+}
+
+// Default returns a Store under the user cache directory (e.g. ~/.cache/osdd/context).
+func Default() (*Store, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve user cache dir: %w", err)
+	}
+	return New(filepath.Join(base, "osdd", "context"))
+}
+
+// Dir returns the root directory of the store.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Key derives a stable cache key from the given parts. Parts are length-prefixed
+// before hashing so that different splits of the same bytes never collide.
+func Key(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		_, _ = fmt.Fprintf(h, "%d:", len(p))
+		_, _ = h.Write(p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the entry stored under key. Entries older than maxAge are treated
+// as a miss; a non-positive maxAge disables expiry.
+func (s *Store) Get(key string, maxAge time.Duration) (*Entry, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache entry %s: %w", p, err)
+	}
+	var e Entry
+	if err := json.Unmarshal(b, &e); err != nil {
+		// A corrupt entry is as good as a missing one; it is rewritten on the next Put.
+		return nil, ErrMiss
+	}
+	if maxAge > 0 && s.now().Sub(e.StoredAt) > maxAge {
+		return nil, ErrMiss
+	}
+	return &e, nil
+}
+
+// Put stores e under key, replacing any previous entry. StoredAt is set to the
+// current time when zero. The write is atomic (temp file + rename).
+func (s *Store) Put(key string, e *Entry) error {
+	if e == nil {
+		return fmt.Errorf("cache entry cannot be nil")
+	}
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if e.StoredAt.IsZero() {
+		e.StoredAt = s.now()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	dir := filepath.Dir(p)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file in %s: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to close cache entry: %w", err)
+	}
+	if err := os.Rename(tmpPath, p); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to store cache entry %s: %w", p, err)
+	}
+	return nil
+}
+
+func (s *Store) path(key string) (string, error) {
+	if len(key) < 3 {
+		return "", fmt.Errorf("invalid cache key %q", key)
+	}
+	if _, err := hex.DecodeString(key); err != nil {
+		return "", fmt.Errorf("invalid cache key %q: must be hex", key)
+	}
+	return filepath.Join(s.dir, key[:2], key+".json"), nil
+}
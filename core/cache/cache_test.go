@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_EmptyDir(t *testing.T) {
+	t.Parallel()
+	_, err := New("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache directory cannot be empty")
+}
+
+func TestKey_StableAndUnambiguous(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, Key([]byte("a"), []byte("b")), Key([]byte("a"), []byte("b")))
+	assert.NotEqual(t, Key([]byte("ab"), []byte("")), Key([]byte("a"), []byte("b")))
+	assert.Len(t, Key([]byte("x")), 64)
+}
+
+func TestStore_PutGet(t *testing.T) {
+	t.Parallel()
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	key := Key([]byte("source"))
+	binary := []byte{0x00, 0xFF, 0x10}
+	require.NoError(t, s.Put(key, &Entry{Files: []File{
+		{Path: "a.md", Content: []byte("hello")},
+		{Path: "b.bin", Content: binary},
+	}}))
+
+	e, err := s.Get(key, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, e.Files, 2)
+	assert.Equal(t, "hello", string(e.Files[0].Content))
+	assert.Equal(t, binary, e.Files[1].Content)
+	assert.False(t, e.StoredAt.IsZero())
+
+	_, err = os.Stat(filepath.Join(s.Dir(), key[:2], key+".json"))
+	require.NoError(t, err)
+}
+
+func TestStore_Miss(t *testing.T) {
+	t.Parallel()
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = s.Get(Key([]byte("nothing")), 0)
+	assert.True(t, errors.Is(err, ErrMiss))
+}
+
+func TestStore_Expiry(t *testing.T) {
+	t.Parallel()
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	key := Key([]byte("ttl"))
+	require.NoError(t, s.Put(key, &Entry{Files: []File{{Path: "x", Content: []byte("v")}}}))
+
+	now = now.Add(2 * time.Hour)
+	_, err = s.Get(key, time.Hour)
+	assert.True(t, errors.Is(err, ErrMiss), "entry older than maxAge should miss")
+
+	e, err := s.Get(key, 0)
+	require.NoError(t, err, "non-positive maxAge disables expiry")
+	assert.Equal(t, "v", string(e.Files[0].Content))
+}
+
+func TestStore_CorruptEntryIsMiss(t *testing.T) {
+	t.Parallel()
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	key := Key([]byte("corrupt"))
+	p := filepath.Join(s.Dir(), key[:2], key+".json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+	require.NoError(t, os.WriteFile(p, []byte("{not json"), 0o644))
+
+	_, err = s.Get(key, 0)
+	assert.True(t, errors.Is(err, ErrMiss))
+}
+
+func TestStore_InvalidKey(t *testing.T) {
+	t.Parallel()
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = s.Get("../../etc/passwd", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid cache key")
+}
@@ -1,10 +1,14 @@
 package core
 
 import (
+	"context"
 	"os"
+	"time"
 
 	"github.com/opensdd/osdd-api/clients/go/osdd"
 	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
+	"github.com/opensdd/osdd-core/core/cache"
+	"github.com/opensdd/osdd-core/core/utils"
 )
 
 type GenerationContext struct {
@@ -30,6 +34,85 @@ type GenerationContext struct {
 	// EnvOverrides supplies values for environment variables without mutating
 	// the process environment. ResolveEnv checks this map first.
 	EnvOverrides map[string]string
+
+	// Cache, when set, stores fetched context source content on disk so repeated
+	// materializations reuse it instead of hitting the network.
+	Cache *cache.Store
+
+	// CacheTTLs overrides the default cache time-to-live per source kind.
+	// A non-positive value disables expiry for that kind. Commands are not
+	// cached by default; see EntryOptions.CacheTTL.
+	CacheTTLs map[SourceKind]time.Duration
+
+	// GitHubTokenEnvVar names the environment variable holding a GitHub token
+	// used for Github context and command sources. With a token, files are read
+	// through the authenticated contents API so private repositories work.
+	GitHubTokenEnvVar string
+
+	// GitLabTokenEnvVar names the environment variable holding a GitLab token
+	// used for context and command sources that reference GitLab file URLs.
+	GitLabTokenEnvVar string
+
+	// GiteaTokenEnvVar names the environment variable holding a Gitea or
+	// Forgejo token used for context and command sources that reference
+	// Gitea file URLs.
+	GiteaTokenEnvVar string
+
+	// Offline serves remote context sources only from Cache and fails on a cache miss.
+	Offline bool
+
+	// CacheRevalidate resolves the git refs of cached Github entries to commit
+	// SHAs even while the entries are fresh, so an advanced branch is fetched
+	// before the TTL expires. Without it, fresh entries are served without
+	// network access.
+	CacheRevalidate bool
+
+	// Persist configures how executable recipes write materialized files into the workspace.
+	Persist PersistOptions
+
+	// ReviewPlan, when set, is called by executable recipes with the persist plan
+	// before any file is written. Returning an error aborts the execution.
+	ReviewPlan func(ctx context.Context, plan *PersistPlan) error
+
+	// RenderTemplates enables Go template rendering of text sources, command
+	// arguments and start prompts against TemplateData. Missing keys are errors.
+	RenderTemplates bool
+
+	// EntryOptions configures individual context entries by path.
+	EntryOptions map[string]*EntryOptions
+
+	// CollectErrors makes context materialization attempt every entry and
+	// return all failures joined. Without it the first failure cancels the
+	// entries still running.
+	CollectErrors bool
+
+	// Concurrency bounds how many context entries are materialized in
+	// parallel. Non-positive values use the default of 5.
+	Concurrency int
+
+	// SourceTimeouts limits how long a single context entry of a source kind
+	// may take. Kinds without a positive timeout are not limited. Exceeding a
+	// timeout fails the entry with a *TimeoutError.
+	SourceTimeouts map[SourceKind]time.Duration
+
+	// TokenBudget caps the estimated token count of the in-memory files produced
+	// by context materialization. Lower-priority files (see EntryOptions.Priority)
+	// are truncated or dropped to fit. Non-positive values disable the budget.
+	TokenBudget int
+
+	// Observer, when set, receives progress events for prefetch, context
+	// entries, IDE materialization, persist and launch.
+	Observer Observer
+
+	// DeferWrites makes context sources that write into the workspace during
+	// materialization (URL downloads) return file entries instead, so that
+	// persisting writes them and they show up in persist plans, secret scans
+	// and the manifest. Executable recipes always defer writes.
+	DeferWrites bool
+
+	// MaterializationReport is set by context materialization to the outcome of
+	// every entry. It is populated on failure as well.
+	MaterializationReport *MaterializationReport
 }
 
 func (g *GenerationContext) GetPrefetched() map[string]*osdd.FetchedData {
@@ -56,3 +139,23 @@ func (g *GenerationContext) ResolveEnv(key string) string {
 	}
 	return os.Getenv(key)
 }
+
+// GitHubToken returns the GitHub token named by GitHubTokenEnvVar, or "" when unset.
+func (g *GenerationContext) GitHubToken() string {
+	if g == nil || g.GitHubTokenEnvVar == "" {
+		return ""
+	}
+	return g.ResolveEnv(g.GitHubTokenEnvVar)
+}
+
+// GitFileTokens returns the provider tokens used to fetch single files from git hosts.
+func (g *GenerationContext) GitFileTokens() utils.GitFileTokens {
+	tokens := utils.GitFileTokens{GitHub: g.GitHubToken()}
+	if g != nil && g.GitLabTokenEnvVar != "" {
+		tokens.GitLab = g.ResolveEnv(g.GitLabTokenEnvVar)
+	}
+	if g != nil && g.GiteaTokenEnvVar != "" {
+		tokens.Gitea = g.ResolveEnv(g.GiteaTokenEnvVar)
+	}
+	return tokens
+}
@@ -0,0 +1,276 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change.
+const diffContextLines = 3
+
+type diffOpKind byte
+
+const (
+	diffEqual  diffOpKind = ' '
+	diffDelete diffOpKind = '-'
+	diffInsert diffOpKind = '+'
+)
+
+type diffOp struct {
+	kind diffOpKind
+	line string
+}
+
+// UnifiedDiff renders the difference between oldText and newText in unified
+// diff format with the given file labels (e.g. "a/x.md", "b/x.md", or
+// "/dev/null" for a missing side). It returns an empty string when the texts are equal.
+// Binary content (see IsBinaryContent) is summarized in a single line, as git does.
+func UnifiedDiff(oldLabel, newLabel, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	if IsBinaryContent(oldText) || IsBinaryContent(newText) {
+		return fmt.Sprintf("Binary files %s and %s differ\n", oldLabel, newLabel)
+	}
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldLabel, newLabel)
+	for _, h := range buildHunks(ops) {
+		writeHunk(&b, ops[h.start:h.end], h.oldStart, h.newStart)
+	}
+	return b.String()
+}
+
+// splitLines splits text into lines, keeping the trailing newline on each line.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a shortest edit script between a and b with the
+// linear-space variant of Myers' algorithm: the middle snake of the edit graph
+// splits the problem in two, so memory stays O(len(a)+len(b)) however much the
+// texts differ. Within a run of changes, deletions precede insertions.
+func diffLines(a, b []string) []diffOp {
+	// Compare line ids instead of strings.
+	ids := map[string]int{}
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, l := range lines {
+			id, ok := ids[l]
+			if !ok {
+				id = len(ids)
+				ids[l] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	d := &differ{a: a, b: b, ai: intern(a), bi: intern(b)}
+	d.compare(0, len(a), 0, len(b))
+	deletionsFirst(d.ops)
+	return d.ops
+}
+
+type differ struct {
+	a, b   []string
+	ai, bi []int
+	ops    []diffOp
+}
+
+// compare appends the edit script of a[aLo:aHi] to b[bLo:bHi].
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.ai[aLo] == d.bi[bLo] {
+		d.ops = append(d.ops, diffOp{kind: diffEqual, line: d.a[aLo]})
+		aLo++
+		bLo++
+	}
+	var suffix int
+	for aLo < aHi && bLo < bHi && d.ai[aHi-1] == d.bi[bHi-1] {
+		aHi--
+		bHi--
+		suffix++
+	}
+	switch {
+	case aLo == aHi:
+		for _, line := range d.b[bLo:bHi] {
+			d.ops = append(d.ops, diffOp{kind: diffInsert, line: line})
+		}
+	case bLo == bHi:
+		for _, line := range d.a[aLo:aHi] {
+			d.ops = append(d.ops, diffOp{kind: diffDelete, line: line})
+		}
+	default:
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.compare(aLo, x, bLo, y)
+		for ; x < u; x, y = x+1, y+1 {
+			d.ops = append(d.ops, diffOp{kind: diffEqual, line: d.a[x]})
+		}
+		d.compare(u, aHi, v, bHi)
+	}
+	for i := aHi; i < aHi+suffix; i++ {
+		d.ops = append(d.ops, diffOp{kind: diffEqual, line: d.a[i]})
+	}
+}
+
+// middleSnake returns the middle snake (x, y) → (u, v) of a shortest edit
+// path between a[aLo:aHi] and b[bLo:bHi], searching forward from the start
+// and backward from the end until the two searches overlap. Both ranges must
+// be non-empty.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta&1 != 0
+	maxD := (n + m + 1) / 2
+	offset := maxD + max(delta, -delta) + 1
+	vf := make([]int, 2*offset+1) // furthest x on forward diagonal k
+	vb := make([]int, 2*offset+1) // nearest x on backward diagonal k
+	vf[offset+1] = 0
+	vb[offset+delta-1] = n
+	a, b := d.ai[aLo:aHi], d.bi[bLo:bHi]
+	for step := 0; step <= maxD; step++ {
+		for k := -step; k <= step; k += 2 {
+			var px int
+			if k == -step || (k != step && vf[offset+k-1] < vf[offset+k+1]) {
+				px = vf[offset+k+1]
+			} else {
+				px = vf[offset+k-1] + 1
+			}
+			px0, py0 := px, px-k
+			py := py0
+			for px < n && py < m && a[px] == b[py] {
+				px++
+				py++
+			}
+			vf[offset+k] = px
+			if odd && k >= delta-(step-1) && k <= delta+(step-1) && px >= vb[offset+k] {
+				return aLo + px0, bLo + py0, aLo + px, bLo + py
+			}
+		}
+		for k := delta - step; k <= delta+step; k += 2 {
+			var px int
+			if k == delta+step || (k != delta-step && vb[offset+k-1] < vb[offset+k+1]) {
+				px = vb[offset+k-1]
+			} else {
+				px = vb[offset+k+1] - 1
+			}
+			px0, py0 := px, px-k
+			py := py0
+			for px > 0 && py > 0 && a[px-1] == b[py-1] {
+				px--
+				py--
+			}
+			vb[offset+k] = px
+			if !odd && k >= -step && k <= step && px <= vf[offset+k] {
+				return aLo + px, bLo + py, aLo + px0, bLo + py0
+			}
+		}
+	}
+	panic("diff: no middle snake")
+}
+
+// deletionsFirst moves the deletions of every run of changes before its
+// insertions.
+func deletionsFirst(ops []diffOp) {
+	for i := 0; i < len(ops); {
+		if ops[i].kind == diffEqual {
+			i++
+			continue
+		}
+		j := i
+		for j < len(ops) && ops[j].kind != diffEqual {
+			j++
+		}
+		run := ops[i:j]
+		sort.SliceStable(run, func(x, y int) bool { return run[x].kind == diffDelete && run[y].kind == diffInsert })
+		i = j
+	}
+}
+
+type diffHunk struct {
+	start, end         int // range in ops
+	oldStart, newStart int // 0-based line positions of ops[start]
+}
+
+// buildHunks groups changed ops with diffContextLines of surrounding context,
+// merging hunks whose context overlaps.
+func buildHunks(ops []diffOp) []diffHunk {
+	var hunks []diffHunk
+	oldLine, newLine := 0, 0
+	oldAt := make([]int, len(ops)+1)
+	newAt := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldAt[i], newAt[i] = oldLine, newLine
+		if op.kind != diffInsert {
+			oldLine++
+		}
+		if op.kind != diffDelete {
+			newLine++
+		}
+	}
+	oldAt[len(ops)], newAt[len(ops)] = oldLine, newLine
+
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == diffEqual {
+			continue
+		}
+		start := max(0, i-diffContextLines)
+		end := i + 1
+		for j := i + 1; j < len(ops); j++ {
+			if ops[j].kind != diffEqual {
+				end = j + 1
+				continue
+			}
+			if j-end >= 2*diffContextLines {
+				break
+			}
+		}
+		end = min(len(ops), end+diffContextLines)
+		if n := len(hunks); n > 0 && start <= hunks[n-1].end {
+			hunks[n-1].end = end
+		} else {
+			hunks = append(hunks, diffHunk{start: start, end: end, oldStart: oldAt[start], newStart: newAt[start]})
+		}
+		i = end - 1
+	}
+	return hunks
+}
+
+func writeHunk(b *strings.Builder, ops []diffOp, oldStart, newStart int) {
+	oldCount, newCount := 0, 0
+	for _, op := range ops {
+		if op.kind != diffInsert {
+			oldCount++
+		}
+		if op.kind != diffDelete {
+			newCount++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, op := range ops {
+		b.WriteByte(byte(op.kind))
+		b.WriteString(op.line)
+		if !strings.HasSuffix(op.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a hunk range; empty ranges point at the line before them.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
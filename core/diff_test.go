@@ -0,0 +1,144 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "equal texts produce no diff",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name: "create from empty",
+			old:  "",
+			new:  "a\nb\n",
+			want: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "single line change with context",
+			old:  "1\n2\n3\n4\n5\n",
+			new:  "1\n2\nX\n4\n5\n",
+			want: "--- old\n+++ new\n@@ -1,5 +1,5 @@\n 1\n 2\n-3\n+X\n 4\n 5\n",
+		},
+		{
+			name: "missing trailing newline is marked",
+			old:  "a\n",
+			new:  "a\nb",
+			want: "--- old\n+++ new\n@@ -1 +1,2 @@\n a\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			name: "binary content is summarized",
+			old:  "",
+			new:  "\x89PNG\r\n\x1a\n\x00\x00",
+			want: "Binary files old and new differ\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, UnifiedDiff("old", "new", tt.old, tt.new))
+		})
+	}
+}
+
+func TestUnifiedDiff_SplitsDistantHunks(t *testing.T) {
+	t.Parallel()
+	var oldLines, newLines []string
+	for i := 0; i < 20; i++ {
+		line := string(rune('a' + i))
+		oldLines = append(oldLines, line)
+		switch i {
+		case 1:
+			newLines = append(newLines, "X")
+		case 18:
+			newLines = append(newLines, "Y")
+		default:
+			newLines = append(newLines, line)
+		}
+	}
+	diff := UnifiedDiff("old", "new", strings.Join(oldLines, "\n")+"\n", strings.Join(newLines, "\n")+"\n")
+	assert.Equal(t, 2, strings.Count(diff, "@@ -"))
+	assert.Contains(t, diff, "@@ -1,5 +1,5 @@\n a\n-b\n+X\n c\n d\n e\n")
+	assert.Contains(t, diff, "@@ -16,5 +16,5 @@\n p\n q\n r\n-s\n+Y\n t\n")
+}
+
+func TestUnifiedDiff_MergesNearbyHunks(t *testing.T) {
+	t.Parallel()
+	diff := UnifiedDiff("old", "new", "1\n2\n3\n4\n5\n6\n7\n8\n", "X\n2\n3\n4\n5\n6\n7\nY\n")
+	assert.Equal(t, 1, strings.Count(diff, "@@ -"))
+	assert.Contains(t, diff, "@@ -1,8 +1,8 @@\n")
+}
+
+func TestUnifiedDiff_LargeRewrite(t *testing.T) {
+	t.Parallel()
+	var oldText, newText strings.Builder
+	for i := 0; i < 4000; i++ {
+		fmt.Fprintf(&oldText, "old line %d\n", i)
+		fmt.Fprintf(&newText, "new line %d\n", i)
+	}
+	diff := UnifiedDiff("old", "new", oldText.String(), newText.String())
+	assert.True(t, strings.HasPrefix(diff, "--- old\n+++ new\n@@ -1,4000 +1,4000 @@\n-old line 0\n"), diff[:min(len(diff), 100)])
+	assert.Equal(t, 4000, strings.Count(diff, "\n-old line"))
+	assert.Equal(t, 4000, strings.Count(diff, "\n+new line"))
+}
+
+func TestDiffLines_ShortestScript(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct{ a, b string }{
+		{"abcabba", "cbabac"},
+		{"xaxbxcx", "abc"},
+		{"abc", "xaxbxcx"},
+		{"aaaa", "aa"},
+		{"abcdef", "fedcba"},
+		{"", "abc"},
+	} {
+		a, b := strings.Split(tt.a, ""), strings.Split(tt.b, "")
+		ops := diffLines(a, b)
+		var gotA, gotB []string
+		changes := 0
+		for _, op := range ops {
+			if op.kind != diffInsert {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != diffDelete {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind != diffEqual {
+				changes++
+			}
+		}
+		assert.Equal(t, strings.Join(a, ""), strings.Join(gotA, ""), tt.a)
+		assert.Equal(t, strings.Join(b, ""), strings.Join(gotB, ""), tt.b)
+		assert.Equal(t, len(a)+len(b)-2*lcsLen(a, b), changes, "%s → %s", tt.a, tt.b)
+	}
+}
+
+// lcsLen returns the length of the longest common subsequence of a and b.
+func lcsLen(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(cur[j], prev[j+1])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
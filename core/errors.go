@@ -0,0 +1,30 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TimeoutError is returned when a context entry exceeds its per-source timeout
+// (see GenerationContext.SourceTimeouts). Use errors.As to tell it apart from
+// failures of the source itself.
+type TimeoutError struct {
+	Path    string
+	Kind    SourceKind
+	Timeout time.Duration
+	// Err is the error the source returned when its deadline expired.
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s source at %s timed out after %s: %v", e.Kind, e.Path, e.Timeout, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// Is reports context.DeadlineExceeded as matching, so errors.Is works even when
+// the source did not wrap the context error.
+func (e *TimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
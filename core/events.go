@@ -0,0 +1,76 @@
+package core
+
+import (
+	"time"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+)
+
+// EventType identifies a materialization or launch step reported to an Observer.
+type EventType string
+
+const (
+	EventPrefetchStart       EventType = "prefetch_start"
+	EventPrefetchEnd         EventType = "prefetch_end"
+	EventEntryStart          EventType = "entry_start"
+	EventEntryProgress       EventType = "entry_progress"
+	EventEntryFinish         EventType = "entry_finish"
+	EventIDEMaterializeStart EventType = "ide_materialize_start"
+	EventIDEMaterializeEnd   EventType = "ide_materialize_end"
+	EventPersistStart        EventType = "persist_start"
+	EventPersistEnd          EventType = "persist_end"
+	EventLaunchStart         EventType = "launch_start"
+	EventLaunchEnd           EventType = "launch_end"
+)
+
+// Event describes a step of recipe materialization or launch. Fields that do
+// not apply to an event type are left zero.
+type Event struct {
+	Type EventType
+	Time time.Time
+	// Path and Kind identify the context entry of entry events.
+	Path string
+	Kind SourceKind
+	// Bytes is the amount of content produced so far (progress) or in total (end/finish).
+	Bytes int64
+	// TotalBytes is the expected size for progress events, or -1 when unknown.
+	TotalBytes int64
+	// Duration is set on end/finish events and measures the step since its start event.
+	Duration time.Duration
+	// Outcome is set on entry finish events.
+	Outcome EntryOutcome
+	Err     error
+}
+
+// Observer receives events during materialization and launch. OnEvent is
+// called from multiple goroutines when entries materialize in parallel and
+// should return quickly.
+type Observer interface {
+	OnEvent(Event)
+}
+
+// ObserverFunc adapts a function to the Observer interface.
+type ObserverFunc func(Event)
+
+func (f ObserverFunc) OnEvent(e Event) { f(e) }
+
+// Emit sends e to the configured Observer, stamping its time. It is a no-op
+// without an observer.
+func (g *GenerationContext) Emit(e Event) {
+	if g == nil || g.Observer == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	g.Observer.OnEvent(e)
+}
+
+// ContentBytes sums the file content sizes of result.
+func ContentBytes(result *osdd.MaterializedResult) int64 {
+	var total int64
+	for _, e := range result.GetEntries() {
+		total += int64(len(e.GetFile().GetContent()))
+	}
+	return total
+}
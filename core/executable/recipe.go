@@ -3,6 +3,7 @@ package executable
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/opensdd/osdd-api/clients/go/osdd"
 	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
@@ -13,6 +14,8 @@ import (
 type RecipeExecutionResult struct {
 	// LaunchResult is the result of launching the IDE.
 	LaunchResult LaunchResult
+	// Persist describes the files written into the workspace.
+	Persist *core.PersistReport
 }
 
 func ForRecipe(recipe *recipes.ExecutableRecipe) *Recipe {
@@ -42,6 +45,11 @@ func (r *Recipe) Materialize(ctx context.Context, genCtx *core.GenerationContext
 		return nil, fmt.Errorf("failed to materialize workspace: %w", err)
 	}
 	genCtx.WorkspacePath = wsPath
+	// Execute persists the result; route URL downloads through it so they are
+	// planned, scanned and tracked like every other file. The caller's
+	// setting is restored afterwards so the mode does not leak.
+	defer func(prev bool) { genCtx.DeferWrites = prev }(genCtx.DeferWrites)
+	genCtx.DeferWrites = true
 	recipeResult, err := rec.Materialize(ctx, genCtx, r.recipe.GetRecipe())
 	if err != nil {
 		return nil, fmt.Errorf("failed to materialize recipe: %w", err)
@@ -60,12 +68,22 @@ func (r *Recipe) Execute(ctx context.Context, genCtx *core.GenerationContext) (R
 	if r.materialized == nil {
 		return RecipeExecutionResult{}, fmt.Errorf("recipe must be materialized first")
 	}
-	root := r.materialized.GetWorkspacePath()
-	if root == "" {
-		root = "."
+	root := r.workspaceRoot()
+	if genCtx.ReviewPlan != nil {
+		plan, err := core.PlanMaterializedResult(ctx, root, r.materialized, genCtx.Persist)
+		if err != nil {
+			return RecipeExecutionResult{}, fmt.Errorf("failed to plan persisting materialized result: %w", err)
+		}
+		if err := genCtx.ReviewPlan(ctx, plan); err != nil {
+			return RecipeExecutionResult{}, fmt.Errorf("persist plan rejected: %w", err)
+		}
 	}
 	// Persist materialized files into the workspace so the IDE can use them.
-	if err := core.PersistMaterializedResult(context.Background(), root, r.materialized); err != nil {
+	genCtx.Emit(core.Event{Type: core.EventPersistStart})
+	start := time.Now()
+	persistReport, err := core.PersistMaterializedResultWithOptions(context.Background(), root, r.materialized, genCtx.Persist)
+	genCtx.Emit(core.Event{Type: core.EventPersistEnd, Bytes: core.ContentBytes(r.materialized), Duration: time.Since(start), Err: err})
+	if err != nil {
 		return RecipeExecutionResult{}, fmt.Errorf("failed to persist materialized result: %w", err)
 	}
 	ideType := r.recipe.GetEntryPoint().GetIdeType()
@@ -75,12 +93,18 @@ func (r *Recipe) Execute(ctx context.Context, genCtx *core.GenerationContext) (R
 	}
 	prompt := execProps.PromptPrefix
 	if !execProps.OmitDefaultPrompt {
-		prompt += getPrompt(r.recipe.GetEntryPoint().GetStart())
+		startPrompt, err := getPrompt(r.recipe.GetEntryPoint().GetStart(), genCtx)
+		if err != nil {
+			return RecipeExecutionResult{}, err
+		}
+		prompt += startPrompt
 	}
 	args := execProps.ExtraArgs
 	if prompt != "" {
 		args = append(args, prompt)
 	}
+	genCtx.Emit(core.Event{Type: core.EventLaunchStart})
+	start = time.Now()
 	launchResult, err := LaunchIDE(ctx, LaunchParams{
 		IDE:           ideType,
 		IDEPath:       genCtx.IDEPaths[ideType],
@@ -88,21 +112,44 @@ func (r *Recipe) Execute(ctx context.Context, genCtx *core.GenerationContext) (R
 		Args:          args,
 		OutputCMDOnly: genCtx.OutputCMDOnly,
 	})
+	genCtx.Emit(core.Event{Type: core.EventLaunchEnd, Duration: time.Since(start), Err: err})
 	if err != nil {
 		return RecipeExecutionResult{}, fmt.Errorf("failed to launch IDE: %w", err)
 	}
-	return RecipeExecutionResult{LaunchResult: launchResult}, nil
+	return RecipeExecutionResult{LaunchResult: launchResult, Persist: persistReport}, nil
+}
+
+// Plan reports what Execute would write into the workspace, with a unified
+// diff against the files currently on disk. Nothing is persisted; URL
+// downloads are deferred by Materialize and planned like other files.
+func (r *Recipe) Plan(ctx context.Context, genCtx *core.GenerationContext) (*core.PersistPlan, error) {
+	if r.materialized == nil {
+		return nil, fmt.Errorf("recipe must be materialized first")
+	}
+	var opts core.PersistOptions
+	if genCtx != nil {
+		opts = genCtx.Persist
+	}
+	return core.PlanMaterializedResult(ctx, r.workspaceRoot(), r.materialized, opts)
+}
+
+func (r *Recipe) workspaceRoot() string {
+	if root := r.materialized.GetWorkspacePath(); root != "" {
+		return root
+	}
+	return "."
 }
 
-func getPrompt(st *recipes.StartConfig) string {
+// getPrompt returns the start prompt, rendering prompt templates with genCtx.
+func getPrompt(st *recipes.StartConfig, genCtx *core.GenerationContext) (string, error) {
 	if st == nil {
-		return ""
+		return "", nil
 	}
 	switch st.WhichType() {
 	case recipes.StartConfig_Command_case:
-		return fmt.Sprintf("/%v", st.GetCommand())
+		return fmt.Sprintf("/%v", st.GetCommand()), nil
 	case recipes.StartConfig_Prompt_case:
-		return st.GetPrompt()
+		return genCtx.RenderTemplate("start.prompt", st.GetPrompt())
 	}
-	return ""
+	return "", nil
 }
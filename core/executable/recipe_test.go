@@ -2,6 +2,11 @@ package executable
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
@@ -67,3 +72,161 @@ func TestExecutableRecipe_Materialize_Table(t *testing.T) {
 		})
 	}
 }
+
+func planTestRecipe(t *testing.T, workspace string) *Recipe {
+	t.Helper()
+	text := "hello\n"
+	re := ForRecipe(recipes.ExecutableRecipe_builder{
+		Recipe: recipes.Recipe_builder{
+			Context: recipes.Context_builder{Entries: []*recipes.ContextEntry{
+				recipes.ContextEntry_builder{
+					Path: "docs/hello.md",
+					From: recipes.ContextFrom_builder{Text: &text}.Build(),
+				}.Build(),
+			}}.Build(),
+		}.Build(),
+		EntryPoint: recipes.EntryPoint_builder{
+			IdeType: "claude",
+			Workspace: recipes.WorkspaceConfig_builder{
+				Enabled:  true,
+				Path:     workspace,
+				Absolute: true,
+			}.Build(),
+		}.Build(),
+	}.Build())
+	_, err := re.Materialize(context.Background(), &core.GenerationContext{})
+	require.NoError(t, err)
+	return re
+}
+
+func TestExecutableRecipe_Plan(t *testing.T) {
+	t.Parallel()
+	workspace := t.TempDir()
+	re := planTestRecipe(t, workspace)
+
+	plan, err := re.Plan(context.Background(), &core.GenerationContext{})
+	require.NoError(t, err)
+	require.Len(t, plan.Entries, 1)
+	assert.Equal(t, core.PlanCreate, plan.Entries[0].Action)
+	assert.Contains(t, plan.Diff(), "+++ b/docs/hello.md")
+	assert.NoFileExists(t, filepath.Join(workspace, "docs", "hello.md"))
+}
+
+func TestExecutableRecipe_Plan_ListsURLDownloads(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("new spec\n"))
+	}))
+	defer server.Close()
+
+	workspace := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "spec.md"), []byte("old spec\n"), 0o644))
+	re := ForRecipe(recipes.ExecutableRecipe_builder{
+		Recipe: recipes.Recipe_builder{
+			Context: recipes.Context_builder{Entries: []*recipes.ContextEntry{
+				recipes.ContextEntry_builder{
+					Path: "spec.md",
+					From: recipes.ContextFrom_builder{UrlFetch: recipes.UrlSource_builder{Url: server.URL}.Build()}.Build(),
+				}.Build(),
+				recipes.ContextEntry_builder{
+					Path: "docs/api.md",
+					From: recipes.ContextFrom_builder{UrlFetch: recipes.UrlSource_builder{Url: server.URL}.Build()}.Build(),
+				}.Build(),
+			}}.Build(),
+		}.Build(),
+		EntryPoint: recipes.EntryPoint_builder{
+			IdeType: "claude",
+			Workspace: recipes.WorkspaceConfig_builder{
+				Enabled:  true,
+				Path:     workspace,
+				Absolute: true,
+			}.Build(),
+		}.Build(),
+	}.Build())
+	genCtx := &core.GenerationContext{}
+	_, err := re.Materialize(context.Background(), genCtx)
+	require.NoError(t, err)
+	assert.False(t, genCtx.DeferWrites, "the caller's DeferWrites setting must be restored")
+
+	plan, err := re.Plan(context.Background(), genCtx)
+	require.NoError(t, err)
+	actions := map[string]core.PlanAction{}
+	for _, e := range plan.Entries {
+		actions[e.Path] = e.Action
+	}
+	assert.Equal(t, map[string]core.PlanAction{"spec.md": core.PlanUpdate, "docs/api.md": core.PlanCreate}, actions)
+	assert.Contains(t, plan.Diff(), "-old spec\n+new spec\n")
+
+	// Nothing is downloaded into the workspace before persisting.
+	b, err := os.ReadFile(filepath.Join(workspace, "spec.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "old spec\n", string(b))
+	assert.NoFileExists(t, filepath.Join(workspace, "docs", "api.md"))
+}
+
+func TestExecutableRecipe_Plan_RequiresMaterialize(t *testing.T) {
+	t.Parallel()
+	_, err := ForRecipe(recipes.ExecutableRecipe_builder{}.Build()).Plan(context.Background(), &core.GenerationContext{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "recipe must be materialized first")
+}
+
+func TestExecutableRecipe_Execute_ReviewPlanRejects(t *testing.T) {
+	t.Parallel()
+	workspace := t.TempDir()
+	re := planTestRecipe(t, workspace)
+
+	var reviewed *core.PersistPlan
+	_, err := re.Execute(context.Background(), &core.GenerationContext{
+		ReviewPlan: func(_ context.Context, plan *core.PersistPlan) error {
+			reviewed = plan
+			return errors.New("declined")
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "persist plan rejected: declined")
+	require.NotNil(t, reviewed)
+	assert.Equal(t, 1, reviewed.Counts()[core.PlanCreate])
+	assert.NoFileExists(t, filepath.Join(workspace, "docs", "hello.md"))
+}
+
+func TestGetPrompt_RendersTemplates(t *testing.T) {
+	t.Parallel()
+	prompt := "Implement {{.UserInput.ticket}}"
+	st := recipes.StartConfig_builder{Prompt: &prompt}.Build()
+
+	got, err := getPrompt(st, &core.GenerationContext{RenderTemplates: true, UserInput: map[string]string{"ticket": "ABC-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Implement ABC-1", got)
+
+	_, err = getPrompt(st, &core.GenerationContext{RenderTemplates: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "start.prompt")
+
+	got, err = getPrompt(st, &core.GenerationContext{})
+	require.NoError(t, err)
+	assert.Equal(t, prompt, got)
+}
+
+func TestExecutableRecipe_Execute_EmitsEvents(t *testing.T) {
+	t.Parallel()
+	workspace := t.TempDir()
+	re := planTestRecipe(t, workspace)
+
+	var types []core.EventType
+	var persistEnd core.Event
+	_, err := re.Execute(context.Background(), &core.GenerationContext{
+		OutputCMDOnly: true,
+		IDEPaths:      map[string]string{"claude": "claude"},
+		Observer: core.ObserverFunc(func(e core.Event) {
+			types = append(types, e.Type)
+			if e.Type == core.EventPersistEnd {
+				persistEnd = e
+			}
+		}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []core.EventType{core.EventPersistStart, core.EventPersistEnd, core.EventLaunchStart, core.EventLaunchEnd}, types)
+	assert.Equal(t, int64(len("hello\n")), persistEnd.Bytes)
+	assert.NoError(t, persistEnd.Err)
+}
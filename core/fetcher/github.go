@@ -15,13 +15,16 @@ import (
 
 type GitHub struct {
 	Strict bool
+	// Token, when set, authenticates recipe fetches so recipes can be read
+	// from private repositories.
+	Token string
 }
 
-// fetchByURL fetches content from a GitHub URL using utils.FetchGithub.
+// fetchByURL fetches content from a GitHub URL using utils.FetchGithubWithToken.
 // It accepts standard github.com paths and lets utils.ConvertToRawURL resolve raw URLs.
-func fetchByURL(ctx context.Context, url string) (string, error) {
+func fetchByURL(ctx context.Context, url, token string) (string, error) {
 	ref := osdd.GitReference_builder{Path: url}.Build()
-	return utils.FetchGithub(ctx, ref)
+	return utils.FetchGithubWithToken(ctx, ref, token)
 }
 
 // buildGitHubBaseRecipeURL constructs a GitHub URL base (without extension) for the given recipe id according to the rules:
@@ -79,7 +82,7 @@ func (g *GitHub) FetchRecipe(id string) (*recipes.ExecutableRecipe, error) {
 
 	ctx := context.Background()
 	for _, c := range candidates {
-		if s, err := fetchByURL(ctx, c.url); err == nil && s != "" {
+		if s, err := fetchByURL(ctx, c.url, g.Token); err == nil && s != "" {
 			content = s
 			usedYAML = c.yaml
 			break
@@ -1,8 +1,13 @@
 package fetcher
 
 import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/opensdd/osdd-core/core/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -27,3 +32,22 @@ func Test_FetchRecipe_InvalidID(t *testing.T) {
 	_, err := g.FetchRecipe("")
 	assert.Error(t, err)
 }
+
+func Test_FetchRecipe_PrivateRepoWithToken(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/private/contents/opensdd_recipes/my_recipe/recipe.yaml", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		content := base64.StdEncoding.EncodeToString([]byte("recipe:\n  context: {}\n"))
+		_, _ = fmt.Fprintf(w, `{"type":"file","name":"recipe.yaml","encoding":"base64","content":%q}`, content)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	old := utils.ExportGitHubAPIBaseURL()
+	utils.SetGitHubAPIBaseURL(server.URL)
+	t.Cleanup(func() { utils.SetGitHubAPIBaseURL(old) })
+
+	g := &GitHub{Token: "secret"}
+	rec, err := g.FetchRecipe("owner/private/my_recipe")
+	require.NoError(t, err)
+	assert.True(t, rec.HasRecipe())
+}
@@ -0,0 +1,96 @@
+package generators
+
+import (
+	"log/slog"
+	"sort"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"github.com/opensdd/osdd-core/core"
+	"github.com/opensdd/osdd-core/core/utils"
+)
+
+// budgetTruncationMarker is appended to files cut by the token budget so the
+// reader knows the content is incomplete.
+const budgetTruncationMarker = "\n\n[... truncated to fit the context token budget ...]\n"
+
+// budgetFile is a materialized file considered by the token budget enforcer.
+type budgetFile struct {
+	entry    *osdd.MaterializedResult_Entry
+	source   string // path of the context entry that produced the file
+	priority int
+	tokens   int
+}
+
+// enforceTokenBudget keeps files within budget tokens, filling the budget in
+// order of descending priority and then recipe order. A file that does not fit
+// is truncated to the remaining budget, or dropped when too little remains.
+// Non-file entries (directories) and binary files pass through. Entries keep
+// their order.
+func enforceTokenBudget(files []budgetFile, budget int) ([]*osdd.MaterializedResult_Entry, *core.BudgetReport) {
+	report := &core.BudgetReport{Budget: budget}
+	order := make([]int, 0, len(files))
+	for i, f := range files {
+		if !f.entry.HasFile() || core.IsBinaryContent(f.entry.GetFile().GetContent()) {
+			continue
+		}
+		report.Tokens += f.tokens
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return files[order[a]].priority > files[order[b]].priority
+	})
+
+	markerTokens := utils.CountTokens(budgetTruncationMarker)
+	// replaced maps file indexes to their truncated entry, or nil when dropped.
+	replaced := make(map[int]*osdd.MaterializedResult_Entry)
+	remaining := budget
+	for _, i := range order {
+		f := files[i]
+		if f.tokens <= remaining {
+			remaining -= f.tokens
+			report.UsedTokens += f.tokens
+			continue
+		}
+		cut := core.BudgetCut{
+			Path:     f.entry.GetFile().GetPath(),
+			Entry:    f.source,
+			Priority: f.priority,
+			Action:   core.BudgetDropped,
+			Tokens:   f.tokens,
+		}
+		replaced[i] = nil
+		if keep := remaining - markerTokens; keep > 0 {
+			content := utils.TruncateToTokens(f.entry.GetFile().GetContent(), keep) + budgetTruncationMarker
+			cut.Action = core.BudgetTruncated
+			cut.KeptTokens = utils.CountTokens(content)
+			report.UsedTokens += cut.KeptTokens
+			remaining = 0
+			replaced[i] = osdd.MaterializedResult_Entry_builder{
+				File: osdd.FullFileContent_builder{Path: cut.Path, Content: content}.Build(),
+			}.Build()
+		}
+		slog.Warn("Context file cut to fit token budget", "path", cut.Path, "action", cut.Action, "tokens", cut.Tokens, "kept", cut.KeptTokens)
+		report.Cuts = append(report.Cuts, cut)
+	}
+
+	kept := make([]*osdd.MaterializedResult_Entry, 0, len(files))
+	for i, f := range files {
+		e := f.entry
+		if r, ok := replaced[i]; ok {
+			e = r
+		}
+		if e != nil {
+			kept = append(kept, e)
+		}
+	}
+	return kept, report
+}
+
+// fileTokens counts the tokens of a file entry; binary files count as none.
+func fileTokens(e *osdd.MaterializedResult_Entry) int {
+	content := e.GetFile().GetContent()
+	if core.IsBinaryContent(content) {
+		return 0
+	}
+	return utils.CountTokens(content)
+}
@@ -0,0 +1,125 @@
+package generators
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
+	"github.com/opensdd/osdd-core/core"
+	"github.com/opensdd/osdd-core/core/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func budgetFileOf(path, content string, priority int) budgetFile {
+	return budgetFile{
+		entry: osdd.MaterializedResult_Entry_builder{
+			File: osdd.FullFileContent_builder{Path: path, Content: content}.Build(),
+		}.Build(),
+		source:   path,
+		priority: priority,
+		tokens:   utils.CountTokens(content),
+	}
+}
+
+func TestEnforceTokenBudget_FitsWithoutCuts(t *testing.T) {
+	t.Parallel()
+	files := []budgetFile{budgetFileOf("a.md", "alpha beta", 0), budgetFileOf("b.md", "gamma delta", 0)}
+	total := files[0].tokens + files[1].tokens
+
+	kept, report := enforceTokenBudget(files, total)
+	require.Len(t, kept, 2)
+	assert.Empty(t, report.Cuts)
+	assert.Equal(t, total, report.Tokens)
+	assert.Equal(t, total, report.UsedTokens)
+}
+
+func TestEnforceTokenBudget_PrioritizesAndCuts(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("lorem ipsum dolor sit amet ", 200)
+	dir := "repo"
+	files := []budgetFile{
+		budgetFileOf("low.md", long, 0),
+		{entry: osdd.MaterializedResult_Entry_builder{Directory: &dir}.Build(), source: "repo"},
+		budgetFileOf("high.md", long, 10),
+		budgetFileOf("mid.md", long, 5),
+	}
+	budget := files[2].tokens + files[3].tokens/2
+
+	kept, report := enforceTokenBudget(files, budget)
+
+	var paths []string
+	for _, e := range kept {
+		if e.HasFile() {
+			paths = append(paths, e.GetFile().GetPath())
+		} else {
+			paths = append(paths, e.GetDirectory())
+		}
+	}
+	assert.Equal(t, []string{"repo", "high.md", "mid.md"}, paths, "recipe order is preserved")
+	assert.Equal(t, long, kept[1].GetFile().GetContent(), "highest priority file is kept whole")
+	assert.True(t, strings.HasSuffix(kept[2].GetFile().GetContent(), budgetTruncationMarker))
+	assert.Less(t, len(kept[2].GetFile().GetContent()), len(long))
+
+	require.Len(t, report.Cuts, 2)
+	assert.Equal(t, "mid.md", report.Cuts[0].Path)
+	assert.Equal(t, core.BudgetTruncated, report.Cuts[0].Action)
+	assert.Equal(t, 5, report.Cuts[0].Priority)
+	assert.Positive(t, report.Cuts[0].KeptTokens)
+	assert.Equal(t, "low.md", report.Cuts[1].Path)
+	assert.Equal(t, core.BudgetDropped, report.Cuts[1].Action)
+	assert.Zero(t, report.Cuts[1].KeptTokens)
+	assert.LessOrEqual(t, report.UsedTokens, budget)
+	assert.Equal(t, files[0].tokens*3, report.Tokens)
+}
+
+func TestEnforceTokenBudget_BinaryFilesPassThrough(t *testing.T) {
+	t.Parallel()
+	binary := "%PDF-1.7\n\x00\xe2\xe3" + strings.Repeat("\xff\x00", 1000)
+	files := []budgetFile{
+		budgetFileOf("doc.md", "alpha beta", 0),
+		{
+			entry: osdd.MaterializedResult_Entry_builder{
+				File: osdd.FullFileContent_builder{Path: "spec.pdf", Content: binary}.Build(),
+			}.Build(),
+			source: "issues",
+		},
+	}
+
+	kept, report := enforceTokenBudget(files, files[0].tokens)
+	require.Len(t, kept, 2)
+	assert.Equal(t, binary, kept[1].GetFile().GetContent())
+	assert.Empty(t, report.Cuts)
+	assert.Equal(t, files[0].tokens, report.Tokens)
+	assert.Equal(t, 0, fileTokens(kept[1]))
+}
+
+func TestContext_Materialize_TokenBudget(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("context ", 500)
+	genCtx := &core.GenerationContext{
+		TokenBudget:  utils.CountTokens(long),
+		EntryOptions: map[string]*core.EntryOptions{"important.md": {Priority: 1}},
+	}
+	ctxMsg := recipes.Context_builder{Entries: []*recipes.ContextEntry{
+		contextEntry("extra.md", textFrom(long)),
+		contextEntry("important.md", textFrom(long)),
+	}}.Build()
+
+	res, err := (&Context{}).Materialize(context.Background(), ctxMsg, genCtx)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, "important.md", res.GetEntries()[0].GetFile().GetPath())
+
+	budget := genCtx.MaterializationReport.Budget
+	require.NotNil(t, budget)
+	require.Len(t, budget.Cuts, 1)
+	assert.Equal(t, core.BudgetCut{
+		Path:   "extra.md",
+		Entry:  "extra.md",
+		Action: core.BudgetDropped,
+		Tokens: utils.CountTokens(long),
+	}, budget.Cuts[0])
+}
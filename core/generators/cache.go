@@ -0,0 +1,310 @@
+package generators
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
+	"github.com/opensdd/osdd-core/core"
+	"github.com/opensdd/osdd-core/core/cache"
+	"github.com/opensdd/osdd-core/core/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+// cacheKeyVersion is mixed into every cache key; bump it when the cached layout changes.
+const cacheKeyVersion = "context-v1"
+
+// defaultCacheTTLs lists the source kinds served from the cache and how long
+// their content stays fresh. Local sources (text, user input, files) are never
+// cached; local files read by combined entries are part of their cache key.
+// Command output usually changes between runs, so Cmd entries and Combined
+// entries with command items are only cached with EntryOptions.CacheTTL.
+var defaultCacheTTLs = map[core.SourceKind]time.Duration{
+	core.SourceGithub:       24 * time.Hour,
+	core.SourceCombined:     10 * time.Minute,
+	core.SourceUrlFetch:     time.Hour,
+	core.SourceJiraIssues:   15 * time.Minute,
+	core.SourceLinearIssues: 15 * time.Minute,
+	core.SourceGitHistory:   time.Hour,
+}
+
+// sourceKind maps a ContextFrom to its SourceKind.
+func sourceKind(from *recipes.ContextFrom) core.SourceKind {
+	switch from.WhichType() {
+	case recipes.ContextFrom_Text_case:
+		return core.SourceText
+	case recipes.ContextFrom_Cmd_case:
+		return core.SourceCmd
+	case recipes.ContextFrom_Github_case:
+		return core.SourceGithub
+	case recipes.ContextFrom_Combined_case:
+		return core.SourceCombined
+	case recipes.ContextFrom_PrefetchId_case:
+		return core.SourcePrefetchID
+	case recipes.ContextFrom_UserInput_case:
+		return core.SourceUserInput
+	case recipes.ContextFrom_LocalFile_case:
+		return core.SourceLocalFile
+	case recipes.ContextFrom_GitRepo_case:
+		return core.SourceGitRepo
+	case recipes.ContextFrom_JiraIssues_case:
+		return core.SourceJiraIssues
+	case recipes.ContextFrom_LinearIssues_case:
+		return core.SourceLinearIssues
+	case recipes.ContextFrom_GitHistory_case:
+		return core.SourceGitHistory
+	case recipes.ContextFrom_UrlFetch_case:
+		return core.SourceUrlFetch
+	default:
+		return core.SourceUnknown
+	}
+}
+
+// cacheTTL returns the TTL for entry and whether it is cacheable at all.
+func cacheTTL(entry *recipes.ContextEntry, genCtx *core.GenerationContext) (time.Duration, bool) {
+	kind := sourceKind(entry.GetFrom())
+	runsCommands := kind == core.SourceCmd || slices.ContainsFunc(entry.GetFrom().GetCombined().GetItems(), func(item *recipes.CombinedContextSource_Item) bool {
+		return item.WhichType() == recipes.CombinedContextSource_Item_Cmd_case
+	})
+	if entryTTL := genCtx.EntryOptionsFor(entry.GetPath()).CacheTTL; entryTTL > 0 && (runsCommands || defaultCacheTTLs[kind] > 0) {
+		return entryTTL, true
+	}
+	ttl, ok := defaultCacheTTLs[kind]
+	if !ok || runsCommands {
+		return 0, false
+	}
+	if genCtx != nil {
+		if override, ok := genCtx.CacheTTLs[kind]; ok {
+			ttl = override
+		}
+	}
+	return ttl, true
+}
+
+// cacheKey hashes the source definition of entry together with the values it
+// resolves at run time, so a change to any of them produces a different key:
+// user input and prefetched data referenced by combined items, the contents of
+// combined local files, the working directory of commands, and revisions: the
+// commit SHAs of the git refs the entry reads (see gitRevisions).
+func cacheKey(entry *recipes.ContextEntry, genCtx *core.GenerationContext, revisions []string) (string, error) {
+	from := entry.GetFrom()
+	def, err := proto.MarshalOptions{Deterministic: true}.Marshal(from)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal source definition: %w", err)
+	}
+	parts := [][]byte{[]byte(cacheKeyVersion), []byte(sourceKind(from)), []byte(entry.GetPath()), def}
+	if from.WhichType() == recipes.ContextFrom_Cmd_case {
+		parts = append(parts, []byte(workingDir()))
+	}
+	for _, item := range from.GetCombined().GetItems() {
+		switch item.WhichType() {
+		case recipes.CombinedContextSource_Item_PrefetchId_case:
+			parts = append(parts, []byte(genCtx.GetPrefetched()[item.GetPrefetchId()].GetData()))
+		case recipes.CombinedContextSource_Item_UserInput_case:
+			userVals := genCtx.GetUserInput()
+			for _, p := range item.GetUserInput().GetEntries() {
+				parts = append(parts, []byte(p.GetName()), []byte(userVals[p.GetName()]))
+			}
+		case recipes.CombinedContextSource_Item_Cmd_case:
+			parts = append(parts, []byte(workingDir()))
+		case recipes.CombinedContextSource_Item_LocalFile_case:
+			// A missing file fails the fetch; its error still yields a stable part.
+			content, err := os.ReadFile(strings.TrimSpace(item.GetLocalFile()))
+			if err != nil {
+				content = []byte(err.Error())
+			}
+			parts = append(parts, content)
+		}
+	}
+	for _, rev := range revisions {
+		parts = append(parts, []byte(rev))
+	}
+	if genCtx.EntryOptionsFor(entry.GetPath()).HTMLToMarkdown && from.WhichType() == recipes.ContextFrom_UrlFetch_case {
+		parts = append(parts, []byte("html-to-markdown"))
+	}
+	if o := genCtx.EntryOptionsFor(entry.GetPath()).Jira; o != nil && from.WhichType() == recipes.ContextFrom_JiraIssues_case {
+		opts, err := json.Marshal(o)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal jira options: %w", err)
+		}
+		parts = append(parts, opts)
+	}
+	if o := genCtx.EntryOptionsFor(entry.GetPath()).Linear; o != nil && from.WhichType() == recipes.ContextFrom_LinearIssues_case {
+		opts, err := json.Marshal(o)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal linear options: %w", err)
+		}
+		parts = append(parts, opts)
+	}
+	if o := genCtx.EntryOptionsFor(entry.GetPath()).GitHubIssues; o != nil && from.WhichType() == recipes.ContextFrom_GitHistory_case {
+		opts, err := json.Marshal(o)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal github issues options: %w", err)
+		}
+		parts = append(parts, opts)
+	}
+	return cache.Key(parts...), nil
+}
+
+// workingDir returns the directory commands run in.
+func workingDir() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return wd
+}
+
+// gitRevisions resolves the git refs read by entry, from a Github source or
+// combined Github items, to commit SHAs. Refs such as "main" move, so keying
+// on the SHA tells an expired entry whose ref has not advanced from one that
+// must be fetched again. Paths that name no repository yield no revision.
+func gitRevisions(ctx context.Context, entry *recipes.ContextEntry, genCtx *core.GenerationContext) ([]string, error) {
+	var refs []*osdd.GitReference
+	from := entry.GetFrom()
+	if from.WhichType() == recipes.ContextFrom_Github_case {
+		refs = append(refs, from.GetGithub())
+	}
+	for _, item := range from.GetCombined().GetItems() {
+		if item.WhichType() == recipes.CombinedContextSource_Item_Github_case {
+			refs = append(refs, item.GetGithub())
+		}
+	}
+	var revisions []string
+	for _, ref := range refs {
+		rev, err := utils.ResolveGitFileRevision(ctx, ref, genCtx.GitFileTokens())
+		if err != nil {
+			return nil, err
+		}
+		if rev != "" {
+			revisions = append(revisions, rev)
+		}
+	}
+	return revisions, nil
+}
+
+// ErrOffline is wrapped by errors returned when offline mode cannot serve an entry.
+// Cache misses additionally wrap cache.ErrMiss.
+var ErrOffline = errors.New("offline mode")
+
+// withCache serves entry from genCtx.Cache when fresh, otherwise calls fetch and
+// stores its result. In offline mode fetch is never called and a miss is an error.
+// Entries whose source kind is not cacheable pass straight through to fetch.
+//
+// A fresh entry is served without network access. Once it expires, or with
+// genCtx.CacheRevalidate, the git refs the entry reads are resolved to commit
+// SHAs: content cached for the same commits is reused, anything else is
+// fetched again.
+func (c *Context) withCache(ctx context.Context, entry *recipes.ContextEntry, genCtx *core.GenerationContext, fetch func() ([]*osdd.MaterializedResult_Entry, error)) ([]*osdd.MaterializedResult_Entry, error) {
+	kind := sourceKind(entry.GetFrom())
+	ttl, cacheable := cacheTTL(entry, genCtx)
+	if !cacheable || genCtx == nil || (genCtx.Cache == nil && !genCtx.Offline) {
+		return fetch()
+	}
+	if genCtx.Cache == nil {
+		return nil, fmt.Errorf("%w: no cache configured for %s source at %s", ErrOffline, kind, entry.GetPath())
+	}
+
+	key, err := cacheKey(entry, genCtx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if genCtx.Offline || !genCtx.CacheRevalidate {
+		maxAge := ttl
+		if genCtx.Offline {
+			maxAge = 0
+		}
+		cached, err := genCtx.Cache.Get(key, maxAge)
+		switch {
+		case err == nil:
+			slog.Debug("Serving context entry from cache", "path", entry.GetPath(), "kind", kind, "storedAt", cached.StoredAt)
+			return entriesFromCache(cached), nil
+		case !errors.Is(err, cache.ErrMiss):
+			if genCtx.Offline {
+				return nil, fmt.Errorf("%w: failed to read cache for %s source at %s: %w", ErrOffline, kind, entry.GetPath(), err)
+			}
+			slog.Warn("Failed to read context cache, fetching", "path", entry.GetPath(), "error", err)
+		case genCtx.Offline:
+			return nil, fmt.Errorf("%w: no cached content for %s source at %s: %w", ErrOffline, kind, entry.GetPath(), err)
+		}
+	}
+
+	keys := []string{key}
+	revisions, err := gitRevisions(ctx, entry, genCtx)
+	if err != nil {
+		slog.Warn("Failed to resolve git refs, fetching", "path", entry.GetPath(), "error", err)
+	} else if len(revisions) > 0 {
+		revKey, err := cacheKey(entry, genCtx, revisions)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, revKey)
+		// A file at a commit never changes, so its age does not matter.
+		if kind == core.SourceGithub {
+			if cached, err := genCtx.Cache.Get(revKey, 0); err == nil {
+				slog.Debug("Serving context entry from cache, revision unchanged", "path", entry.GetPath(), "kind", kind, "storedAt", cached.StoredAt)
+				result := entriesFromCache(cached)
+				putCache(entry, genCtx, result, key)
+				return result, nil
+			}
+		}
+	}
+
+	result, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	putCache(entry, genCtx, result, keys...)
+	return result, nil
+}
+
+// putCache stores result under keys, logging failures.
+func putCache(entry *recipes.ContextEntry, genCtx *core.GenerationContext, result []*osdd.MaterializedResult_Entry, keys ...string) {
+	for _, k := range keys {
+		if err := genCtx.Cache.Put(k, entriesToCache(result)); err != nil {
+			slog.Warn("Failed to write context cache", "path", entry.GetPath(), "error", err)
+		}
+	}
+}
+
+func entriesToCache(entries []*osdd.MaterializedResult_Entry) *cache.Entry {
+	e := &cache.Entry{Files: make([]cache.File, 0, len(entries))}
+	for _, m := range entries {
+		if !m.HasFile() {
+			continue
+		}
+		e.Files = append(e.Files, cache.File{Path: m.GetFile().GetPath(), Content: []byte(m.GetFile().GetContent())})
+	}
+	return e
+}
+
+func entriesFromCache(e *cache.Entry) []*osdd.MaterializedResult_Entry {
+	entries := make([]*osdd.MaterializedResult_Entry, 0, len(e.Files))
+	for _, f := range e.Files {
+		entries = append(entries, osdd.MaterializedResult_Entry_builder{
+			File: osdd.FullFileContent_builder{
+				Path:    f.Path,
+				Content: string(f.Content),
+			}.Build(),
+		}.Build())
+	}
+	return entries
+}
+
+// isOffline reports whether genCtx requests offline materialization.
+func isOffline(genCtx *core.GenerationContext) bool {
+	return genCtx != nil && genCtx.Offline
+}
+
+// offlineUnsupported is returned for source kinds that need the network and cannot be cached.
+func offlineUnsupported(kind core.SourceKind, path string) error {
+	return fmt.Errorf("%w: %s source at %s cannot be served offline", ErrOffline, kind, path)
+}
@@ -0,0 +1,351 @@
+package generators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
+	"github.com/opensdd/osdd-core/core"
+	"github.com/opensdd/osdd-core/core/cache"
+	"github.com/opensdd/osdd-core/core/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCache(t *testing.T) *cache.Store {
+	t.Helper()
+	s, err := cache.New(t.TempDir())
+	require.NoError(t, err)
+	return s
+}
+
+func countingServer(t *testing.T, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		_, _ = fmt.Fprintf(w, "%s-%d", body, n)
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestContext_Cache_HitSkipsFetch(t *testing.T) {
+	t.Parallel()
+	server, hits := countingServer(t, "doc")
+	genCtx := &core.GenerationContext{Cache: newTestCache(t)}
+	ctx := recipes.Context_builder{Entries: []*recipes.ContextEntry{
+		contextEntry("doc.md", githubFrom(server.URL+"/doc.md")),
+	}}.Build()
+
+	c := &Context{}
+	first, err := c.Materialize(context.Background(), ctx, genCtx)
+	require.NoError(t, err)
+	second, err := c.Materialize(context.Background(), ctx, genCtx)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), hits.Load())
+	assert.Equal(t, "doc-1", first.GetEntries()[0].GetFile().GetContent())
+	assert.Equal(t, "doc-1", second.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestContext_Cache_KeyIncludesSourceDefinition(t *testing.T) {
+	t.Parallel()
+	server, hits := countingServer(t, "doc")
+	genCtx := &core.GenerationContext{Cache: newTestCache(t)}
+	c := &Context{}
+
+	for _, p := range []string{"/a.md", "/b.md", "/a.md"} {
+		ctx := recipes.Context_builder{Entries: []*recipes.ContextEntry{
+			contextEntry("doc.md", githubFrom(server.URL+p)),
+		}}.Build()
+		_, err := c.Materialize(context.Background(), ctx, genCtx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestContext_Cache_KeyIncludesResolvedUserInput(t *testing.T) {
+	t.Parallel()
+	genCtx := &core.GenerationContext{UserInput: map[string]string{"ticket": "A-1"}}
+	entry := contextEntry("ctx.md", combinedFrom(combinedUserInputItem(userInputParam("ticket", false))))
+
+	k1, err := cacheKey(entry, genCtx, nil)
+	require.NoError(t, err)
+	genCtx.UserInput["ticket"] = "A-2"
+	k2, err := cacheKey(entry, genCtx, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, k1, k2)
+}
+
+func TestContext_Cache_KeyIncludesLocalFileContents(t *testing.T) {
+	t.Parallel()
+	p := filepath.Join(t.TempDir(), "notes.md")
+	require.NoError(t, os.WriteFile(p, []byte("v1"), 0o644))
+	genCtx := &core.GenerationContext{Cache: newTestCache(t)}
+	ctx := recipes.Context_builder{Entries: []*recipes.ContextEntry{
+		contextEntry("ctx.md", combinedFrom(
+			combinedTextItem("notes: "),
+			recipes.CombinedContextSource_Item_builder{LocalFile: &p}.Build(),
+		)),
+	}}.Build()
+
+	c := &Context{}
+	first, err := c.Materialize(context.Background(), ctx, genCtx)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(p, []byte("v2"), 0o644))
+	second, err := c.Materialize(context.Background(), ctx, genCtx)
+	require.NoError(t, err)
+
+	assert.Equal(t, "notes: v1", first.GetEntries()[0].GetFile().GetContent())
+	assert.Equal(t, "notes: v2", second.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestContext_Cache_RevalidatesRefsOnExpiry(t *testing.T) {
+	// Mutates the GitLab API base URL; not parallel.
+	var sha atomic.Value
+	sha.Store("1111111")
+	var commitHits, fileHits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/repository/commits/") {
+			commitHits.Add(1)
+			_, _ = fmt.Fprintf(w, `{"id":%q}`, sha.Load())
+			return
+		}
+		_, _ = fmt.Fprintf(w, "doc-%d", fileHits.Add(1))
+	}))
+	defer server.Close()
+	orig := utils.ExportGitLabAPIBaseURL()
+	utils.SetGitLabAPIBaseURL(server.URL)
+	defer utils.SetGitLabAPIBaseURL(orig)
+
+	store := newTestCache(t)
+	ctx := recipes.Context_builder{Entries: []*recipes.ContextEntry{
+		contextEntry("doc.md", githubFrom("https://gitlab.example.com/group/project/-/blob/main/doc.md")),
+	}}.Build()
+	c := &Context{}
+	materialize := func(genCtx *core.GenerationContext) string {
+		genCtx.Cache = store
+		result, err := c.Materialize(context.Background(), ctx, genCtx)
+		require.NoError(t, err)
+		return result.GetEntries()[0].GetFile().GetContent()
+	}
+	expired := map[core.SourceKind]time.Duration{core.SourceGithub: time.Nanosecond}
+
+	assert.Equal(t, "doc-1", materialize(&core.GenerationContext{}))
+	commitHits.Store(0)
+
+	// A fresh entry is served without resolving the ref, even after it moved.
+	sha.Store("2222222")
+	assert.Equal(t, "doc-1", materialize(&core.GenerationContext{}))
+	assert.Equal(t, int32(0), commitHits.Load())
+
+	// Revalidation notices the moved branch.
+	assert.Equal(t, "doc-2", materialize(&core.GenerationContext{CacheRevalidate: true}))
+	assert.Equal(t, int32(1), commitHits.Load())
+
+	// An expired entry whose ref did not move is reused.
+	time.Sleep(time.Millisecond)
+	assert.Equal(t, "doc-2", materialize(&core.GenerationContext{CacheTTLs: expired}))
+	assert.Equal(t, int32(2), commitHits.Load())
+	assert.Equal(t, int32(2), fileHits.Load())
+
+	// Offline mode cannot resolve refs and serves the latest fetch.
+	assert.Equal(t, "doc-2", materialize(&core.GenerationContext{Offline: true}))
+}
+
+func TestContext_Cache_PinnedCommitNotResolved(t *testing.T) {
+	// Mutates the GitLab API base URL; not parallel.
+	var commitHits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/repository/commits/") {
+			commitHits.Add(1)
+		}
+		_, _ = w.Write([]byte("doc"))
+	}))
+	defer server.Close()
+	orig := utils.ExportGitLabAPIBaseURL()
+	utils.SetGitLabAPIBaseURL(server.URL)
+	defer utils.SetGitLabAPIBaseURL(orig)
+
+	ctx := recipes.Context_builder{Entries: []*recipes.ContextEntry{
+		contextEntry("doc.md", githubFrom("https://gitlab.example.com/group/project/-/blob/0123456789abcdef0123456789abcdef01234567/doc.md")),
+	}}.Build()
+	genCtx := &core.GenerationContext{Cache: newTestCache(t), CacheRevalidate: true}
+	c := &Context{}
+	for range 2 {
+		_, err := c.Materialize(context.Background(), ctx, genCtx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(0), commitHits.Load())
+}
+
+func TestContext_Cache_TTLExpiry(t *testing.T) {
+	t.Parallel()
+	server, hits := countingServer(t, "doc")
+	genCtx := &core.GenerationContext{
+		Cache:     newTestCache(t),
+		CacheTTLs: map[core.SourceKind]time.Duration{core.SourceGithub: time.Nanosecond},
+	}
+	ctx := recipes.Context_builder{Entries: []*recipes.ContextEntry{
+		contextEntry("doc.md", githubFrom(server.URL+"/doc.md")),
+	}}.Build()
+
+	c := &Context{}
+	_, err := c.Materialize(context.Background(), ctx, genCtx)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	result, err := c.Materialize(context.Background(), ctx, genCtx)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), hits.Load())
+	assert.Equal(t, "doc-2", result.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestContext_Offline_ServesCachedIgnoringTTL(t *testing.T) {
+	t.Parallel()
+	server, hits := countingServer(t, "doc")
+	store := newTestCache(t)
+	ctx := recipes.Context_builder{Entries: []*recipes.ContextEntry{
+		contextEntry("doc.md", githubFrom(server.URL+"/doc.md")),
+	}}.Build()
+
+	c := &Context{}
+	_, err := c.Materialize(context.Background(), ctx, &core.GenerationContext{Cache: store})
+	require.NoError(t, err)
+
+	offline := &core.GenerationContext{
+		Cache:     store,
+		Offline:   true,
+		CacheTTLs: map[core.SourceKind]time.Duration{core.SourceGithub: time.Nanosecond},
+	}
+	time.Sleep(time.Millisecond)
+	result, err := c.Materialize(context.Background(), ctx, offline)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), hits.Load())
+	assert.Equal(t, "doc-1", result.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestContext_Offline_MissFails(t *testing.T) {
+	t.Parallel()
+	server, hits := countingServer(t, "doc")
+	genCtx := &core.GenerationContext{Cache: newTestCache(t), Offline: true}
+	ctx := recipes.Context_builder{Entries: []*recipes.ContextEntry{
+		contextEntry("doc.md", githubFrom(server.URL+"/doc.md")),
+	}}.Build()
+
+	_, err := (&Context{}).Materialize(context.Background(), ctx, genCtx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrOffline))
+	assert.True(t, errors.Is(err, cache.ErrMiss))
+	assert.Contains(t, err.Error(), "no cached content for github source at doc.md")
+	assert.Equal(t, int32(0), hits.Load())
+}
+
+func TestContext_Offline_WithoutCache(t *testing.T) {
+	t.Parallel()
+	genCtx := &core.GenerationContext{Offline: true}
+	ctx := recipes.Context_builder{Entries: []*recipes.ContextEntry{
+		contextEntry("doc.md", githubFrom("https://github.com/org/repo/blob/main/doc.md")),
+	}}.Build()
+
+	_, err := (&Context{}).Materialize(context.Background(), ctx, genCtx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrOffline))
+	assert.Contains(t, err.Error(), "no cache configured")
+}
+
+func TestContext_Cache_CommandsOptIn(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	// Each run prints how often the command has run.
+	script := func(name string) string {
+		counter := filepath.Join(dir, name)
+		return "echo x >> " + counter + "; wc -l < " + counter
+	}
+	ctx := recipes.Context_builder{Entries: []*recipes.ContextEntry{
+		contextEntry("out.txt", cmdFrom("sh", "-c", script("cmd"))),
+		contextEntry("combined.txt", combinedFrom(combinedCmdItem("sh", "-c", script("combined")))),
+	}}.Build()
+	c := &Context{}
+	materialize := func(genCtx *core.GenerationContext) []string {
+		result, err := c.Materialize(context.Background(), ctx, genCtx)
+		require.NoError(t, err)
+		var out []string
+		for _, e := range result.GetEntries() {
+			out = append(out, strings.TrimSpace(e.GetFile().GetContent()))
+		}
+		return out
+	}
+
+	store := newTestCache(t)
+	assert.Equal(t, []string{"1", "1"}, materialize(&core.GenerationContext{Cache: store}))
+	assert.Equal(t, []string{"2", "2"}, materialize(&core.GenerationContext{Cache: store}), "commands are not cached by default")
+
+	optIn := &core.GenerationContext{Cache: store, EntryOptions: map[string]*core.EntryOptions{
+		"out.txt":      {CacheTTL: time.Hour},
+		"combined.txt": {CacheTTL: time.Hour},
+	}}
+	assert.Equal(t, []string{"3", "3"}, materialize(optIn))
+	assert.Equal(t, []string{"3", "3"}, materialize(optIn))
+}
+
+func TestContext_Offline_LocalSourcesStillWork(t *testing.T) {
+	t.Parallel()
+	genCtx := &core.GenerationContext{Offline: true}
+	ctx := recipes.Context_builder{Entries: []*recipes.ContextEntry{
+		contextEntry("a.txt", textFrom("local")),
+	}}.Build()
+
+	result, err := (&Context{}).Materialize(context.Background(), ctx, genCtx)
+	require.NoError(t, err)
+	assert.Equal(t, "local", result.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestContext_Offline_GitRepoUnsupported(t *testing.T) {
+	t.Parallel()
+	genCtx := &core.GenerationContext{Cache: newTestCache(t), Offline: true}
+	entry := contextEntry("repo", gitRepoFrom("org/repo", "github", nil))
+
+	_, err := (&Context{}).materializeEntry(context.Background(), entry, genCtx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrOffline))
+	assert.Contains(t, err.Error(), "cannot be served offline")
+}
+
+func TestContext_Cache_UrlFetchBinaryRoundTrip(t *testing.T) {
+	t.Parallel()
+	payload := []byte{0x00, 0x01, 0xFF, 0xFE}
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write(payload)
+	}))
+	defer server.Close()
+
+	store := newTestCache(t)
+	workspace := t.TempDir()
+	entry := contextEntry("bin/data.bin", urlFetchFrom(server.URL, false))
+	c := &Context{}
+
+	_, err := c.materializeEntry(context.Background(), entry, &core.GenerationContext{WorkspacePath: workspace, Cache: store})
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(workspace, "bin", "data.bin")))
+
+	_, err = c.materializeEntry(context.Background(), entry, &core.GenerationContext{WorkspacePath: workspace, Cache: store, Offline: true})
+	require.NoError(t, err)
+
+	got, err := os.ReadFile(filepath.Join(workspace, "bin", "data.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, payload, got)
+	assert.Equal(t, int32(1), hits.Load())
+}
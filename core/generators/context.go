@@ -3,6 +3,7 @@ package generators
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -16,10 +17,15 @@ import (
 	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
 	"github.com/opensdd/osdd-core/core"
 	"github.com/opensdd/osdd-core/core/utils"
+	"google.golang.org/protobuf/proto"
 )
 
 type Context struct{}
 
+// defaultMaxConcurrency bounds parallel entry materialization unless
+// GenerationContext.Concurrency overrides it.
+const defaultMaxConcurrency = 5
+
 func (c *Context) Materialize(ctx context.Context, contextMsg *recipes.Context, genCtx *core.GenerationContext) (*osdd.MaterializedResult, error) {
 	if contextMsg == nil {
 		return nil, fmt.Errorf("context cannot be nil")
@@ -40,16 +46,28 @@ func (c *Context) Materialize(ctx context.Context, contextMsg *recipes.Context,
 		filtered = append(filtered, entry)
 	}
 
+	concurrency := defaultMaxConcurrency
+	if genCtx != nil && genCtx.Concurrency > 0 {
+		concurrency = genCtx.Concurrency
+	}
+	failFast := genCtx == nil || !genCtx.CollectErrors
+
+	// In fail-fast mode the first failure cancels its siblings.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var failOnce sync.Once
+	firstFailure := -1
+
 	// Materialize entries in parallel with bounded concurrency.
-	const maxConcurrency = 5
 	type indexedResult struct {
 		index   int
 		entries []*osdd.MaterializedResult_Entry
+		report  core.EntryReport
 		err     error
 	}
 
 	results := make([]indexedResult, len(filtered))
-	sem := make(chan struct{}, maxConcurrency)
+	sem := make(chan struct{}, concurrency)
 	var wg sync.WaitGroup
 
 	for i, entry := range filtered {
@@ -58,27 +76,155 @@ func (c *Context) Materialize(ctx context.Context, contextMsg *recipes.Context,
 		go func(i int, entry *recipes.ContextEntry) {
 			defer wg.Done()
 			defer func() { <-sem }()
-			materialized, err := c.materializeEntry(ctx, entry, genCtx)
-			results[i] = indexedResult{index: i, entries: materialized, err: err}
+			if err := ctx.Err(); err != nil {
+				report := core.EntryReport{Path: entry.GetPath(), Kind: sourceKind(entry.GetFrom()), Outcome: core.OutcomeCanceled, Err: err}
+				genCtx.Emit(core.Event{Type: core.EventEntryFinish, Path: report.Path, Kind: report.Kind, Outcome: report.Outcome, Err: err})
+				results[i] = indexedResult{index: i, err: err, report: report}
+				return
+			}
+			materialized, report, err := c.materializeEntryWithReport(ctx, entry, genCtx)
+			if err != nil && failFast {
+				failOnce.Do(func() {
+					firstFailure = i
+					cancel()
+				})
+				if firstFailure != i {
+					report.Outcome = core.OutcomeCanceled
+				}
+			}
+			results[i] = indexedResult{index: i, entries: materialized, report: report, err: err}
 		}(i, entry)
 	}
 	wg.Wait()
 
-	// Collect results in order; fail on first error.
+	report := &core.MaterializationReport{}
+	for _, r := range results {
+		report.Entries = append(report.Entries, r.report)
+	}
+	if genCtx != nil {
+		genCtx.MaterializationReport = report
+	}
+
+	if firstFailure >= 0 {
+		return nil, fmt.Errorf("failed to materialize entry for path %s: %w", filtered[firstFailure].GetPath(), results[firstFailure].err)
+	}
+
+	// Collect results in order, joining all errors.
 	var resultEntries []*osdd.MaterializedResult_Entry
+	var errs []error
 	for _, r := range results {
 		if r.err != nil {
-			return nil, fmt.Errorf("failed to materialize entry for path %s: %w", filtered[r.index].GetPath(), r.err)
+			errs = append(errs, fmt.Errorf("failed to materialize entry for path %s: %w", filtered[r.index].GetPath(), r.err))
+			continue
 		}
 		resultEntries = append(resultEntries, r.entries...)
 	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if genCtx != nil && genCtx.TokenBudget > 0 {
+		var files []budgetFile
+		for _, r := range results {
+			source := filtered[r.index].GetPath()
+			priority := genCtx.EntryOptionsFor(source).Priority
+			for _, e := range r.entries {
+				files = append(files, budgetFile{
+					entry:    e,
+					source:   source,
+					priority: priority,
+					tokens:   fileTokens(e),
+				})
+			}
+		}
+		resultEntries, report.Budget = enforceTokenBudget(files, genCtx.TokenBudget)
+	}
 
 	return osdd.MaterializedResult_builder{
 		Entries: resultEntries,
 	}.Build(), nil
 }
 
+// materializeEntry materializes a single entry; failures of optional entries
+// are logged and yield no entries.
 func (c *Context) materializeEntry(ctx context.Context, entry *recipes.ContextEntry, genCtx *core.GenerationContext) ([]*osdd.MaterializedResult_Entry, error) {
+	entries, _, err := c.materializeEntryWithReport(ctx, entry, genCtx)
+	return entries, err
+}
+
+// materializeEntryWithReport is materializeEntry that also reports the entry's
+// outcome, size and duration.
+func (c *Context) materializeEntryWithReport(ctx context.Context, entry *recipes.ContextEntry, genCtx *core.GenerationContext) ([]*osdd.MaterializedResult_Entry, core.EntryReport, error) {
+	report := core.EntryReport{Path: entry.GetPath(), Kind: sourceKind(entry.GetFrom())}
+	entryCtx := ctx
+	timeout := sourceTimeout(report.Kind, genCtx)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		entryCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if genCtx != nil && genCtx.Observer != nil {
+		entryCtx = utils.WithProgress(entryCtx, func(bytes, total int64) {
+			genCtx.Emit(core.Event{Type: core.EventEntryProgress, Path: report.Path, Kind: report.Kind, Bytes: bytes, TotalBytes: total})
+		})
+	}
+	genCtx.Emit(core.Event{Type: core.EventEntryStart, Path: report.Path, Kind: report.Kind})
+	start := time.Now()
+	entries, err := c.materializeEntrySource(entryCtx, entry, genCtx, &report)
+	report.Duration = time.Since(start)
+	if err != nil && timeout > 0 && ctx.Err() == nil && errors.Is(entryCtx.Err(), context.DeadlineExceeded) {
+		err = &core.TimeoutError{Path: report.Path, Kind: report.Kind, Timeout: timeout, Err: err}
+	}
+	defer func() {
+		genCtx.Emit(core.Event{
+			Type: core.EventEntryFinish, Path: report.Path, Kind: report.Kind,
+			Bytes: report.Bytes, Duration: report.Duration, Outcome: report.Outcome, Err: report.Err,
+		})
+	}()
+
+	if err != nil {
+		report.Err = err
+		var skipped *skippedError
+		if errors.As(err, &skipped) || genCtx.EntryOptionsFor(entry.GetPath()).Optional {
+			report.Outcome = core.OutcomeSkipped
+			slog.Warn("Context entry failed, skipping optional entry", "path", entry.GetPath(), "kind", report.Kind, "error", err)
+			return nil, report, nil
+		}
+		report.Outcome = core.OutcomeFailed
+		return nil, report, err
+	}
+
+	report.Outcome = core.OutcomeSucceeded
+	for _, e := range entries {
+		if e.HasFile() {
+			report.Bytes += int64(len(e.GetFile().GetContent()))
+			report.Tokens += fileTokens(e)
+		}
+	}
+	return entries, report, nil
+}
+
+// sourceTimeout returns the configured timeout for kind, or 0 when unlimited.
+func sourceTimeout(kind core.SourceKind, genCtx *core.GenerationContext) time.Duration {
+	if genCtx == nil {
+		return 0
+	}
+	return genCtx.SourceTimeouts[kind]
+}
+
+// skippedError marks a failure that a source has already classified as
+// optional, such as a failed UrlFetch with its optional flag set.
+type skippedError struct {
+	err error
+}
+
+func (e *skippedError) Error() string { return e.err.Error() }
+
+func (e *skippedError) Unwrap() error { return e.err }
+
+// materializeEntrySource dispatches entry to its source. Sizes of content written
+// directly to the workspace are recorded in report.
+func (c *Context) materializeEntrySource(ctx context.Context, entry *recipes.ContextEntry, genCtx *core.GenerationContext, report *core.EntryReport) ([]*osdd.MaterializedResult_Entry, error) {
 	path := entry.GetPath()
 	if path == "" {
 		return nil, fmt.Errorf("entry path cannot be empty")
@@ -88,15 +234,27 @@ func (c *Context) materializeEntry(ctx context.Context, entry *recipes.ContextEn
 		return nil, fmt.Errorf("entry must have a 'from' source")
 	}
 
+	entry, err := renderEntryTemplates(entry, genCtx)
+	if err != nil {
+		return nil, err
+	}
 	from := entry.GetFrom()
 
+	transforms := genCtx.EntryOptionsFor(path).Transforms
+	if len(transforms) > 0 && !supportsTransforms(from) {
+		return nil, fmt.Errorf("transforms are not supported for %s sources", sourceKind(from))
+	}
+
 	// URL fetch entries download bytes directly to disk.
 	if from.WhichType() == recipes.ContextFrom_UrlFetch_case {
-		return c.materializeUrlFetch(ctx, entry, genCtx)
+		return c.materializeUrlFetch(ctx, entry, genCtx, report)
 	}
 
 	// GitRepo entries clone a full repository to disk and return a Directory entry.
 	if from.WhichType() == recipes.ContextFrom_GitRepo_case {
+		if isOffline(genCtx) {
+			return nil, offlineUnsupported(core.SourceGitRepo, path)
+		}
 		e, err := c.materializeGitRepo(ctx, entry, genCtx)
 		if err != nil {
 			return nil, err
@@ -104,30 +262,114 @@ func (c *Context) materializeEntry(ctx context.Context, entry *recipes.ContextEn
 		return []*osdd.MaterializedResult_Entry{e}, nil
 	}
 
-	// Jira/Linear entries produce multiple files: a summary index + one file per issue.
+	// Transforms run after the cache so cached content stays raw and changing
+	// a transform takes effect without refetching.
+	entries, err := c.withCache(ctx, entry, genCtx, func() ([]*osdd.MaterializedResult_Entry, error) {
+		return c.materializeSource(ctx, entry, genCtx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transformEntries(entries, transforms)
+}
+
+// renderEntryTemplates returns a copy of entry whose text sources and command
+// arguments (including those of combined items) are rendered with
+// genCtx.RenderTemplate. Rendering before the cache lookup makes the cache key
+// reflect the rendered values. entry is returned as is when rendering is disabled.
+func renderEntryTemplates(entry *recipes.ContextEntry, genCtx *core.GenerationContext) (*recipes.ContextEntry, error) {
+	if genCtx == nil || !genCtx.RenderTemplates {
+		return entry, nil
+	}
+	entry = proto.CloneOf(entry)
+	from := entry.GetFrom()
+	name := entry.GetPath()
+	switch from.WhichType() {
+	case recipes.ContextFrom_Text_case:
+		text, err := genCtx.RenderTemplate(name+".text", from.GetText())
+		if err != nil {
+			return nil, err
+		}
+		from.SetText(text)
+	case recipes.ContextFrom_Cmd_case:
+		cmd, err := genCtx.RenderExec(name+".cmd", from.GetCmd())
+		if err != nil {
+			return nil, err
+		}
+		from.SetCmd(cmd)
+	case recipes.ContextFrom_Combined_case:
+		for i, item := range from.GetCombined().GetItems() {
+			itemName := fmt.Sprintf("%s.combined[%d]", name, i)
+			switch item.WhichType() {
+			case recipes.CombinedContextSource_Item_Text_case:
+				text, err := genCtx.RenderTemplate(itemName+".text", item.GetText())
+				if err != nil {
+					return nil, err
+				}
+				item.SetText(text)
+			case recipes.CombinedContextSource_Item_Cmd_case:
+				cmd, err := genCtx.RenderExec(itemName+".cmd", item.GetCmd())
+				if err != nil {
+					return nil, err
+				}
+				item.SetCmd(cmd)
+			}
+		}
+	}
+	return entry, nil
+}
+
+// materializeSource fetches the content of entry for all sources that produce
+// in-memory file entries (everything except UrlFetch and GitRepo).
+func (c *Context) materializeSource(ctx context.Context, entry *recipes.ContextEntry, genCtx *core.GenerationContext) ([]*osdd.MaterializedResult_Entry, error) {
+	path := entry.GetPath()
+	from := entry.GetFrom()
+
+	// Jira/Linear entries (and GitHistory entries fetching GitHub issues)
+	// produce multiple files: a summary index + one file per issue.
 	if from.WhichType() == recipes.ContextFrom_JiraIssues_case {
 		src := from.GetJiraIssues()
 		token := resolveAuthToken(src.GetAuthTokenEnvVar(), genCtx)
+		var opts utils.JiraOptions
+		if o := genCtx.EntryOptionsFor(path).Jira; o != nil {
+			opts = *o
+		}
 		return c.materializeIssues(path, func() (*utils.IssuesResult, error) {
-			return utils.FetchJiraIssues(ctx, src, token)
+			return utils.FetchJiraIssuesWithOptions(ctx, src, token, opts)
 		})
 	}
 	if from.WhichType() == recipes.ContextFrom_LinearIssues_case {
 		src := from.GetLinearIssues()
 		token := resolveAuthToken(src.GetAuthTokenEnvVar(), genCtx)
+		var opts utils.LinearOptions
+		if o := genCtx.EntryOptionsFor(path).Linear; o != nil {
+			opts = *o
+		}
 		return c.materializeIssues(path, func() (*utils.IssuesResult, error) {
-			return utils.FetchLinearIssues(ctx, src, token)
+			return utils.FetchLinearIssuesWithOptions(ctx, src, token, opts)
 		})
 	}
 
 	if from.WhichType() == recipes.ContextFrom_GitHistory_case {
 		src := from.GetGitHistory()
 		token := resolveAuthToken(src.GetRepo().GetAuthTokenEnvVar(), genCtx)
+		if o := genCtx.EntryOptionsFor(path).GitHubIssues; o != nil {
+			opts := *o
+			return c.materializeIssues(path, func() (*utils.IssuesResult, error) {
+				return utils.FetchGitHubIssues(ctx, src.GetRepo(), src.GetDateFilter(), token, opts)
+			})
+		}
 		return c.materializeGitHistory(path, func() (*utils.GitHistoryResult, error) {
 			return utils.FetchGitHistory(ctx, src, token)
 		})
 	}
 
+	if from.WhichType() == recipes.ContextFrom_LocalFile_case {
+		if root, opts, ok := localDirectorySource(from.GetLocalFile(), genCtx.EntryOptionsFor(path)); ok {
+			return c.materializeLocalDirectory(path, root, opts)
+		}
+	}
+
 	content, err := c.fetchContent(ctx, from, genCtx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch content: %w", err)
@@ -145,7 +387,8 @@ func (c *Context) materializeEntry(ctx context.Context, entry *recipes.ContextEn
 
 // materializeIssues converts an IssuesResult into a summary file and per-issue files.
 // Path is treated as a folder: the summary is written to <path>/all-issues.json;
-// individual issues go to <path>/issues/<id>.json.
+// individual issues go to <path>/issues/<id>.json, or <id>.md for Markdown issues,
+// and their attachments to <path>/issues/<id>/attachments/.
 func (c *Context) materializeIssues(path string, fetch func() (*utils.IssuesResult, error)) ([]*osdd.MaterializedResult_Entry, error) {
 	result, err := fetch()
 	if err != nil {
@@ -157,7 +400,7 @@ func (c *Context) materializeIssues(path string, fetch func() (*utils.IssuesResu
 		return nil, fmt.Errorf("failed to marshal issues summary: %w", err)
 	}
 
-	entries := make([]*osdd.MaterializedResult_Entry, 0, 1+len(result.Issues))
+	entries := make([]*osdd.MaterializedResult_Entry, 0, 1+len(result.Issues)+len(result.Files))
 
 	// Summary file at <path>/all-issues.json
 	summaryPath := path + "/all-issues.json"
@@ -168,9 +411,13 @@ func (c *Context) materializeIssues(path string, fetch func() (*utils.IssuesResu
 		}.Build(),
 	}.Build())
 
-	// Per-issue files at <path>/issues/<id>.json
+	// Per-issue files at <path>/issues/<id>.<ext>
+	folder := result.Folder
+	if folder == "" {
+		folder = "issues"
+	}
 	for _, s := range result.Summary {
-		issuePath := path + "/issues/" + s.ID + ".json"
+		issuePath := path + "/" + folder + "/" + s.ID + result.Format.Extension()
 		entries = append(entries, osdd.MaterializedResult_Entry_builder{
 			File: osdd.FullFileContent_builder{
 				Path:    issuePath,
@@ -179,6 +426,16 @@ func (c *Context) materializeIssues(path string, fetch func() (*utils.IssuesResu
 		}.Build())
 	}
 
+	// Additional files such as attachments at <path>/<file.Path>
+	for _, f := range result.Files {
+		entries = append(entries, osdd.MaterializedResult_Entry_builder{
+			File: osdd.FullFileContent_builder{
+				Path:    path + "/" + f.Path,
+				Content: f.Content,
+			}.Build(),
+		}.Build())
+	}
+
 	return entries, nil
 }
 
@@ -204,6 +461,50 @@ func (c *Context) materializeGitHistory(path string, fetch func() (*utils.GitHis
 	return entries, nil
 }
 
+// localDirectorySource decides whether a LocalFile source is read as a
+// directory: when directory options are configured, the path is a glob, or
+// the path is an existing directory. A glob is split into the directory to
+// walk and an include pattern. Paths that exist are never globs, so files
+// such as "notes[1].md" are read literally.
+func localDirectorySource(localPath string, entryOpts core.EntryOptions) (string, utils.DirectoryOptions, bool) {
+	var opts utils.DirectoryOptions
+	if entryOpts.Directory != nil {
+		opts = *entryOpts.Directory
+	}
+	p := strings.TrimSpace(localPath)
+	info, statErr := os.Stat(p)
+	if root, pattern := utils.SplitGlobRoot(p); pattern != "" && statErr != nil {
+		opts.Include = append([]string{pattern}, opts.Include...)
+		return root, opts, true
+	}
+	if entryOpts.Directory != nil {
+		return p, opts, true
+	}
+	if statErr == nil && info.IsDir() {
+		return p, opts, true
+	}
+	return "", opts, false
+}
+
+// materializeLocalDirectory produces one file entry per selected file, written
+// to <path>/<relative file path>.
+func (c *Context) materializeLocalDirectory(path, root string, opts utils.DirectoryOptions) ([]*osdd.MaterializedResult_Entry, error) {
+	files, err := utils.ReadLocalDirectory(root, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local directory: %w", err)
+	}
+	entries := make([]*osdd.MaterializedResult_Entry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, osdd.MaterializedResult_Entry_builder{
+			File: osdd.FullFileContent_builder{
+				Path:    path + "/" + f.Path,
+				Content: string(f.Content),
+			}.Build(),
+		}.Build())
+	}
+	return entries, nil
+}
+
 func (c *Context) materializeGitRepo(ctx context.Context, entry *recipes.ContextEntry, genCtx *core.GenerationContext) (*osdd.MaterializedResult_Entry, error) {
 	path := entry.GetPath()
 	slog.Debug("Materializing git repository context", "path", path)
@@ -215,7 +516,11 @@ func (c *Context) materializeGitRepo(ctx context.Context, entry *recipes.Context
 
 	repo := entry.GetFrom().GetGitRepo()
 	token := resolveAuthToken(repo.GetAuthTokenEnvVar(), genCtx)
-	if err := utils.CloneGitRepo(ctx, repo, destPath, token); err != nil {
+	var opts utils.GitCheckoutOptions
+	if o := genCtx.EntryOptionsFor(path).Git; o != nil {
+		opts = *o
+	}
+	if err := utils.CloneGitRepoWithOptions(ctx, repo, destPath, token, opts); err != nil {
 		return nil, fmt.Errorf("failed to clone git repository: %w", err)
 	}
 
@@ -235,7 +540,7 @@ func urlFetchBackoff(attempt int) time.Duration {
 	return time.Duration(1<<uint(attempt)) * urlFetchBackoffBase // 1x, 2x, 4x base
 }
 
-func (c *Context) materializeUrlFetch(ctx context.Context, entry *recipes.ContextEntry, genCtx *core.GenerationContext) ([]*osdd.MaterializedResult_Entry, error) {
+func (c *Context) materializeUrlFetch(ctx context.Context, entry *recipes.ContextEntry, genCtx *core.GenerationContext, report *core.EntryReport) ([]*osdd.MaterializedResult_Entry, error) {
 	src := entry.GetFrom().GetUrlFetch()
 	rawURL := src.GetUrl()
 	optional := src.GetOptional()
@@ -248,44 +553,82 @@ func (c *Context) materializeUrlFetch(ctx context.Context, entry *recipes.Contex
 		return nil, err
 	}
 
-	// Validate destination path against workspace root.
-	if genCtx == nil || genCtx.WorkspacePath == "" {
+	// Validate destination path against workspace root. Deferred writes are
+	// validated when persisted.
+	deferred := genCtx != nil && genCtx.DeferWrites
+	if !deferred && (genCtx == nil || genCtx.WorkspacePath == "") {
 		return nil, fmt.Errorf("workspace path is required for url fetch")
 	}
-	destPath := filepath.Join(genCtx.WorkspacePath, filepath.Clean(path))
-	if !core.IsPathWithinRoot(genCtx.WorkspacePath, destPath) {
-		return nil, fmt.Errorf("destination path escapes workspace: %s", path)
+	var destPath string
+	if !deferred {
+		destPath = filepath.Join(genCtx.WorkspacePath, filepath.Clean(path))
+		if !core.IsPathWithinRoot(genCtx.WorkspacePath, destPath) {
+			return nil, fmt.Errorf("destination path escapes workspace: %s", path)
+		}
 	}
 
-	// Retry loop.
-	var data []byte
-	var lastErr error
-	for attempt := range urlFetchMaxAttempts {
-		data, lastErr = utils.FetchURL(ctx, rawURL)
-		if lastErr == nil {
-			break
+	// HTML conversion happens before caching; the cached entry records the
+	// converted .md path, and the conversion flag is part of the cache key.
+	entryOpts := genCtx.EntryOptionsFor(path)
+	fetched, err := c.withCache(ctx, entry, genCtx, func() ([]*osdd.MaterializedResult_Entry, error) {
+		data, contentType, err := fetchURLWithRetry(ctx, rawURL, path)
+		if err != nil {
+			return nil, err
 		}
-		if ctx.Err() != nil {
-			lastErr = fmt.Errorf("context cancelled while fetching url %s for path %s: %w", rawURL, path, ctx.Err())
-			break
+		outPath := path
+		if entryOpts.HTMLToMarkdown && utils.IsHTMLContentType(contentType, data) {
+			md, err := utils.HTMLToMarkdown(string(data), rawURL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %s to markdown: %w", rawURL, err)
+			}
+			data, outPath = []byte(md), utils.MarkdownPath(path)
 		}
-		if attempt < urlFetchMaxAttempts-1 {
-			slog.Debug("Retrying URL fetch", "url", rawURL, "attempt", attempt+1, "error", lastErr)
-			select {
-			case <-ctx.Done():
-				return nil, fmt.Errorf("context cancelled while fetching url %s for path %s: %w", rawURL, path, ctx.Err())
-			case <-time.After(urlFetchBackoff(attempt)):
+		return []*osdd.MaterializedResult_Entry{
+			osdd.MaterializedResult_Entry_builder{
+				File: osdd.FullFileContent_builder{Path: outPath, Content: string(data)}.Build(),
+			}.Build(),
+		}, nil
+	})
+	if err != nil {
+		if optional {
+			return nil, &skippedError{err: err}
+		}
+		return nil, err
+	}
+	var data []byte
+	if len(fetched) > 0 {
+		data = []byte(fetched[0].GetFile().GetContent())
+		if outPath := fetched[0].GetFile().GetPath(); outPath != path {
+			path = outPath
+			if !deferred {
+				destPath = filepath.Join(genCtx.WorkspacePath, filepath.Clean(path))
+				if !core.IsPathWithinRoot(genCtx.WorkspacePath, destPath) {
+					return nil, fmt.Errorf("destination path escapes workspace: %s", path)
+				}
 			}
 		}
 	}
-
-	if lastErr != nil {
-		if optional {
-			slog.Warn("URL fetch failed, skipping optional entry", "url", rawURL, "path", path, "error", lastErr)
-			return nil, nil
+	if len(entryOpts.Transforms) > 0 && !core.IsBinaryContent(string(data)) {
+		content, err := utils.ApplyTransforms(string(data), entryOpts.Transforms)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform %s: %w", path, err)
 		}
-		return nil, fmt.Errorf("failed to fetch url %s for path %s: %w", rawURL, path, lastErr)
+		data = []byte(content)
+	}
+	if deferred {
+		return []*osdd.MaterializedResult_Entry{
+			osdd.MaterializedResult_Entry_builder{
+				File: osdd.FullFileContent_builder{Path: path, Content: string(data)}.Build(),
+			}.Build(),
+		}, nil
+	}
+
+	// The file bypasses persisting, so apply its secret policy here.
+	content, _, err := core.ScanSecrets(filepath.ToSlash(filepath.Clean(path)), string(data), genCtx.Persist)
+	if err != nil {
+		return nil, err
 	}
+	data = []byte(content)
 
 	// Atomic write: temp file → rename.
 	dir := filepath.Dir(destPath)
@@ -315,11 +658,41 @@ func (c *Context) materializeUrlFetch(ctx context.Context, entry *recipes.Contex
 	}
 
 	slog.Debug("URL content written", "url", rawURL, "path", path, "bytes", len(data))
+	report.Bytes = int64(len(data))
 
 	// File is already on disk; return no entries to avoid PersistMaterializedResult overwriting.
 	return nil, nil
 }
 
+// fetchURLWithRetry downloads rawURL, retrying with exponential backoff up to urlFetchMaxAttempts times.
+// It returns the body and the response Content-Type.
+func fetchURLWithRetry(ctx context.Context, rawURL, path string) ([]byte, string, error) {
+	var (
+		data        []byte
+		contentType string
+		lastErr     error
+	)
+	for attempt := range urlFetchMaxAttempts {
+		data, contentType, lastErr = utils.FetchURLWithContentType(ctx, rawURL)
+		if lastErr == nil {
+			return data, contentType, nil
+		}
+		if ctx.Err() != nil {
+			lastErr = fmt.Errorf("context cancelled while fetching url %s for path %s: %w", rawURL, path, ctx.Err())
+			break
+		}
+		if attempt < urlFetchMaxAttempts-1 {
+			slog.Debug("Retrying URL fetch", "url", rawURL, "attempt", attempt+1, "error", lastErr)
+			select {
+			case <-ctx.Done():
+				return nil, "", fmt.Errorf("context cancelled while fetching url %s for path %s: %w", rawURL, path, ctx.Err())
+			case <-time.After(urlFetchBackoff(attempt)):
+			}
+		}
+	}
+	return nil, "", fmt.Errorf("failed to fetch url %s for path %s: %w", rawURL, path, lastErr)
+}
+
 func (c *Context) fetchContent(ctx context.Context, from *recipes.ContextFrom, genCtx *core.GenerationContext) (string, error) {
 	if from == nil {
 		return "", fmt.Errorf("from source cannot be nil")
@@ -333,7 +706,7 @@ func (c *Context) fetchContent(ctx context.Context, from *recipes.ContextFrom, g
 		return utils.ExecuteCommand(ctx, from.GetCmd())
 
 	case recipes.ContextFrom_Github_case:
-		return utils.FetchGithub(ctx, from.GetGithub())
+		return utils.FetchGitFile(ctx, from.GetGithub(), genCtx.GitFileTokens())
 
 	case recipes.ContextFrom_Combined_case:
 		return c.fetchCombined(ctx, from.GetCombined(), genCtx)
@@ -399,7 +772,7 @@ func (c *Context) fetchCombinedItem(ctx context.Context, item *recipes.CombinedC
 		return utils.ExecuteCommand(ctx, item.GetCmd())
 
 	case recipes.CombinedContextSource_Item_Github_case:
-		return utils.FetchGithub(ctx, item.GetGithub())
+		return utils.FetchGitFile(ctx, item.GetGithub(), genCtx.GitFileTokens())
 
 	case recipes.CombinedContextSource_Item_PrefetchId_case:
 		data, ok := genCtx.GetPrefetched()[item.GetPrefetchId()]
@@ -0,0 +1,48 @@
+package generators
+
+import (
+	"fmt"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
+	"github.com/opensdd/osdd-core/core"
+	"github.com/opensdd/osdd-core/core/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+// supportsTransforms reports whether entry transforms apply to from. Sources
+// producing structured multi-file output (issues, git history) or whole
+// checkouts are excluded.
+func supportsTransforms(from *recipes.ContextFrom) bool {
+	switch from.WhichType() {
+	case recipes.ContextFrom_JiraIssues_case, recipes.ContextFrom_LinearIssues_case,
+		recipes.ContextFrom_GitHistory_case, recipes.ContextFrom_GitRepo_case:
+		return false
+	default:
+		return true
+	}
+}
+
+// transformEntries returns entries with transforms applied to the content of
+// every text file. Binary files are passed through unchanged. Entries are
+// copied so cached results are not modified.
+func transformEntries(entries []*osdd.MaterializedResult_Entry, transforms []utils.Transform) ([]*osdd.MaterializedResult_Entry, error) {
+	if len(transforms) == 0 {
+		return entries, nil
+	}
+	out := make([]*osdd.MaterializedResult_Entry, 0, len(entries))
+	for _, e := range entries {
+		if !e.HasFile() || core.IsBinaryContent(e.GetFile().GetContent()) {
+			out = append(out, e)
+			continue
+		}
+		content, err := utils.ApplyTransforms(e.GetFile().GetContent(), transforms)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform %s: %w", e.GetFile().GetPath(), err)
+		}
+		e = proto.CloneOf(e)
+		e.GetFile().SetContent(content)
+		out = append(out, e)
+	}
+	return out, nil
+}
@@ -7,10 +7,48 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/opensdd/osdd-api/clients/go/osdd"
 )
 
+// PersistOptions configures PersistMaterializedResultWithOptions.
+type PersistOptions struct {
+	// RecipeID identifies the recipe that produced the result. It is recorded in
+	// the manifest and scopes pruning and revert to files from the same recipe.
+	RecipeID string
+
+	// TrackManifest records every persisted file in ManifestPath, prunes files a
+	// previous run of the same recipe produced but this run no longer does, and
+	// backs up pre-existing files before they are first overwritten.
+	// Persisting fails, before anything is written, when a file is already
+	// managed for a different recipe.
+	// Only file entries are tracked: URL downloads are tracked when returned as
+	// entries (see GenerationContext.DeferWrites), git repository checkouts
+	// never are.
+	TrackManifest bool
+
+	// SecretScanner, when set, scans the content of every file entry before
+	// anything is written. See DefaultSecretScanner.
+	SecretScanner SecretScanner
+	// SecretPolicy selects what happens to secrets the scanner finds. The
+	// default is SecretPolicyWarn.
+	SecretPolicy SecretPolicy
+}
+
+// PersistReport describes what PersistMaterializedResultWithOptions did.
+type PersistReport struct {
+	// Written lists the root-relative paths of files written.
+	Written []string
+	// Pruned lists stale managed files that were removed or restored from backup.
+	Pruned []string
+	// Modified lists managed files whose on-disk content no longer matched the
+	// manifest, i.e. files a user edited after they were persisted.
+	Modified []string
+	// Secrets lists the findings of PersistOptions.SecretScanner.
+	Secrets []SecretFinding
+}
+
 // PersistMaterializedResult writes all entries from MaterializedResult into the filesystem under the given root directory.
 // - root: base directory where files will be written.
 // - result: materialized content to persist.
@@ -20,44 +58,130 @@ import (
 // - Handles Directory entries by ensuring the directory exists under root.
 // - Skips entries that contain neither a file nor a directory.
 // - Rejects paths that escape the provided root via path traversal.
-func PersistMaterializedResult(_ context.Context, root string, result *osdd.MaterializedResult) error {
+func PersistMaterializedResult(ctx context.Context, root string, result *osdd.MaterializedResult) error {
+	_, err := PersistMaterializedResultWithOptions(ctx, root, result, PersistOptions{})
+	return err
+}
+
+// PersistMaterializedResultWithOptions behaves like PersistMaterializedResult and
+// additionally maintains the materialization manifest when opts.TrackManifest is set
+// and scans file contents for secrets when opts.SecretScanner is set.
+// All entry paths are validated and all files scanned before anything is written.
+func PersistMaterializedResultWithOptions(_ context.Context, root string, result *osdd.MaterializedResult, opts PersistOptions) (_ *PersistReport, err error) {
 	log := slog.With("op", "PersistMaterializedResult")
 	if strings.TrimSpace(root) == "" {
-		return fmt.Errorf("root path cannot be empty")
+		return nil, fmt.Errorf("root path cannot be empty")
 	}
 	if result == nil {
-		return fmt.Errorf("materialized result cannot be nil")
+		return nil, fmt.Errorf("materialized result cannot be nil")
 	}
 
 	root = filepath.Clean(root)
 
-	entries := result.GetEntries()
-	if len(entries) == 0 {
-		return nil
+	resolved, err := resolveEntries(root, result.GetEntries())
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := scanSecrets(resolved, opts)
+	if err != nil {
+		return nil, err
+	}
+	report := &PersistReport{Secrets: secrets}
+	if len(resolved) == 0 && !opts.TrackManifest {
+		return report, nil
 	}
 
+	var m *manifestState
+	if opts.TrackManifest {
+		if m, err = loadManifestState(root); err != nil {
+			return nil, err
+		}
+		if err := m.checkOwnership(resolved, opts.RecipeID); err != nil {
+			return nil, err
+		}
+		// Save the manifest even when a write fails, so files written and
+		// backed up before the failure can still be pruned and reverted.
+		defer func() {
+			if saveErr := m.save(); saveErr != nil {
+				if err == nil {
+					err = saveErr
+				} else {
+					slog.Error("Failed to save manifest after persist error", "root", root, "error", saveErr)
+				}
+			}
+		}()
+	}
+
+	for _, e := range resolved {
+		// Handle Directory entries: ensure the directory exists under root.
+		if e.dir {
+			log.Debug("Ensuring directory exists", "dir", e.full)
+			if err := os.MkdirAll(e.full, 0o755); err != nil {
+				return nil, fmt.Errorf("entry %d: failed to create directory %s: %w", e.index, e.full, err)
+			}
+			continue
+		}
+
+		if m != nil {
+			if err := m.beforeWrite(e, report); err != nil {
+				return nil, fmt.Errorf("entry %d: %w", e.index, err)
+			}
+		}
+
+		// Materialize parent directories.
+		dir := filepath.Dir(e.full)
+		log.Debug("Creating directory", "dir", dir)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("entry %d: failed to create directories for %s: %w", e.index, e.full, err)
+		}
+
+		// Write file (overwrite if exists).
+		log.Debug("Writing file", "rel", e.rel, "full", e.full)
+		if err := os.WriteFile(e.full, []byte(e.content), 0o644); err != nil {
+			return nil, fmt.Errorf("entry %d: failed to write file %s: %w", e.index, e.full, err)
+		}
+		report.Written = append(report.Written, e.rel)
+		if m != nil {
+			m.recordWrite(e, opts.RecipeID)
+		}
+	}
+
+	if m != nil {
+		if err := m.prune(opts.RecipeID, report); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// resolvedEntry is a MaterializedResult entry with its path validated and resolved under root.
+type resolvedEntry struct {
+	index   int
+	rel     string // root-relative, slash-separated
+	full    string
+	dir     bool
+	content string
+}
+
+// resolveEntries validates and resolves the paths of all file and directory
+// entries under root. Entries with neither a file nor a directory are skipped.
+func resolveEntries(root string, entries []*osdd.MaterializedResult_Entry) ([]resolvedEntry, error) {
+	var resolved []resolvedEntry
 	for i, e := range entries {
 		if e == nil {
 			continue
 		}
 
-		// Handle Directory entries: ensure the directory exists under root.
 		if e.HasDirectory() {
 			dirPath := strings.TrimSpace(e.GetDirectory())
-			if dirPath != "" {
-				rel := filepath.Clean(dirPath)
-				if filepath.IsAbs(rel) {
-					rel = strings.TrimPrefix(rel, string(os.PathSeparator))
-				}
-				full := filepath.Clean(filepath.Join(root, rel))
-				if !IsPathWithinRoot(root, full) {
-					return fmt.Errorf("entry %d: directory path escapes root: %s", i, dirPath)
-				}
-				log.Debug("Ensuring directory exists", "dir", full)
-				if err := os.MkdirAll(full, 0o755); err != nil {
-					return fmt.Errorf("entry %d: failed to create directory %s: %w", i, full, err)
-				}
+			if dirPath == "" {
+				continue
+			}
+			rel, full := resolveUnderRoot(root, dirPath)
+			if !IsPathWithinRoot(root, full) {
+				return nil, fmt.Errorf("entry %d: directory path escapes root: %s", i, dirPath)
 			}
+			resolved = append(resolved, resolvedEntry{index: i, rel: rel, full: full, dir: true})
 			continue
 		}
 
@@ -70,38 +194,30 @@ func PersistMaterializedResult(_ context.Context, root string, result *osdd.Mate
 		}
 		p := strings.TrimSpace(f.GetPath())
 		if p == "" {
-			return fmt.Errorf("entry %d: file path cannot be empty", i)
-		}
-
-		// Clean and resolve the path under root.
-		rel := filepath.Clean(p)
-		// Disallow absolute paths by making them relative.
-		if filepath.IsAbs(rel) {
-			// turn "/abs/path" into "abs/path"
-			rel = strings.TrimPrefix(rel, string(os.PathSeparator))
+			return nil, fmt.Errorf("entry %d: file path cannot be empty", i)
 		}
-		full := filepath.Join(root, rel)
-		full = filepath.Clean(full)
-
+		rel, full := resolveUnderRoot(root, p)
 		// Ensure the target path is within root (prevent path traversal).
 		if !IsPathWithinRoot(root, full) {
-			return fmt.Errorf("entry %d: path escapes root: %s", i, p)
-		}
-
-		// Materialize parent directories.
-		dir := filepath.Dir(full)
-		log.Debug("Creating directory", "dir", dir)
-		if err := os.MkdirAll(dir, 0o755); err != nil {
-			return fmt.Errorf("entry %d: failed to create directories for %s: %w", i, full, err)
+			return nil, fmt.Errorf("entry %d: path escapes root: %s", i, p)
 		}
+		resolved = append(resolved, resolvedEntry{index: i, rel: rel, full: full, content: f.GetContent()})
+	}
+	return resolved, nil
+}
 
-		// Write file (overwrite if exists).
-		log.Debug("Writing file", "rel", rel, "full", full)
-		if err := os.WriteFile(full, []byte(f.GetContent()), 0o644); err != nil {
-			return fmt.Errorf("entry %d: failed to write file %s: %w", i, full, err)
-		}
+// resolveUnderRoot cleans p and joins it to root. Absolute paths are made
+// relative ("/abs/path" becomes "abs/path").
+func resolveUnderRoot(root, p string) (rel, full string) {
+	rel = filepath.Clean(p)
+	if filepath.IsAbs(rel) {
+		rel = strings.TrimPrefix(rel, string(os.PathSeparator))
 	}
-	return nil
+	full = filepath.Clean(filepath.Join(root, rel))
+	if r, err := filepath.Rel(root, full); err == nil {
+		rel = r
+	}
+	return filepath.ToSlash(rel), full
 }
 
 // IsPathWithinRoot checks whether target is inside root directory.
@@ -125,3 +241,18 @@ func IsPathWithinRoot(root, target string) bool {
 	}
 	return true
 }
+
+// binarySniffLen is how much of a file IsBinaryContent searches for NUL bytes,
+// the same heuristic git uses.
+const binarySniffLen = 8000
+
+// IsBinaryContent reports whether file content is binary rather than text: it
+// has a NUL byte within its first 8000 bytes or is not valid UTF-8. Binary
+// files, such as downloaded attachments, are written as is but bypass the
+// token budget, secret scanning and diffs.
+func IsBinaryContent(content string) bool {
+	if strings.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0 {
+		return true
+	}
+	return !utf8.ValidString(content)
+}
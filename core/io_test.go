@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/opensdd/osdd-api/clients/go/osdd"
@@ -146,3 +147,12 @@ func TestPersistMaterializedResult(t *testing.T) {
 		assert.Contains(t, err.Error(), "directory path escapes root")
 	})
 }
+
+func TestIsBinaryContent(t *testing.T) {
+	t.Parallel()
+	assert.False(t, IsBinaryContent(""))
+	assert.False(t, IsBinaryContent("plain text\nwith ünïcode\n"))
+	assert.True(t, IsBinaryContent("GIF89a\x00\x01"))
+	assert.True(t, IsBinaryContent("caf\xe9"))
+	assert.False(t, IsBinaryContent(strings.Repeat("a", binarySniffLen)+"\x00"), "NUL bytes past the sniffed prefix are ignored")
+}
@@ -0,0 +1,320 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ManifestPath is the root-relative location of the materialization manifest.
+const ManifestPath = ".osdd/manifest.json"
+
+// manifestBackupDir holds copies of files that existed before they were first
+// overwritten by a persisted entry, so that revert can restore them.
+const manifestBackupDir = ".osdd/backups"
+
+const manifestVersion = 1
+
+// Manifest records the files written by PersistMaterializedResultWithOptions.
+type Manifest struct {
+	Version int            `json:"version"`
+	Files   []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a single managed file.
+type ManifestFile struct {
+	// Path is root-relative and slash-separated.
+	Path string `json:"path"`
+	// SHA256 is the hex digest of the content last written to Path.
+	SHA256 string `json:"sha256"`
+	// Recipe is the PersistOptions.RecipeID that produced the file.
+	Recipe string `json:"recipe,omitempty"`
+	// Backup is the root-relative path of the content Path had before it was
+	// first overwritten. Empty when the file did not exist beforehand.
+	Backup string `json:"backup,omitempty"`
+}
+
+// RevertReport describes what RevertMaterialized did.
+type RevertReport struct {
+	// Removed lists managed files that were deleted.
+	Removed []string
+	// Restored lists files that were restored from their pre-materialization backup.
+	Restored []string
+	// Modified lists managed files left in place because a user edited them.
+	Modified []string
+}
+
+// ReadManifest loads the manifest under root. A missing manifest yields an empty one.
+func ReadManifest(root string) (*Manifest, error) {
+	p := filepath.Join(root, ManifestPath)
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Manifest{Version: manifestVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", p, err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", p, err)
+	}
+	return &m, nil
+}
+
+// RevertMaterialized removes the files managed for recipeID (all recipes when
+// empty) and restores any files that were backed up before their first
+// overwrite, such as merged .mcp.json or .claude/settings.local.json files.
+// Files a user edited since they were persisted are left untouched, reported
+// as Modified and kept in the manifest.
+func RevertMaterialized(_ context.Context, root, recipeID string) (*RevertReport, error) {
+	if strings.TrimSpace(root) == "" {
+		return nil, fmt.Errorf("root path cannot be empty")
+	}
+	m, err := loadManifestState(filepath.Clean(root))
+	if err != nil {
+		return nil, err
+	}
+	report := &RevertReport{}
+	var kept []ManifestFile
+	for _, f := range m.files {
+		if recipeID != "" && f.Recipe != recipeID {
+			kept = append(kept, *f)
+			continue
+		}
+		action, err := m.release(f)
+		if err != nil {
+			return nil, err
+		}
+		switch action {
+		case releaseModified:
+			report.Modified = append(report.Modified, f.Path)
+			kept = append(kept, *f)
+		case releaseRestored:
+			report.Restored = append(report.Restored, f.Path)
+		case releaseRemoved:
+			report.Removed = append(report.Removed, f.Path)
+		}
+	}
+	m.setFiles(kept)
+	if err := m.save(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// manifestState is a manifest loaded for modification under a specific root.
+type manifestState struct {
+	root  string
+	files []*ManifestFile
+	byRel map[string]*ManifestFile
+	// touched holds the paths written during the current persist.
+	touched map[string]bool
+}
+
+func loadManifestState(root string) (*manifestState, error) {
+	m, err := ReadManifest(root)
+	if err != nil {
+		return nil, err
+	}
+	s := &manifestState{root: root, touched: map[string]bool{}}
+	s.setFiles(m.Files)
+	return s, nil
+}
+
+func (s *manifestState) setFiles(files []ManifestFile) {
+	s.files = make([]*ManifestFile, 0, len(files))
+	s.byRel = make(map[string]*ManifestFile, len(files))
+	for i := range files {
+		f := files[i]
+		s.files = append(s.files, &f)
+		s.byRel[f.Path] = &f
+	}
+}
+
+// checkOwnership rejects entries whose path is already managed for a
+// different recipe, so one recipe cannot silently take over another's files.
+func (s *manifestState) checkOwnership(entries []resolvedEntry, recipeID string) error {
+	for _, e := range entries {
+		if e.dir {
+			continue
+		}
+		if f, ok := s.byRel[e.rel]; ok && f.Recipe != recipeID {
+			return fmt.Errorf("entry %d: %s is managed by recipe %q; revert it before materializing recipe %q", e.index, e.rel, f.Recipe, recipeID)
+		}
+	}
+	return nil
+}
+
+// beforeWrite warns about user edits to a managed file and backs up a
+// pre-existing unmanaged file before it is overwritten for the first time.
+func (s *manifestState) beforeWrite(e resolvedEntry, report *PersistReport) error {
+	current, err := os.ReadFile(e.full)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read existing file %s: %w", e.full, err)
+	}
+	if prev, ok := s.byRel[e.rel]; ok {
+		if contentHash(current) != prev.SHA256 {
+			slog.Warn("Managed file was modified since it was materialized; overwriting", "path", e.rel)
+			report.Modified = append(report.Modified, e.rel)
+		}
+		return nil
+	}
+	backup := filepath.ToSlash(filepath.Join(manifestBackupDir, e.rel))
+	full := filepath.Join(s.root, backup)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return fmt.Errorf("failed to create backup directory for %s: %w", e.rel, err)
+	}
+	if err := os.WriteFile(full, current, 0o644); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", e.rel, err)
+	}
+	s.byRel[e.rel] = &ManifestFile{Path: e.rel, Backup: backup}
+	s.files = append(s.files, s.byRel[e.rel])
+	return nil
+}
+
+// recordWrite updates the manifest after e was written.
+func (s *manifestState) recordWrite(e resolvedEntry, recipeID string) {
+	f, ok := s.byRel[e.rel]
+	if !ok {
+		f = &ManifestFile{Path: e.rel}
+		s.byRel[e.rel] = f
+		s.files = append(s.files, f)
+	}
+	f.SHA256 = contentHash([]byte(e.content))
+	f.Recipe = recipeID
+	s.touched[e.rel] = true
+}
+
+// prune releases files managed for recipeID that were not written in this run.
+func (s *manifestState) prune(recipeID string, report *PersistReport) error {
+	var kept []ManifestFile
+	for _, f := range s.files {
+		if s.touched[f.Path] || f.Recipe != recipeID {
+			kept = append(kept, *f)
+			continue
+		}
+		action, err := s.release(f)
+		if err != nil {
+			return err
+		}
+		switch action {
+		case releaseModified:
+			slog.Warn("Stale managed file was modified by the user; leaving it in place", "path", f.Path)
+			report.Modified = append(report.Modified, f.Path)
+			kept = append(kept, *f)
+		case releaseRemoved, releaseRestored:
+			report.Pruned = append(report.Pruned, f.Path)
+		}
+	}
+	s.setFiles(kept)
+	return nil
+}
+
+type releaseAction int
+
+const (
+	releaseMissing releaseAction = iota
+	releaseModified
+	releaseRemoved
+	releaseRestored
+)
+
+// release undoes a managed file: restores its backup when there is one,
+// otherwise deletes it. Files edited since they were written are left alone.
+func (s *manifestState) release(f *ManifestFile) (releaseAction, error) {
+	full := filepath.Join(s.root, filepath.FromSlash(f.Path))
+	current, err := os.ReadFile(full)
+	if errors.Is(err, os.ErrNotExist) {
+		s.dropBackup(f)
+		return releaseMissing, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read managed file %s: %w", f.Path, err)
+	}
+	if contentHash(current) != f.SHA256 {
+		return releaseModified, nil
+	}
+
+	if f.Backup != "" {
+		backupFull := filepath.Join(s.root, filepath.FromSlash(f.Backup))
+		original, err := os.ReadFile(backupFull)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read backup of %s: %w", f.Path, err)
+		}
+		if err := os.WriteFile(full, original, 0o644); err != nil {
+			return 0, fmt.Errorf("failed to restore %s: %w", f.Path, err)
+		}
+		s.dropBackup(f)
+		return releaseRestored, nil
+	}
+
+	if err := os.Remove(full); err != nil {
+		return 0, fmt.Errorf("failed to remove %s: %w", f.Path, err)
+	}
+	removeEmptyParents(s.root, filepath.Dir(full))
+	return releaseRemoved, nil
+}
+
+func (s *manifestState) dropBackup(f *ManifestFile) {
+	if f.Backup == "" {
+		return
+	}
+	full := filepath.Join(s.root, filepath.FromSlash(f.Backup))
+	_ = os.Remove(full)
+	removeEmptyParents(s.root, filepath.Dir(full))
+}
+
+// save writes the manifest, or removes it when no files are managed anymore.
+func (s *manifestState) save() error {
+	p := filepath.Join(s.root, ManifestPath)
+	if len(s.files) == 0 {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove manifest %s: %w", p, err)
+		}
+		removeEmptyParents(s.root, filepath.Dir(p))
+		return nil
+	}
+
+	m := Manifest{Version: manifestVersion, Files: make([]ManifestFile, 0, len(s.files))}
+	for _, f := range s.files {
+		m.Files = append(m.Files, *f)
+	}
+	slices.SortFunc(m.Files, func(a, b ManifestFile) int { return strings.Compare(a.Path, b.Path) })
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest %s: %w", p, err)
+	}
+	return nil
+}
+
+// removeEmptyParents removes dir and its ancestors while they are empty, stopping at root.
+func removeEmptyParents(root, dir string) {
+	for dir != root && IsPathWithinRoot(root, dir) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func contentHash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
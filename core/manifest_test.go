@@ -0,0 +1,211 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func filesResult(files map[string]string) *osdd.MaterializedResult {
+	var entries []*osdd.MaterializedResult_Entry
+	for p, c := range files {
+		entries = append(entries, osdd.MaterializedResult_Entry_builder{
+			File: osdd.FullFileContent_builder{Path: p, Content: c}.Build(),
+		}.Build())
+	}
+	return osdd.MaterializedResult_builder{Entries: entries}.Build()
+}
+
+func readFile(t *testing.T, root, rel string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(root, rel))
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestPersistWithManifest_RecordsFiles(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	opts := PersistOptions{RecipeID: "r1", TrackManifest: true}
+
+	report, err := PersistMaterializedResultWithOptions(context.Background(), root, filesResult(map[string]string{
+		".claude/commands/a.md": "A",
+	}), opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{".claude/commands/a.md"}, report.Written)
+
+	m, err := ReadManifest(root)
+	require.NoError(t, err)
+	require.Len(t, m.Files, 1)
+	assert.Equal(t, ".claude/commands/a.md", m.Files[0].Path)
+	assert.Equal(t, "r1", m.Files[0].Recipe)
+	assert.Equal(t, contentHash([]byte("A")), m.Files[0].SHA256)
+	assert.Empty(t, m.Files[0].Backup)
+}
+
+func TestPersistWithManifest_SavedOnWriteFailure(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "blocker"), []byte("not a dir"), 0o644))
+	// The second entry fails because its parent directory is a file.
+	result := osdd.MaterializedResult_builder{Entries: []*osdd.MaterializedResult_Entry{
+		osdd.MaterializedResult_Entry_builder{File: osdd.FullFileContent_builder{Path: "a.md", Content: "A"}.Build()}.Build(),
+		osdd.MaterializedResult_Entry_builder{File: osdd.FullFileContent_builder{Path: "blocker/b.md", Content: "B"}.Build()}.Build(),
+	}}.Build()
+
+	_, err := PersistMaterializedResultWithOptions(context.Background(), root, result, PersistOptions{RecipeID: "r1", TrackManifest: true})
+	require.Error(t, err)
+
+	m, err := ReadManifest(root)
+	require.NoError(t, err)
+	require.Len(t, m.Files, 1)
+	assert.Equal(t, "a.md", m.Files[0].Path)
+
+	// The file written before the failure can be reverted.
+	rr, err := RevertMaterialized(context.Background(), root, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.md"}, rr.Removed)
+}
+
+func TestPersistWithManifest_PrunesStaleFiles(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	opts := PersistOptions{RecipeID: "r1", TrackManifest: true}
+
+	_, err := PersistMaterializedResultWithOptions(context.Background(), root, filesResult(map[string]string{
+		".claude/commands/a.md": "A",
+		".claude/commands/b.md": "B",
+	}), opts)
+	require.NoError(t, err)
+
+	report, err := PersistMaterializedResultWithOptions(context.Background(), root, filesResult(map[string]string{
+		".claude/commands/a.md": "A2",
+	}), opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{".claude/commands/b.md"}, report.Pruned)
+	assert.NoFileExists(t, filepath.Join(root, ".claude/commands/b.md"))
+	assert.Equal(t, "A2", readFile(t, root, ".claude/commands/a.md"))
+}
+
+func TestPersistWithManifest_PruneScopedToRecipe(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	_, err := PersistMaterializedResultWithOptions(context.Background(), root, filesResult(map[string]string{"other.md": "O"}),
+		PersistOptions{RecipeID: "other", TrackManifest: true})
+	require.NoError(t, err)
+	report, err := PersistMaterializedResultWithOptions(context.Background(), root, filesResult(map[string]string{"mine.md": "M"}),
+		PersistOptions{RecipeID: "mine", TrackManifest: true})
+	require.NoError(t, err)
+
+	assert.Empty(t, report.Pruned)
+	assert.FileExists(t, filepath.Join(root, "other.md"))
+}
+
+func TestPersistWithManifest_RejectsOtherRecipesFiles(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	_, err := PersistMaterializedResultWithOptions(context.Background(), root, filesResult(map[string]string{"shared.md": "O"}),
+		PersistOptions{RecipeID: "other", TrackManifest: true})
+	require.NoError(t, err)
+	_, err = PersistMaterializedResultWithOptions(context.Background(), root, osdd.MaterializedResult_builder{Entries: []*osdd.MaterializedResult_Entry{
+		osdd.MaterializedResult_Entry_builder{File: osdd.FullFileContent_builder{Path: "mine.md", Content: "M"}.Build()}.Build(),
+		osdd.MaterializedResult_Entry_builder{File: osdd.FullFileContent_builder{Path: "shared.md", Content: "M"}.Build()}.Build(),
+	}}.Build(), PersistOptions{RecipeID: "mine", TrackManifest: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `shared.md is managed by recipe "other"`)
+
+	// Nothing was written and ownership is unchanged.
+	assert.NoFileExists(t, filepath.Join(root, "mine.md"))
+	assert.Equal(t, "O", readFile(t, root, "shared.md"))
+	m, err := ReadManifest(root)
+	require.NoError(t, err)
+	require.Len(t, m.Files, 1)
+	assert.Equal(t, "other", m.Files[0].Recipe)
+}
+
+func TestPersistWithManifest_WarnsOnUserEdits(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	opts := PersistOptions{RecipeID: "r1", TrackManifest: true}
+
+	_, err := PersistMaterializedResultWithOptions(context.Background(), root, filesResult(map[string]string{
+		"keep.md":  "K",
+		"stale.md": "S",
+	}), opts)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "keep.md"), []byte("edited"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "stale.md"), []byte("edited"), 0o644))
+
+	report, err := PersistMaterializedResultWithOptions(context.Background(), root, filesResult(map[string]string{
+		"keep.md": "K2",
+	}), opts)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"keep.md", "stale.md"}, report.Modified)
+	assert.Empty(t, report.Pruned)
+	assert.Equal(t, "K2", readFile(t, root, "keep.md"))
+	assert.Equal(t, "edited", readFile(t, root, "stale.md"), "user-edited stale file must be kept")
+}
+
+func TestPersistWithManifest_BacksUpAndRevertRestores(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	opts := PersistOptions{RecipeID: "r1", TrackManifest: true}
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".mcp.json"), []byte(`{"mcpServers":{}}`), 0o644))
+
+	_, err := PersistMaterializedResultWithOptions(context.Background(), root, filesResult(map[string]string{
+		".mcp.json":             `{"mcpServers":{"x":{}}}`,
+		".claude/commands/a.md": "A",
+	}), opts)
+	require.NoError(t, err)
+	assert.Equal(t, `{"mcpServers":{}}`, readFile(t, root, ".osdd/backups/.mcp.json"))
+
+	// A second persist must not replace the original backup with merged content.
+	_, err = PersistMaterializedResultWithOptions(context.Background(), root, filesResult(map[string]string{
+		".mcp.json":             `{"mcpServers":{"x":{},"y":{}}}`,
+		".claude/commands/a.md": "A",
+	}), opts)
+	require.NoError(t, err)
+	assert.Equal(t, `{"mcpServers":{}}`, readFile(t, root, ".osdd/backups/.mcp.json"))
+
+	report, err := RevertMaterialized(context.Background(), root, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{".mcp.json"}, report.Restored)
+	assert.Equal(t, []string{".claude/commands/a.md"}, report.Removed)
+	assert.Equal(t, `{"mcpServers":{}}`, readFile(t, root, ".mcp.json"))
+	assert.NoFileExists(t, filepath.Join(root, ".claude/commands/a.md"))
+	assert.NoDirExists(t, filepath.Join(root, ".claude"))
+	assert.NoDirExists(t, filepath.Join(root, ".osdd"))
+}
+
+func TestRevertMaterialized_KeepsModified(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	_, err := PersistMaterializedResultWithOptions(context.Background(), root, filesResult(map[string]string{"a.md": "A"}),
+		PersistOptions{RecipeID: "r1", TrackManifest: true})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.md"), []byte("mine"), 0o644))
+
+	report, err := RevertMaterialized(context.Background(), root, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.md"}, report.Modified)
+	assert.Equal(t, "mine", readFile(t, root, "a.md"))
+
+	m, err := ReadManifest(root)
+	require.NoError(t, err)
+	assert.Len(t, m.Files, 1)
+}
+
+func TestPersistWithoutManifest_WritesNoManifest(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	require.NoError(t, PersistMaterializedResult(context.Background(), root, filesResult(map[string]string{"a.md": "A"})))
+	assert.NoFileExists(t, filepath.Join(root, ManifestPath))
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"time"
+
+	"github.com/opensdd/osdd-core/core/utils"
+)
+
+// EntryOptions holds per-entry settings that the recipe schema has no field for.
+// They are looked up by context entry path in GenerationContext.EntryOptions.
+type EntryOptions struct {
+	// Optional turns a failure of the entry into a skipped entry that is
+	// reported but does not fail materialization.
+	Optional bool
+
+	// CacheTTL caches the entry for this long when GenerationContext.Cache is
+	// set, overriding GenerationContext.CacheTTLs. Cmd entries and Combined
+	// entries with command items are only cached when it is positive.
+	CacheTTL time.Duration
+
+	// Priority orders entries for GenerationContext.TokenBudget: files of
+	// higher-priority entries keep their content first. The default is 0.
+	Priority int
+
+	// Directory reads a LocalFile source as a directory, producing one file per
+	// selected file under the entry path. Directory mode is also used without
+	// options when the LocalFile path is a directory or contains a glob such
+	// as "docs/adr/**/*.md".
+	Directory *utils.DirectoryOptions
+
+	// Git configures the checkout of a GitRepo source: ref, shallow depth,
+	// sparse paths, submodules and single-branch cloning.
+	Git *utils.GitCheckoutOptions
+
+	// Transforms post-process the fetched content in order, e.g. extracting a
+	// JSON path from command output or wrapping it in a code fence. They apply
+	// to Text, Cmd, Github, LocalFile (every file in directory mode), Combined,
+	// PrefetchId, UserInput and UrlFetch sources. Binary content (see
+	// IsBinaryContent) is left unchanged.
+	Transforms []utils.Transform
+
+	// HTMLToMarkdown converts UrlFetch responses served as HTML into Markdown,
+	// dropping scripts, styles and navigation chrome. Converted content is
+	// written under a ".md" path (see utils.MarkdownPath). Other content types
+	// are written unchanged.
+	HTMLToMarkdown bool
+
+	// Jira configures JiraIssues sources, e.g. rendering issues as Markdown.
+	Jira *utils.JiraOptions
+
+	// Linear configures LinearIssues sources, e.g. filtering by state type or
+	// fetching projects and documents instead of issues.
+	Linear *utils.LinearOptions
+
+	// GitHubIssues turns a GitHistory entry of a GitHub repository into an
+	// issue source: the repository's issues are fetched instead of its
+	// history, in the layout of JiraIssues and LinearIssues entries. The
+	// entry's date filter keeps issues created or updated within its range.
+	GitHubIssues *utils.GitHubIssuesOptions
+}
+
+// EntryOptionsFor returns the options for the context entry at path, or zero
+// options when none are configured.
+func (g *GenerationContext) EntryOptionsFor(path string) EntryOptions {
+	if g == nil {
+		return EntryOptions{}
+	}
+	if opts := g.EntryOptions[path]; opts != nil {
+		return *opts
+	}
+	return EntryOptions{}
+}
@@ -0,0 +1,193 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+)
+
+// PlanAction classifies what persisting an entry would do.
+type PlanAction string
+
+const (
+	// PlanCreate writes a file that does not exist yet.
+	PlanCreate PlanAction = "create"
+	// PlanUpdate overwrites an existing file with different content.
+	PlanUpdate PlanAction = "update"
+	// PlanUnchanged rewrites a file with identical content.
+	PlanUnchanged PlanAction = "unchanged"
+	// PlanDirectory ensures a directory exists.
+	PlanDirectory PlanAction = "directory"
+	// PlanPrune removes (or restores from backup) a stale managed file.
+	// It only appears when planning with PersistOptions.TrackManifest.
+	PlanPrune PlanAction = "prune"
+)
+
+// PlannedEntry is the planned outcome for a single path.
+type PlannedEntry struct {
+	// Path is root-relative and slash-separated.
+	Path   string
+	Action PlanAction
+	// Diff is a unified diff of the on-disk content against the new content.
+	// Empty for unchanged files and directories.
+	Diff string
+}
+
+// PersistPlan is the dry-run result of PlanMaterializedResult.
+type PersistPlan struct {
+	Root    string
+	Entries []PlannedEntry
+	// Secrets lists the findings of PersistOptions.SecretScanner. With
+	// SecretPolicyRedact the diffs show the redacted content.
+	Secrets []SecretFinding
+}
+
+// Counts returns the number of planned entries per action.
+func (p *PersistPlan) Counts() map[PlanAction]int {
+	counts := map[PlanAction]int{}
+	if p == nil {
+		return counts
+	}
+	for _, e := range p.Entries {
+		counts[e.Action]++
+	}
+	return counts
+}
+
+// HasChanges reports whether persisting would create, update or prune any file.
+func (p *PersistPlan) HasChanges() bool {
+	if p == nil {
+		return false
+	}
+	for _, e := range p.Entries {
+		switch e.Action {
+		case PlanCreate, PlanUpdate, PlanPrune:
+			return true
+		}
+	}
+	return false
+}
+
+// Diff concatenates the unified diffs of all planned entries.
+func (p *PersistPlan) Diff() string {
+	if p == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, e := range p.Entries {
+		b.WriteString(e.Diff)
+	}
+	return b.String()
+}
+
+// PlanMaterializedResult computes what PersistMaterializedResultWithOptions
+// would do for result under root without touching the filesystem. Paths are
+// validated the same way, so a plan that succeeds can be persisted. Files that
+// context sources write during materialization are only planned when their
+// writes were deferred (see GenerationContext.DeferWrites).
+func PlanMaterializedResult(_ context.Context, root string, result *osdd.MaterializedResult, opts PersistOptions) (*PersistPlan, error) {
+	if strings.TrimSpace(root) == "" {
+		return nil, fmt.Errorf("root path cannot be empty")
+	}
+	if result == nil {
+		return nil, fmt.Errorf("materialized result cannot be nil")
+	}
+	root = filepath.Clean(root)
+
+	resolved, err := resolveEntries(root, result.GetEntries())
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := scanSecrets(resolved, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &PersistPlan{Root: root, Secrets: secrets}
+	// Later entries overwrite earlier ones with the same path, so only the last one counts.
+	last := make(map[string]int, len(resolved))
+	for i, e := range resolved {
+		last[e.rel] = i
+	}
+	for i, e := range resolved {
+		if last[e.rel] != i {
+			continue
+		}
+		if e.dir {
+			plan.Entries = append(plan.Entries, PlannedEntry{Path: e.rel, Action: PlanDirectory})
+			continue
+		}
+		current, err := os.ReadFile(e.full)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			plan.Entries = append(plan.Entries, PlannedEntry{
+				Path:   e.rel,
+				Action: PlanCreate,
+				Diff:   UnifiedDiff("/dev/null", "b/"+e.rel, "", e.content),
+			})
+		case err != nil:
+			return nil, fmt.Errorf("entry %d: failed to read existing file %s: %w", e.index, e.full, err)
+		case string(current) == e.content:
+			plan.Entries = append(plan.Entries, PlannedEntry{Path: e.rel, Action: PlanUnchanged})
+		default:
+			plan.Entries = append(plan.Entries, PlannedEntry{
+				Path:   e.rel,
+				Action: PlanUpdate,
+				Diff:   UnifiedDiff("a/"+e.rel, "b/"+e.rel, string(current), e.content),
+			})
+		}
+	}
+
+	if opts.TrackManifest {
+		if err := planPrunes(plan, root, last, opts.RecipeID); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// planPrunes adds the stale managed files that persisting would release.
+func planPrunes(plan *PersistPlan, root string, produced map[string]int, recipeID string) error {
+	m, err := ReadManifest(root)
+	if err != nil {
+		return err
+	}
+	for _, f := range m.Files {
+		if _, ok := produced[f.Path]; ok || f.Recipe != recipeID {
+			continue
+		}
+		current, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f.Path)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read managed file %s: %w", f.Path, err)
+		}
+		if contentHash(current) != f.SHA256 {
+			// User-edited files are left in place by persist.
+			continue
+		}
+		restored := ""
+		newLabel := "/dev/null"
+		if f.Backup != "" {
+			b, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f.Backup)))
+			if err != nil {
+				return fmt.Errorf("failed to read backup of %s: %w", f.Path, err)
+			}
+			restored = string(b)
+			newLabel = "b/" + f.Path
+		}
+		plan.Entries = append(plan.Entries, PlannedEntry{
+			Path:   f.Path,
+			Action: PlanPrune,
+			Diff:   UnifiedDiff("a/"+f.Path, newLabel, string(current), restored),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func planByPath(plan *PersistPlan) map[string]PlannedEntry {
+	out := map[string]PlannedEntry{}
+	for _, e := range plan.Entries {
+		out[e.Path] = e
+	}
+	return out
+}
+
+func TestPlanMaterializedResult_ClassifiesEntries(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	dir := "docs"
+	require.NoError(t, os.WriteFile(filepath.Join(root, "same.md"), []byte("same\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "changed.md"), []byte("old\n"), 0o644))
+
+	result := osdd.MaterializedResult_builder{Entries: []*osdd.MaterializedResult_Entry{
+		osdd.MaterializedResult_Entry_builder{Directory: &dir}.Build(),
+		osdd.MaterializedResult_Entry_builder{File: osdd.FullFileContent_builder{Path: "same.md", Content: "same\n"}.Build()}.Build(),
+		osdd.MaterializedResult_Entry_builder{File: osdd.FullFileContent_builder{Path: "changed.md", Content: "new\n"}.Build()}.Build(),
+		osdd.MaterializedResult_Entry_builder{File: osdd.FullFileContent_builder{Path: "docs/new.md", Content: "draft\n"}.Build()}.Build(),
+		osdd.MaterializedResult_Entry_builder{File: osdd.FullFileContent_builder{Path: "docs/new.md", Content: "final\n"}.Build()}.Build(),
+	}}.Build()
+
+	plan, err := PlanMaterializedResult(context.Background(), root, result, PersistOptions{})
+	require.NoError(t, err)
+	require.Len(t, plan.Entries, 4, "duplicate paths collapse to the last entry")
+
+	byPath := planByPath(plan)
+	assert.Equal(t, PlanDirectory, byPath["docs"].Action)
+	assert.Equal(t, PlanUnchanged, byPath["same.md"].Action)
+	assert.Empty(t, byPath["same.md"].Diff)
+	assert.Equal(t, PlanUpdate, byPath["changed.md"].Action)
+	assert.Equal(t, "--- a/changed.md\n+++ b/changed.md\n@@ -1 +1 @@\n-old\n+new\n", byPath["changed.md"].Diff)
+	assert.Equal(t, PlanCreate, byPath["docs/new.md"].Action)
+	assert.Equal(t, "--- /dev/null\n+++ b/docs/new.md\n@@ -0,0 +1 @@\n+final\n", byPath["docs/new.md"].Diff)
+
+	assert.True(t, plan.HasChanges())
+	assert.Equal(t, map[PlanAction]int{PlanDirectory: 1, PlanUnchanged: 1, PlanUpdate: 1, PlanCreate: 1}, plan.Counts())
+	assert.NoFileExists(t, filepath.Join(root, "docs", "new.md"))
+	assert.Equal(t, "old\n", readFile(t, root, "changed.md"))
+}
+
+func TestPlanMaterializedResult_NoChanges(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.md"), []byte("A"), 0o644))
+
+	plan, err := PlanMaterializedResult(context.Background(), root, filesResult(map[string]string{"a.md": "A"}), PersistOptions{})
+	require.NoError(t, err)
+	assert.False(t, plan.HasChanges())
+	assert.Empty(t, plan.Diff())
+}
+
+func TestPlanMaterializedResult_IncludesPrunes(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	opts := PersistOptions{RecipeID: "r1", TrackManifest: true}
+	_, err := PersistMaterializedResultWithOptions(context.Background(), root, filesResult(map[string]string{
+		"a.md": "A\n",
+		"b.md": "B\n",
+	}), opts)
+	require.NoError(t, err)
+
+	plan, err := PlanMaterializedResult(context.Background(), root, filesResult(map[string]string{"a.md": "A\n"}), opts)
+	require.NoError(t, err)
+	byPath := planByPath(plan)
+	assert.Equal(t, PlanUnchanged, byPath["a.md"].Action)
+	assert.Equal(t, PlanPrune, byPath["b.md"].Action)
+	assert.Equal(t, "--- a/b.md\n+++ /dev/null\n@@ -1 +0,0 @@\n-B\n", byPath["b.md"].Diff)
+	assert.FileExists(t, filepath.Join(root, "b.md"))
+
+	plan, err = PlanMaterializedResult(context.Background(), root, filesResult(map[string]string{"a.md": "A\n"}), PersistOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, planByPath(plan), "b.md", "prunes are only planned when tracking the manifest")
+}
+
+func TestPlanMaterializedResult_RejectsEscapingPaths(t *testing.T) {
+	t.Parallel()
+	_, err := PlanMaterializedResult(context.Background(), t.TempDir(), filesResult(map[string]string{"../x.md": "X"}), PersistOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "path escapes root")
+}
@@ -43,7 +43,7 @@ func (n *noOpSettings) Update(context.Context, SettingsInput) ([]*osdd.Materiali
 // - <CommandsFolder>/<name>.md files for each command
 // - <MCPServersJSONPath> for MCP server definitions
 // - settings updated/created by IDESettings
-func (i *IDE) Materialize(ctx context.Context, _ *core.GenerationContext, ide *recipes.Ide) (*osdd.MaterializedResult, error) {
+func (i *IDE) Materialize(ctx context.Context, genCtx *core.GenerationContext, ide *recipes.Ide) (*osdd.MaterializedResult, error) {
 	if ide == nil {
 		return nil, fmt.Errorf("ide cannot be nil")
 	}
@@ -52,7 +52,7 @@ func (i *IDE) Materialize(ctx context.Context, _ *core.GenerationContext, ide *r
 
 	// Commands -> <CommandsFolder>/commands/<name>.md
 	if ide.HasCommands() {
-		cmdEntries, err := i.materializeCommands(ctx, ide.GetCommands())
+		cmdEntries, err := i.materializeCommands(ctx, genCtx, ide.GetCommands())
 		if err != nil {
 			return nil, err
 		}
@@ -102,7 +102,7 @@ func (i *IDE) PrepareStart(context.Context, *core.GenerationContext) (core.ExecP
 	return core.ExecProps{}, nil
 }
 
-func (i *IDE) materializeCommands(ctx context.Context, commands *recipes.Commands) ([]*osdd.MaterializedResult_Entry, error) {
+func (i *IDE) materializeCommands(ctx context.Context, genCtx *core.GenerationContext, commands *recipes.Commands) ([]*osdd.MaterializedResult_Entry, error) {
 	var entries []*osdd.MaterializedResult_Entry
 	if commands == nil {
 		return entries, nil
@@ -117,7 +117,7 @@ func (i *IDE) materializeCommands(ctx context.Context, commands *recipes.Command
 			return nil, fmt.Errorf("command %s must have a 'from' source", name)
 		}
 
-		content, err := i.fetchCommandContent(ctx, c.GetFrom())
+		content, err := i.fetchCommandContent(ctx, genCtx, name, c.GetFrom())
 		if err != nil {
 			return nil, fmt.Errorf("failed to materialize command %s: %w", name, err)
 		}
@@ -151,18 +151,24 @@ func (i *IDE) materializeMcp(mcp *recipes.Mcp) ([]*osdd.MaterializedResult_Entry
 	return entries, nil
 }
 
-func (i *IDE) fetchCommandContent(ctx context.Context, from *recipes.CommandFrom) (string, error) {
+// fetchCommandContent returns the content of the command called name. Text
+// sources and command arguments are rendered with genCtx.RenderTemplate.
+func (i *IDE) fetchCommandContent(ctx context.Context, genCtx *core.GenerationContext, name string, from *recipes.CommandFrom) (string, error) {
 	if from == nil || !from.HasType() {
 		return "", fmt.Errorf("command 'from' source cannot be nil")
 	}
 
 	switch from.WhichType() {
 	case recipes.CommandFrom_Text_case:
-		return from.GetText(), nil
+		return genCtx.RenderTemplate("commands."+name+".text", from.GetText())
 	case recipes.CommandFrom_Cmd_case:
-		return utils.ExecuteCommand(ctx, from.GetCmd())
+		cmd, err := genCtx.RenderExec("commands."+name+".cmd", from.GetCmd())
+		if err != nil {
+			return "", err
+		}
+		return utils.ExecuteCommand(ctx, cmd)
 	case recipes.CommandFrom_Github_case:
-		return utils.FetchGithub(ctx, from.GetGithub())
+		return utils.FetchGitFile(ctx, from.GetGithub(), genCtx.GitFileTokens())
 	default:
 		return "", fmt.Errorf("unknown or unset command source type")
 	}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/opensdd/osdd-api/clients/go/osdd"
 	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
 	"github.com/opensdd/osdd-core/core"
 	"github.com/stretchr/testify/assert"
@@ -67,3 +68,31 @@ func TestIDE_Materialize_Mcp(t *testing.T) {
 	assert.Equal(t, "devplan", parsed.McpServers["devplan"].Command)
 	assert.Equal(t, []string{"mcp"}, parsed.McpServers["devplan"].Args)
 }
+
+func TestIDE_Materialize_CommandsRenderTemplates(t *testing.T) {
+	t.Parallel()
+	g := getIDE()
+	text := "Implement {{.UserInput.ticket}}"
+	ide := recipes.Ide_builder{
+		Commands: recipes.Commands_builder{Entries: []*recipes.Command{
+			recipes.Command_builder{Name: "impl", From: recipes.CommandFrom_builder{Text: &text}.Build()}.Build(),
+			recipes.Command_builder{Name: "show", From: recipes.CommandFrom_builder{
+				Cmd: osdd.Exec_builder{Cmd: "echo", Args: []string{"ticket {{.UserInput.ticket}}"}}.Build(),
+			}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+	genCtx := &core.GenerationContext{RenderTemplates: true, UserInput: map[string]string{"ticket": "ABC-1"}}
+
+	res, err := g.Materialize(context.Background(), genCtx, ide)
+	require.NoError(t, err)
+	contents := map[string]string{}
+	for _, e := range res.GetEntries() {
+		contents[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, "Implement ABC-1", contents[".claude/commands//impl.md"])
+	assert.Equal(t, "ticket ABC-1\n", contents[".claude/commands//show.md"])
+
+	genCtx.UserInput = nil
+	_, err = g.Materialize(context.Background(), genCtx, ide)
+	assert.ErrorContains(t, err, "commands.impl.text")
+}
@@ -5,13 +5,21 @@ import (
 	"fmt"
 
 	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"github.com/opensdd/osdd-core/core"
 	"github.com/opensdd/osdd-core/core/utils"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 type Processor struct{}
 
 func (p *Processor) Process(ctx context.Context, prefetch *osdd.Prefetch) (map[string]*osdd.FetchedData, error) {
+	return p.ProcessWithGenerationContext(ctx, prefetch, nil)
+}
+
+// ProcessWithGenerationContext behaves like Process and renders the command
+// arguments of every entry with genCtx.RenderExec before running it.
+func (p *Processor) ProcessWithGenerationContext(ctx context.Context, prefetch *osdd.Prefetch, genCtx *core.GenerationContext) (map[string]*osdd.FetchedData, error) {
 	entries := prefetch.GetEntries()
 	if len(entries) == 0 {
 		return nil, nil
@@ -24,6 +32,17 @@ func (p *Processor) Process(ctx context.Context, prefetch *osdd.Prefetch) (map[s
 			return nil, fmt.Errorf("prefetch entry at index %d is nil", i)
 		}
 
+		if entry.WhichType() == osdd.PrefetchEntry_Cmd_case {
+			cmd, err := genCtx.RenderExec(fmt.Sprintf("prefetch[%d].cmd", i), entry.GetCmd())
+			if err != nil {
+				return nil, err
+			}
+			if cmd != entry.GetCmd() {
+				entry = proto.CloneOf(entry)
+				entry.SetCmd(cmd)
+			}
+		}
+
 		// Process the entry based on its type
 		data, err := p.processEntry(ctx, entry)
 		if err != nil {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"github.com/opensdd/osdd-core/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -180,6 +181,21 @@ func TestProcessor_ProcessEntry(t *testing.T) {
 	}
 }
 
+func TestProcessor_ProcessWithGenerationContext_RendersArgs(t *testing.T) {
+	t.Parallel()
+	genCtx := &core.GenerationContext{RenderTemplates: true, UserInput: map[string]string{"ticket": "ABC-1"}}
+	p := &Processor{}
+
+	result, err := p.ProcessWithGenerationContext(context.Background(),
+		prefetchWith(cmdEntry("echo", `{"data": [{"id": "plan", "data": "{{.UserInput.ticket}}"}]}`)), genCtx)
+	require.NoError(t, err)
+	assertResult(t, result, map[string]string{"plan": "ABC-1"})
+
+	_, err = p.ProcessWithGenerationContext(context.Background(),
+		prefetchWith(cmdEntry("echo", "{{.UserInput.missing}}")), genCtx)
+	assert.ErrorContains(t, err, "prefetch[0].cmd.args[0]")
+}
+
 func TestProcessor_Process_CancelledContext(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -3,6 +3,7 @@ package providers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/opensdd/osdd-api/clients/go/osdd"
 	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
@@ -23,7 +24,14 @@ func (r *Recipe) Materialize(
 	}
 	if pf := recipe.GetPrefetch(); pf != nil {
 		p := prefetch.Processor{}
-		entries, err := p.Process(ctx, pf)
+		genCtx.Emit(core.Event{Type: core.EventPrefetchStart})
+		start := time.Now()
+		entries, err := p.ProcessWithGenerationContext(ctx, pf, genCtx)
+		var bytes int64
+		for _, v := range entries {
+			bytes += int64(len(v.GetData()))
+		}
+		genCtx.Emit(core.Event{Type: core.EventPrefetchEnd, Bytes: bytes, Duration: time.Since(start), Err: err})
 		if err != nil {
 			return nil, fmt.Errorf("failed to process prefetch: %w", err)
 		}
@@ -49,7 +57,10 @@ func (r *Recipe) Materialize(
 
 	// Materialize IDE configuration if present
 	if recipe.HasIde() {
+		genCtx.Emit(core.Event{Type: core.EventIDEMaterializeStart})
+		start := time.Now()
 		ideResult, err := r.IDE.Materialize(ctx, genCtx, recipe.GetIde())
+		genCtx.Emit(core.Event{Type: core.EventIDEMaterializeEnd, Bytes: core.ContentBytes(ideResult), Duration: time.Since(start), Err: err})
 		if err != nil {
 			return nil, fmt.Errorf("failed to materialize IDE configuration: %w", err)
 		}
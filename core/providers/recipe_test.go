@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/opensdd/osdd-api/clients/go/osdd"
@@ -417,3 +418,62 @@ func TestRecipe_Materialize_MultipleMcpServers(t *testing.T) {
 	assert.Equal(t, "stdio-mcp", mcp.McpServers["stdio-server"]["command"])
 	assert.Equal(t, "another-mcp-server", mcp.McpServers["another-stdio"]["command"])
 }
+
+func TestRecipe_Materialize_EmitsEvents(t *testing.T) {
+	t.Parallel()
+	r := &providers.Recipe{IDE: getIDE()}
+
+	recipe := recipes.Recipe_builder{
+		Prefetch: osdd.Prefetch_builder{Entries: []*osdd.PrefetchEntry{
+			osdd.PrefetchEntry_builder{Cmd: ex("echo", `{"data":[{"id":"plan","data":"p"}]}`)}.Build(),
+		}}.Build(),
+		Context: recipes.Context_builder{
+			Entries: []*recipes.ContextEntry{
+				recipes.ContextEntry_builder{
+					Path: "docs/arch.md",
+					From: recipes.ContextFrom_builder{Text: strPtr("# Architecture")}.Build(),
+				}.Build(),
+			},
+		}.Build(),
+		Ide: recipes.Ide_builder{
+			Commands: recipes.Commands_builder{
+				Entries: []*recipes.Command{
+					recipes.Command_builder{
+						Name: "deploy",
+						From: recipes.CommandFrom_builder{Text: strPtr("Deploy")}.Build(),
+					}.Build(),
+				},
+			}.Build(),
+		}.Build(),
+	}.Build()
+
+	var mu sync.Mutex
+	var events []core.Event
+	genCtx := &core.GenerationContext{Observer: core.ObserverFunc(func(e core.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})}
+
+	_, err := r.Materialize(context.Background(), genCtx, recipe)
+	require.NoError(t, err)
+
+	var types []core.EventType
+	for _, e := range events {
+		assert.False(t, e.Time.IsZero())
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []core.EventType{
+		core.EventPrefetchStart, core.EventPrefetchEnd,
+		core.EventEntryStart, core.EventEntryFinish,
+		core.EventIDEMaterializeStart, core.EventIDEMaterializeEnd,
+	}, types)
+
+	assert.Equal(t, int64(1), events[1].Bytes)
+	finish := events[3]
+	assert.Equal(t, "docs/arch.md", finish.Path)
+	assert.Equal(t, core.SourceText, finish.Kind)
+	assert.Equal(t, core.OutcomeSucceeded, finish.Outcome)
+	assert.Equal(t, int64(len("# Architecture")), finish.Bytes)
+	assert.Equal(t, int64(len("Deploy")), events[5].Bytes)
+}
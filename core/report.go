@@ -0,0 +1,110 @@
+package core
+
+import (
+	"errors"
+	"time"
+)
+
+// EntryOutcome is the result of materializing a single context entry.
+type EntryOutcome string
+
+const (
+	// OutcomeSucceeded means the entry produced its content.
+	OutcomeSucceeded EntryOutcome = "succeeded"
+	// OutcomeSkipped means an optional entry failed and was left out.
+	OutcomeSkipped EntryOutcome = "skipped"
+	// OutcomeFailed means a required entry failed.
+	OutcomeFailed EntryOutcome = "failed"
+	// OutcomeCanceled means the entry was stopped or never started because
+	// another entry failed first in fail-fast mode.
+	OutcomeCanceled EntryOutcome = "canceled"
+)
+
+// EntryReport describes how a single context entry was materialized.
+type EntryReport struct {
+	Path    string
+	Kind    SourceKind
+	Outcome EntryOutcome
+	// Err is the failure of skipped and failed entries.
+	Err error
+	// Bytes is the size of the produced content, including content written
+	// directly to the workspace (e.g. URL fetches).
+	Bytes int64
+	// Tokens estimates the token count of the produced in-memory file content.
+	// Binary files are not counted.
+	Tokens   int
+	Duration time.Duration
+}
+
+// MaterializationReport lists the outcome of every context entry in recipe order.
+type MaterializationReport struct {
+	Entries []EntryReport
+	// Budget describes token budget enforcement; nil when no budget is set.
+	Budget *BudgetReport
+}
+
+// BudgetAction is what the token budget enforcer did to a file.
+type BudgetAction string
+
+const (
+	// BudgetTruncated means the file was cut to fit the remaining budget.
+	BudgetTruncated BudgetAction = "truncated"
+	// BudgetDropped means the file was left out entirely.
+	BudgetDropped BudgetAction = "dropped"
+)
+
+// BudgetCut records a file the token budget enforcer truncated or dropped.
+type BudgetCut struct {
+	// Path is the file path; Entry is the path of the context entry that produced it.
+	Path     string
+	Entry    string
+	Priority int
+	Action   BudgetAction
+	// Tokens is the file's size before enforcement, KeptTokens after it.
+	Tokens     int
+	KeptTokens int
+}
+
+// BudgetReport summarizes token budget enforcement.
+type BudgetReport struct {
+	Budget int
+	// Tokens is the total before enforcement, UsedTokens the total kept.
+	Tokens     int
+	UsedTokens int
+	Cuts       []BudgetCut
+}
+
+// WithOutcome returns the reports of entries with the given outcome.
+func (r *MaterializationReport) WithOutcome(outcome EntryOutcome) []EntryReport {
+	if r == nil {
+		return nil
+	}
+	var out []EntryReport
+	for _, e := range r.Entries {
+		if e.Outcome == outcome {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Err joins the errors of all failed entries, or returns nil when none failed.
+func (r *MaterializationReport) Err() error {
+	var errs []error
+	for _, e := range r.WithOutcome(OutcomeFailed) {
+		errs = append(errs, e.Err)
+	}
+	return errors.Join(errs...)
+}
+
+// TotalBytes sums Bytes over all entries.
+func (r *MaterializationReport) TotalBytes() int64 {
+	if r == nil {
+		return 0
+	}
+	var total int64
+	for _, e := range r.Entries {
+		total += e.Bytes
+	}
+	return total
+}
@@ -0,0 +1,24 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaterializationReport(t *testing.T) {
+	t.Parallel()
+	r := &MaterializationReport{Entries: []EntryReport{
+		{Path: "a", Outcome: OutcomeSucceeded, Bytes: 10},
+		{Path: "b", Outcome: OutcomeSkipped, Err: errors.New("flaky"), Bytes: 0},
+		{Path: "c", Outcome: OutcomeFailed, Err: errors.New("boom"), Bytes: 5},
+	}}
+	assert.Equal(t, int64(15), r.TotalBytes())
+	assert.Equal(t, []EntryReport{r.Entries[1]}, r.WithOutcome(OutcomeSkipped))
+	assert.EqualError(t, r.Err(), "boom")
+
+	var nilReport *MaterializationReport
+	assert.NoError(t, nilReport.Err())
+	assert.Zero(t, nilReport.TotalBytes())
+}
@@ -0,0 +1,290 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SecretPolicy selects what persisting does with secrets found by a SecretScanner.
+type SecretPolicy string
+
+const (
+	// SecretPolicyWarn logs each finding and writes the content unchanged.
+	// It is the default when PersistOptions.SecretPolicy is empty.
+	SecretPolicyWarn SecretPolicy = "warn"
+	// SecretPolicyRedact replaces each finding with "[REDACTED:<rule>]" before writing.
+	SecretPolicyRedact SecretPolicy = "redact"
+	// SecretPolicyFail aborts persisting with a *SecretsFoundError before
+	// anything is written.
+	SecretPolicyFail SecretPolicy = "fail"
+)
+
+// SecretFinding is a secret detected in the content of a file entry.
+type SecretFinding struct {
+	// Path is the root-relative, slash-separated path of the file.
+	Path string
+	// Line is the 1-based line of the start of the secret.
+	Line int
+	// Rule names the rule that matched, e.g. "aws-access-key-id".
+	Rule string
+	// Start and End are the byte offsets of the secret in the file content.
+	// They select the text replaced by SecretPolicyRedact.
+	Start, End int
+	// Preview is a masked form of the secret that is safe to log.
+	Preview string
+}
+
+func (f SecretFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", f.Path, f.Line, f.Rule, f.Preview)
+}
+
+// SecretScanner detects secrets in file content before it is persisted.
+type SecretScanner interface {
+	// Scan returns the secrets found in content of the file at path. Binary
+	// files (see IsBinaryContent) are not scanned.
+	Scan(path, content string) []SecretFinding
+}
+
+// SecretsFoundError is returned by persisting with SecretPolicyFail when the
+// scanner reports any finding.
+type SecretsFoundError struct {
+	Findings []SecretFinding
+}
+
+func (e *SecretsFoundError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "found %d potential secret(s) in materialized files:", len(e.Findings))
+	for _, f := range e.Findings {
+		b.WriteString("\n  ")
+		b.WriteString(f.String())
+	}
+	return b.String()
+}
+
+// SecretRule is a named pattern of PatternSecretScanner. When Pattern has a
+// capture group, the first group is the secret; otherwise the whole match is.
+type SecretRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// PatternSecretScanner finds secrets with regular expressions and, when
+// MinEntropy is positive, flags other high-entropy tokens.
+type PatternSecretScanner struct {
+	Rules []SecretRule
+
+	// MinEntropy is the Shannon entropy in bits per character from which a
+	// token of mixed-case letters and digits is reported as "high-entropy".
+	// Zero disables entropy detection.
+	MinEntropy float64
+	// MinEntropyLength is the minimum length of tokens checked for entropy.
+	MinEntropyLength int
+}
+
+// DefaultSecretRules detect AWS keys, GitHub tokens, Slack tokens and
+// webhooks, and PEM private keys.
+var DefaultSecretRules = []SecretRule{
+	{Name: "aws-access-key-id", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)},
+	{Name: "aws-secret-access-key", Pattern: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+]{40})\b`)},
+	{Name: "github-token", Pattern: regexp.MustCompile(`\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,255}\b`)},
+	{Name: "github-token", Pattern: regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,255}\b`)},
+	{Name: "slack-token", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{Name: "slack-webhook", Pattern: regexp.MustCompile(`https://hooks\.slack\.com/services/T[A-Za-z0-9_]+/B[A-Za-z0-9_]+/[A-Za-z0-9_]+`)},
+	{Name: "private-key", Pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY-----(?:[\s\S]*?-----END (?:[A-Z0-9]+ )*PRIVATE KEY-----)?`)},
+}
+
+// DefaultSecretScanner returns a scanner with DefaultSecretRules and entropy
+// detection of tokens of at least 24 characters.
+func DefaultSecretScanner() *PatternSecretScanner {
+	return &PatternSecretScanner{
+		Rules:            DefaultSecretRules,
+		MinEntropy:       4.2,
+		MinEntropyLength: 24,
+	}
+}
+
+// entropyCandidate matches tokens checked for entropy.
+var entropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/_\-]+=*`)
+
+// Scan implements SecretScanner.
+func (s *PatternSecretScanner) Scan(path, content string) []SecretFinding {
+	var findings []SecretFinding
+	add := func(rule string, start, end int) {
+		for _, f := range findings {
+			if start < f.End && f.Start < end {
+				return
+			}
+		}
+		findings = append(findings, SecretFinding{
+			Path:    path,
+			Line:    strings.Count(content[:start], "\n") + 1,
+			Rule:    rule,
+			Start:   start,
+			End:     end,
+			Preview: maskSecret(content[start:end]),
+		})
+	}
+
+	for _, r := range s.Rules {
+		for _, m := range r.Pattern.FindAllStringSubmatchIndex(content, -1) {
+			start, end := m[0], m[1]
+			if len(m) >= 4 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			add(r.Name, start, end)
+		}
+	}
+
+	if s.MinEntropy > 0 {
+		minLen := max(s.MinEntropyLength, 1)
+		for _, m := range entropyCandidate.FindAllStringIndex(content, -1) {
+			token := content[m[0]:m[1]]
+			if len(token) < minLen || !hasMixedCharClasses(token) {
+				continue
+			}
+			if shannonEntropy(token) >= s.MinEntropy {
+				add("high-entropy", m[0], m[1])
+			}
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Start < findings[j].Start })
+	return findings
+}
+
+// hasMixedCharClasses reports whether s contains upper-case letters,
+// lower-case letters and digits, which words and hex digests rarely all do.
+func hasMixedCharClasses(s string) bool {
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := map[rune]int{}
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// maskSecret keeps the first four characters of secret and masks the rest.
+func maskSecret(secret string) string {
+	first, _, _ := strings.Cut(secret, "\n")
+	if len(first) <= 8 {
+		return strings.Repeat("*", len(first))
+	}
+	return first[:4] + strings.Repeat("*", 8)
+}
+
+// ScanSecrets runs opts.SecretScanner over the content of the file at path,
+// for content written outside PersistMaterializedResultWithOptions. It returns
+// the content to write, redacted under SecretPolicyRedact, and fails with a
+// *SecretsFoundError under SecretPolicyFail. Binary content is not scanned.
+func ScanSecrets(path, content string, opts PersistOptions) (string, []SecretFinding, error) {
+	if opts.SecretScanner == nil {
+		return content, nil, nil
+	}
+	if err := validateSecretPolicy(opts.SecretPolicy); err != nil {
+		return content, nil, err
+	}
+	content, findings := applySecretPolicy(path, content, opts)
+	if opts.SecretPolicy == SecretPolicyFail && len(findings) > 0 {
+		return content, findings, &SecretsFoundError{Findings: findings}
+	}
+	return content, findings, nil
+}
+
+// scanSecrets runs opts.SecretScanner over the file entries and applies
+// opts.SecretPolicy: redacting rewrites the content of the entries in place.
+func scanSecrets(entries []resolvedEntry, opts PersistOptions) ([]SecretFinding, error) {
+	if opts.SecretScanner == nil {
+		return nil, nil
+	}
+	if err := validateSecretPolicy(opts.SecretPolicy); err != nil {
+		return nil, err
+	}
+	var all []SecretFinding
+	for i := range entries {
+		e := &entries[i]
+		if e.dir {
+			continue
+		}
+		var findings []SecretFinding
+		e.content, findings = applySecretPolicy(e.rel, e.content, opts)
+		all = append(all, findings...)
+	}
+	if opts.SecretPolicy == SecretPolicyFail && len(all) > 0 {
+		return all, &SecretsFoundError{Findings: all}
+	}
+	return all, nil
+}
+
+func validateSecretPolicy(policy SecretPolicy) error {
+	switch policy {
+	case "", SecretPolicyWarn, SecretPolicyRedact, SecretPolicyFail:
+		return nil
+	default:
+		return fmt.Errorf("unknown secret policy %q", policy)
+	}
+}
+
+// applySecretPolicy scans one file and returns its content, redacted or
+// unchanged, with the findings. Warnings are logged here; failing is left to
+// the caller so every finding can be reported at once.
+func applySecretPolicy(path, content string, opts PersistOptions) (string, []SecretFinding) {
+	if IsBinaryContent(content) {
+		return content, nil
+	}
+	findings := opts.SecretScanner.Scan(path, content)
+	switch {
+	case len(findings) == 0:
+	case opts.SecretPolicy == SecretPolicyRedact:
+		content = redactSecrets(content, findings)
+	case opts.SecretPolicy == SecretPolicyWarn || opts.SecretPolicy == "":
+		for _, f := range findings {
+			slog.Warn("Potential secret in materialized file", "path", f.Path, "line", f.Line, "rule", f.Rule, "preview", f.Preview)
+		}
+	}
+	return content, findings
+}
+
+// redactSecrets replaces the findings in content with "[REDACTED:<rule>]".
+// Findings overlapping an earlier one are skipped.
+func redactSecrets(content string, findings []SecretFinding) string {
+	sorted := append([]SecretFinding(nil), findings...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	var b strings.Builder
+	pos := 0
+	for _, f := range sorted {
+		if f.Start < pos || f.End > len(content) || f.Start >= f.End {
+			continue
+		}
+		b.WriteString(content[pos:f.Start])
+		b.WriteString("[REDACTED:" + f.Rule + "]")
+		pos = f.End
+	}
+	b.WriteString(content[pos:])
+	return b.String()
+}
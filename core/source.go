@@ -0,0 +1,20 @@
+package core
+
+// SourceKind identifies the type of a context entry source.
+type SourceKind string
+
+const (
+	SourceText         SourceKind = "text"
+	SourceCmd          SourceKind = "cmd"
+	SourceGithub       SourceKind = "github"
+	SourceCombined     SourceKind = "combined"
+	SourcePrefetchID   SourceKind = "prefetch_id"
+	SourceUserInput    SourceKind = "user_input"
+	SourceLocalFile    SourceKind = "local_file"
+	SourceGitRepo      SourceKind = "git_repo"
+	SourceJiraIssues   SourceKind = "jira_issues"
+	SourceLinearIssues SourceKind = "linear_issues"
+	SourceGitHistory   SourceKind = "git_history"
+	SourceUrlFetch     SourceKind = "url_fetch"
+	SourceUnknown      SourceKind = "unknown"
+)
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"google.golang.org/protobuf/proto"
+)
+
+// TemplateData is the data model available to templates in recipe text
+// sources, command arguments and start prompts when
+// GenerationContext.RenderTemplates is set:
+//
+//	{{.UserInput.ticket}}    user-provided value for parameter "ticket"
+//	{{.Prefetched.plan}}     data of the prefetched entry with id "plan"; empty in prefetch commands
+//	{{.Env.HOME}}            environment variable, EnvOverrides taking precedence
+//	{{.IDE}}                 IDE the recipe is generated for
+//	{{.WorkspacePath}}       resolved workspace root
+//
+// Referencing a missing key is an error rather than an empty string.
+type TemplateData struct {
+	UserInput     map[string]string
+	Prefetched    map[string]string
+	Env           map[string]string
+	IDE           string
+	WorkspacePath string
+}
+
+// TemplateData builds the template data model from the generation context.
+func (g *GenerationContext) TemplateData() TemplateData {
+	data := TemplateData{
+		UserInput:  map[string]string{},
+		Prefetched: map[string]string{},
+		Env:        map[string]string{},
+	}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			data.Env[k] = v
+		}
+	}
+	if g == nil {
+		return data
+	}
+	for k, v := range g.EnvOverrides {
+		data.Env[k] = v
+	}
+	for k, v := range g.UserInput {
+		data.UserInput[k] = v
+	}
+	for id, d := range g.Prefetched {
+		data.Prefetched[id] = d.GetData()
+	}
+	data.IDE = g.IDE
+	data.WorkspacePath = g.WorkspacePath
+	return data
+}
+
+// RenderTemplate executes text as a Go template against TemplateData. The name
+// identifies the field in error messages. Text is returned unchanged when
+// template rendering is disabled or it contains no actions.
+func (g *GenerationContext) RenderTemplate(name, text string) (string, error) {
+	if g == nil || !g.RenderTemplates || !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, g.TemplateData()); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// RenderExec returns a copy of e with every argument rendered by RenderTemplate.
+// The command itself is never templated. e is returned as is when rendering is disabled.
+func (g *GenerationContext) RenderExec(name string, e *osdd.Exec) (*osdd.Exec, error) {
+	if e == nil || g == nil || !g.RenderTemplates {
+		return e, nil
+	}
+	args := make([]string, len(e.GetArgs()))
+	for i, arg := range e.GetArgs() {
+		rendered, err := g.RenderTemplate(fmt.Sprintf("%s.args[%d]", name, i), arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = rendered
+	}
+	rendered := proto.CloneOf(e)
+	rendered.SetArgs(args)
+	return rendered, nil
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerationContext_RenderTemplate(t *testing.T) {
+	t.Parallel()
+	genCtx := &GenerationContext{
+		RenderTemplates: true,
+		UserInput:       map[string]string{"ticket": "ABC-1"},
+		Prefetched: map[string]*osdd.FetchedData{
+			"plan": osdd.FetchedData_builder{Id: "plan", Data: "plan-42"}.Build(),
+		},
+		EnvOverrides:  map[string]string{"OSDD_TEMPLATE_TEST": "from-override"},
+		IDE:           "claude",
+		WorkspacePath: "/work",
+	}
+
+	tests := []struct {
+		name       string
+		text       string
+		want       string
+		wantErrSub string
+	}{
+		{name: "user input", text: "Implement {{.UserInput.ticket}}", want: "Implement ABC-1"},
+		{name: "prefetched", text: "{{.Prefetched.plan}}", want: "plan-42"},
+		{name: "env override", text: "{{.Env.OSDD_TEMPLATE_TEST}}", want: "from-override"},
+		{name: "ide and workspace", text: "{{.IDE}}@{{.WorkspacePath}}", want: "claude@/work"},
+		{name: "plain text untouched", text: "no actions here", want: "no actions here"},
+		{name: "missing user input", text: "{{.UserInput.missing}}", wantErrSub: `failed to render template t: template: t:1:12: executing "t" at <.UserInput.missing>: map has no entry for key "missing"`},
+		{name: "unknown field", text: "{{.Nope}}", wantErrSub: "can't evaluate field Nope"},
+		{name: "parse error", text: "{{.UserInput.ticket", wantErrSub: "failed to parse template t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := genCtx.RenderTemplate("t", tt.text)
+			if tt.wantErrSub != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErrSub)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGenerationContext_RenderTemplate_Disabled(t *testing.T) {
+	t.Parallel()
+	got, err := (&GenerationContext{}).RenderTemplate("t", "{{.UserInput.missing}}")
+	require.NoError(t, err)
+	assert.Equal(t, "{{.UserInput.missing}}", got)
+}
+
+func TestGenerationContext_RenderExec(t *testing.T) {
+	t.Parallel()
+	genCtx := &GenerationContext{RenderTemplates: true, UserInput: map[string]string{"id": "7"}}
+	orig := osdd.Exec_builder{Cmd: "devplan", Args: []string{"fetch", "{{.UserInput.id}}"}}.Build()
+
+	got, err := genCtx.RenderExec("cmd", orig)
+	require.NoError(t, err)
+	assert.Equal(t, "devplan", got.GetCmd())
+	assert.Equal(t, []string{"fetch", "7"}, got.GetArgs())
+	assert.Equal(t, []string{"fetch", "{{.UserInput.id}}"}, orig.GetArgs(), "original must not be mutated")
+
+	_, err = genCtx.RenderExec("cmd", osdd.Exec_builder{Cmd: "x", Args: []string{"{{.UserInput.other}}"}}.Build())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cmd.args[0]")
+}
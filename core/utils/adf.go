@@ -0,0 +1,348 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// adfNode is a node of an Atlassian Document Format document, the rich text
+// format of Jira Cloud descriptions and comments.
+type adfNode struct {
+	Type    string         `json:"type"`
+	Text    string         `json:"text"`
+	Attrs   map[string]any `json:"attrs"`
+	Marks   []adfMark      `json:"marks"`
+	Content []adfNode      `json:"content"`
+}
+
+type adfMark struct {
+	Type  string         `json:"type"`
+	Attrs map[string]any `json:"attrs"`
+}
+
+// ADFToMarkdown converts an Atlassian Document Format document to Markdown.
+// A JSON string (the plain-text format of older Jira APIs) is returned as-is
+// and null or empty input yields "". Unknown nodes are rendered through their
+// children, so new node types degrade to their text.
+func ADFToMarkdown(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", fmt.Errorf("failed to parse text: %w", err)
+		}
+		return s, nil
+	}
+	var doc adfNode
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return "", fmt.Errorf("failed to parse ADF document: %w", err)
+	}
+	return renderADFBlock(doc), nil
+}
+
+// renderADFBlocks renders block nodes separated by sep, skipping empty ones.
+func renderADFBlocks(nodes []adfNode, sep string) string {
+	var blocks []string
+	for _, n := range nodes {
+		if s := renderADFBlock(n); s != "" {
+			blocks = append(blocks, s)
+		}
+	}
+	return strings.Join(blocks, sep)
+}
+
+func renderADFBlock(n adfNode) string {
+	switch n.Type {
+	case "doc":
+		return renderADFBlocks(n.Content, "\n\n")
+	case "paragraph":
+		return strings.TrimSpace(renderADFInline(n.Content))
+	case "heading":
+		level := min(max(adfAttrInt(n.Attrs, "level", 1), 1), 6)
+		return strings.Repeat("#", level) + " " + strings.TrimSpace(renderADFInline(n.Content))
+	case "bulletList", "decisionList":
+		return renderADFList(n.Content, func(int, adfNode) string { return "- " })
+	case "orderedList":
+		start := adfAttrInt(n.Attrs, "order", 1)
+		return renderADFList(n.Content, func(i int, _ adfNode) string { return strconv.Itoa(start+i) + ". " })
+	case "taskList":
+		return renderADFList(n.Content, func(_ int, item adfNode) string {
+			if adfAttrString(item.Attrs, "state") == "DONE" {
+				return "- [x] "
+			}
+			return "- [ ] "
+		})
+	case "codeBlock":
+		return strings.TrimSuffix(codeFence(adfText(n.Content), adfAttrString(n.Attrs, "language")), "\n")
+	case "blockquote":
+		return adfQuote(renderADFBlocks(n.Content, "\n\n"))
+	case "rule":
+		return "---"
+	case "panel":
+		label := adfAttrString(n.Attrs, "panelType")
+		if label == "" {
+			label = "info"
+		}
+		label = strings.ToUpper(label[:1]) + label[1:]
+		return adfQuote("**" + label + ":**\n\n" + renderADFBlocks(n.Content, "\n\n"))
+	case "expand", "nestedExpand":
+		body := renderADFBlocks(n.Content, "\n\n")
+		if title := adfAttrString(n.Attrs, "title"); title != "" {
+			return "**" + title + "**\n\n" + body
+		}
+		return body
+	case "table":
+		return renderADFTable(n)
+	case "mediaSingle", "mediaGroup":
+		return renderADFBlocks(n.Content, "\n")
+	case "media":
+		return adfMediaPlaceholder(n)
+	case "blockCard", "embedCard":
+		if u := adfAttrString(n.Attrs, "url"); u != "" {
+			return "<" + u + ">"
+		}
+		return ""
+	case "text", "hardBreak", "mention", "emoji", "inlineCard", "date", "status", "mediaInline":
+		return renderADFInline([]adfNode{n})
+	default:
+		if len(n.Content) == 0 {
+			return n.Text
+		}
+		return renderADFBlocks(n.Content, "\n\n")
+	}
+}
+
+// renderADFList renders list items, indenting continuation lines so nested
+// blocks stay inside their item.
+func renderADFList(items []adfNode, marker func(int, adfNode) string) string {
+	lines := make([]string, 0, len(items))
+	for i, item := range items {
+		m := marker(i, item)
+		var body string
+		if item.Type == "taskItem" || item.Type == "decisionItem" {
+			// Task and decision items hold inline content directly.
+			body = strings.TrimSpace(renderADFInline(item.Content))
+		} else {
+			body = renderADFBlocks(item.Content, "\n")
+		}
+		lines = append(lines, m+adfIndent(body, len(m)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderADFTable(n adfNode) string {
+	var rows [][]string
+	width := 0
+	for _, row := range n.Content {
+		var cells []string
+		for _, cell := range row.Content {
+			text := renderADFBlocks(cell.Content, "\n")
+			text = strings.ReplaceAll(text, "|", `\|`)
+			text = strings.ReplaceAll(text, "\n", "<br>")
+			cells = append(cells, text)
+		}
+		width = max(width, len(cells))
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 || width == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for i := 0; i < width; i++ {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+	// Markdown tables need a header row; the first row serves as one.
+	writeRow(rows[0])
+	b.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func renderADFInline(nodes []adfNode) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		switch n.Type {
+		case "text":
+			b.WriteString(applyADFMarks(n.Text, n.Marks))
+		case "hardBreak":
+			b.WriteString("\n")
+		case "mention":
+			name := adfAttrString(n.Attrs, "text")
+			if name == "" {
+				name = adfAttrString(n.Attrs, "id")
+			}
+			if !strings.HasPrefix(name, "@") {
+				name = "@" + name
+			}
+			b.WriteString(name)
+		case "emoji":
+			if t := adfAttrString(n.Attrs, "text"); t != "" {
+				b.WriteString(t)
+			} else {
+				b.WriteString(adfAttrString(n.Attrs, "shortName"))
+			}
+		case "inlineCard":
+			if u := adfAttrString(n.Attrs, "url"); u != "" {
+				b.WriteString("<" + u + ">")
+			}
+		case "date":
+			b.WriteString(adfDate(adfAttrString(n.Attrs, "timestamp")))
+		case "status":
+			b.WriteString("[" + adfAttrString(n.Attrs, "text") + "]")
+		case "media", "mediaInline":
+			b.WriteString(adfMediaPlaceholder(n))
+		default:
+			if len(n.Content) > 0 {
+				b.WriteString(renderADFInline(n.Content))
+			} else {
+				b.WriteString(n.Text)
+			}
+		}
+	}
+	return b.String()
+}
+
+// applyADFMarks wraps text in the Markdown syntax of its marks. Code marks
+// exclude the other formatting marks; links wrap everything.
+func applyADFMarks(text string, marks []adfMark) string {
+	if text == "" {
+		return ""
+	}
+	var href string
+	code := false
+	for _, m := range marks {
+		switch m.Type {
+		case "code":
+			code = true
+		case "link":
+			href = adfAttrString(m.Attrs, "href")
+		}
+	}
+	if code {
+		text = adfWrap(text, "`")
+	} else {
+		for _, m := range marks {
+			switch m.Type {
+			case "strong":
+				text = adfWrap(text, "**")
+			case "em":
+				text = adfWrap(text, "_")
+			case "strike":
+				text = adfWrap(text, "~~")
+			}
+		}
+	}
+	if href != "" {
+		text = "[" + text + "](" + href + ")"
+	}
+	return text
+}
+
+// adfWrap surrounds text with delim, keeping surrounding whitespace outside
+// the delimiters where Markdown requires it.
+func adfWrap(text, delim string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := strings.Index(text, trimmed)
+	return text[:start] + delim + trimmed + delim + text[start+len(trimmed):]
+}
+
+func adfMediaPlaceholder(n adfNode) string {
+	if adfAttrString(n.Attrs, "type") == "external" {
+		if u := adfAttrString(n.Attrs, "url"); u != "" {
+			return "![" + adfAttrString(n.Attrs, "alt") + "](" + u + ")"
+		}
+	}
+	name := adfAttrString(n.Attrs, "alt")
+	if name == "" {
+		name = adfAttrString(n.Attrs, "id")
+	}
+	if name == "" {
+		return "[attachment]"
+	}
+	return "[attachment: " + name + "]"
+}
+
+// adfText concatenates the text of nodes, e.g. the lines of a code block.
+func adfText(nodes []adfNode) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		if n.Type == "hardBreak" {
+			b.WriteString("\n")
+		}
+		b.WriteString(n.Text)
+		b.WriteString(adfText(n.Content))
+	}
+	return b.String()
+}
+
+func adfQuote(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// adfIndent indents all lines but the first by n spaces.
+func adfIndent(s string, n int) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = pad + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// adfDate formats the millisecond Unix timestamp of a date node.
+func adfDate(ts string) string {
+	ms, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ts
+	}
+	return time.UnixMilli(ms).UTC().Format("2006-01-02")
+}
+
+func adfAttrString(attrs map[string]any, key string) string {
+	switch v := attrs[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+func adfAttrInt(attrs map[string]any, key string, def int) int {
+	if v, ok := attrs[key].(float64); ok {
+		return int(v)
+	}
+	return def
+}
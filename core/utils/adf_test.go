@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestADFToMarkdown(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		adf  string
+		want string
+	}{
+		{
+			name: "null",
+			adf:  `null`,
+			want: "",
+		},
+		{
+			name: "plain string",
+			adf:  `"plain text"`,
+			want: "plain text",
+		},
+		{
+			name: "paragraphs and heading",
+			adf: `{"type":"doc","content":[
+				{"type":"heading","attrs":{"level":2},"content":[{"type":"text","text":"Title"}]},
+				{"type":"paragraph","content":[{"type":"text","text":"line one"},{"type":"hardBreak"},{"type":"text","text":"line two"}]}
+			]}`,
+			want: "## Title\n\nline one\nline two",
+		},
+		{
+			name: "marks",
+			adf: `{"type":"doc","content":[{"type":"paragraph","content":[
+				{"type":"text","text":"bold ","marks":[{"type":"strong"}]},
+				{"type":"text","text":"italic","marks":[{"type":"em"}]},
+				{"type":"text","text":" "},
+				{"type":"text","text":"x := 1","marks":[{"type":"code"},{"type":"strong"}]},
+				{"type":"text","text":" "},
+				{"type":"text","text":"docs","marks":[{"type":"link","attrs":{"href":"https://example.com"}}]},
+				{"type":"text","text":" "},
+				{"type":"text","text":"gone","marks":[{"type":"strike"}]}
+			]}]}`,
+			want: "**bold** _italic_ `x := 1` [docs](https://example.com) ~~gone~~",
+		},
+		{
+			name: "nested lists",
+			adf: `{"type":"doc","content":[{"type":"bulletList","content":[
+				{"type":"listItem","content":[
+					{"type":"paragraph","content":[{"type":"text","text":"one"}]},
+					{"type":"orderedList","attrs":{"order":3},"content":[
+						{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"three"}]}]},
+						{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"four"}]}]}
+					]}
+				]},
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"two"}]}]}
+			]}]}`,
+			want: "- one\n  3. three\n  4. four\n- two",
+		},
+		{
+			name: "task list",
+			adf: `{"type":"doc","content":[{"type":"taskList","content":[
+				{"type":"taskItem","attrs":{"state":"DONE"},"content":[{"type":"text","text":"done"}]},
+				{"type":"taskItem","attrs":{"state":"TODO"},"content":[{"type":"text","text":"todo"}]}
+			]}]}`,
+			want: "- [x] done\n- [ ] todo",
+		},
+		{
+			name: "table",
+			adf: `{"type":"doc","content":[{"type":"table","content":[
+				{"type":"tableRow","content":[
+					{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Name"}]}]},
+					{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Value"}]}]}
+				]},
+				{"type":"tableRow","content":[
+					{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"a|b"}]}]},
+					{"type":"tableCell","content":[
+						{"type":"paragraph","content":[{"type":"text","text":"1"}]},
+						{"type":"paragraph","content":[{"type":"text","text":"2"}]}
+					]}
+				]}
+			]}]}`,
+			want: "| Name | Value |\n| --- | --- |\n| a\\|b | 1<br>2 |",
+		},
+		{
+			name: "code block",
+			adf:  `{"type":"doc","content":[{"type":"codeBlock","attrs":{"language":"go"},"content":[{"type":"text","text":"fmt.Println(1)"}]}]}`,
+			want: "```go\nfmt.Println(1)\n```",
+		},
+		{
+			name: "mention, emoji, date, status and inline card",
+			adf: `{"type":"doc","content":[{"type":"paragraph","content":[
+				{"type":"mention","attrs":{"id":"123","text":"@Alice"}},
+				{"type":"text","text":" "},
+				{"type":"mention","attrs":{"id":"456","text":"Bob"}},
+				{"type":"text","text":" "},
+				{"type":"emoji","attrs":{"shortName":":smile:","text":"😄"}},
+				{"type":"text","text":" "},
+				{"type":"date","attrs":{"timestamp":"1750032000000"}},
+				{"type":"text","text":" "},
+				{"type":"status","attrs":{"text":"IN PROGRESS","color":"blue"}},
+				{"type":"text","text":" "},
+				{"type":"inlineCard","attrs":{"url":"https://example.atlassian.net/browse/TES-2"}}
+			]}]}`,
+			want: "@Alice @Bob 😄 2025-06-16 [IN PROGRESS] <https://example.atlassian.net/browse/TES-2>",
+		},
+		{
+			name: "panel and blockquote",
+			adf: `{"type":"doc","content":[
+				{"type":"panel","attrs":{"panelType":"warning"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Careful"}]}]},
+				{"type":"blockquote","content":[{"type":"paragraph","content":[{"type":"text","text":"Quoted"}]}]},
+				{"type":"rule"}
+			]}`,
+			want: "> **Warning:**\n>\n> Careful\n\n> Quoted\n\n---",
+		},
+		{
+			name: "media placeholders",
+			adf: `{"type":"doc","content":[
+				{"type":"mediaSingle","content":[{"type":"media","attrs":{"type":"file","id":"abc","alt":"screenshot.png"}}]},
+				{"type":"mediaGroup","content":[{"type":"media","attrs":{"type":"file","id":"def"}}]},
+				{"type":"mediaSingle","content":[{"type":"media","attrs":{"type":"external","url":"https://example.com/a.png"}}]}
+			]}`,
+			want: "[attachment: screenshot.png]\n\n[attachment: def]\n\n![](https://example.com/a.png)",
+		},
+		{
+			name: "unknown nodes fall back to their text",
+			adf:  `{"type":"doc","content":[{"type":"layoutSection","content":[{"type":"layoutColumn","content":[{"type":"paragraph","content":[{"type":"text","text":"inside"}]}]}]}]}`,
+			want: "inside",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ADFToMarkdown(json.RawMessage(tt.adf))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestADFToMarkdown_InvalidJSON(t *testing.T) {
+	t.Parallel()
+	_, err := ADFToMarkdown(json.RawMessage(`{"type":`))
+	assert.ErrorContains(t, err, "failed to parse ADF document")
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// forEachConcurrent runs fn for each item, at most n at a time, and returns
+// once all calls have. fn receives a pointer into items, so it can fill in the
+// item in place.
+func forEachConcurrent[T any](ctx context.Context, n int, items []T, fn func(context.Context, *T)) {
+	if len(items) == 0 {
+		return
+	}
+	sem := make(chan struct{}, max(n, 1))
+	var wg sync.WaitGroup
+	for i := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(item *T) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(ctx, item)
+		}(&items[i])
+	}
+	wg.Wait()
+}
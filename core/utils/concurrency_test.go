@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachConcurrent(t *testing.T) {
+	t.Parallel()
+	items := make([]int, 20)
+	var running, peak atomic.Int32
+	forEachConcurrent(context.Background(), 3, items, func(_ context.Context, item *int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		*item = 1
+		running.Add(-1)
+	})
+	for i, item := range items {
+		assert.Equal(t, 1, item, "item %d", i)
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+
+	forEachConcurrent(context.Background(), 3, []int(nil), func(context.Context, *int) {
+		t.Error("fn called for empty items")
+	})
+}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	neturl "net/url"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/opensdd/osdd-api/clients/go/osdd"
@@ -13,6 +18,8 @@ import (
 // BuildGitCloneURL constructs an HTTPS clone URL for the given GitRepository.
 // It maps Provider to the appropriate host, and optionally embeds the given
 // auth token. If token is empty the URL is returned without authentication.
+// Self-hosted GitLab, Gitea and Forgejo instances are selected with a
+// "<provider>:<host>" provider, e.g. "forgejo:git.example.com".
 func BuildGitCloneURL(repo *osdd.GitRepository, token string) (string, error) {
 	if repo == nil {
 		return "", fmt.Errorf("git repository cannot be nil")
@@ -22,18 +29,31 @@ func BuildGitCloneURL(repo *osdd.GitRepository, token string) (string, error) {
 		return "", fmt.Errorf("git repository full name cannot be empty")
 	}
 
-	provider := strings.ToLower(strings.TrimSpace(repo.GetProvider()))
+	provider, customHost, err := ParseGitProvider(repo.GetProvider())
+	if err != nil {
+		return "", err
+	}
 
 	var host, tokenUser string
 	switch provider {
-	case "", "github":
+	case "github":
 		host = "github.com"
 		tokenUser = "x-access-token"
 	case "bitbucket":
 		host = "bitbucket.org"
 		tokenUser = "x-token-auth"
-	default:
-		return "", fmt.Errorf("unsupported git provider: %s", provider)
+	case "gitlab":
+		host = gitlabDefaultHost
+		tokenUser = "oauth2"
+	case "gitea":
+		host = giteaDefaultHost
+		tokenUser = "oauth2"
+	case "forgejo":
+		host = forgejoDefaultHost
+		tokenUser = "oauth2"
+	}
+	if customHost != "" {
+		host = customHost
 	}
 
 	if token != "" {
@@ -43,9 +63,119 @@ func BuildGitCloneURL(repo *osdd.GitRepository, token string) (string, error) {
 	return fmt.Sprintf("https://%s/%s.git", host, fullName), nil
 }
 
+// ParseGitProvider normalizes a GitRepository provider into its name and an
+// optional host. An empty provider means "github". Providers that support
+// self-hosted instances accept a "<name>:<host>" form, e.g.
+// "gitlab:gitlab.example.com".
+func ParseGitProvider(provider string) (name, host string, err error) {
+	name, host, _ = strings.Cut(strings.ToLower(strings.TrimSpace(provider)), ":")
+	switch name {
+	case "", "github":
+		name = "github"
+	case "bitbucket":
+	case "gitlab", "gitea", "forgejo":
+		return name, host, nil
+	default:
+		return "", "", fmt.Errorf("unsupported git provider: %s", provider)
+	}
+	if host != "" {
+		return "", "", fmt.Errorf("git provider %s does not support a custom host", name)
+	}
+	return name, "", nil
+}
+
+// GitCheckoutOptions controls how CloneGitRepoWithOptions checks out a repository.
+type GitCheckoutOptions struct {
+	// Ref is the branch, tag or commit SHA to check out. Empty uses the default
+	// branch. Branches and tags take precedence over commits with the same name.
+	// Commits that must be fetched, because a shallow or single-branch clone
+	// lacks them, need the full 40-character SHA.
+	Ref string
+	// Depth creates a shallow clone truncated to that many commits. Zero clones
+	// full history. Updates keep the depth of the existing checkout: full
+	// checkouts stay full.
+	Depth int
+	// SparsePaths limits the working tree to these directories (cone-mode sparse
+	// checkout). Updating an existing sparse checkout without SparsePaths
+	// restores the full working tree.
+	SparsePaths []string
+	// Submodules initializes and updates submodules recursively after checkout.
+	Submodules bool
+	// SingleBranch fetches only the history of the branch being checked out.
+	SingleBranch bool
+	// Update decides how an existing checkout of the same remote is brought to
+	// Ref. Empty means GitUpdateFastForward.
+	Update GitUpdatePolicy
+}
+
+// GitUpdatePolicy decides how an existing checkout is updated.
+type GitUpdatePolicy string
+
+const (
+	// GitUpdateFastForward fast-forwards the checked-out branch, or switches a
+	// clean checkout to the requested tag or commit. Diverged branches are an error.
+	GitUpdateFastForward GitUpdatePolicy = "fast-forward"
+	// GitUpdateReset hard-resets the checkout to the fetched ref.
+	GitUpdateReset GitUpdatePolicy = "reset"
+)
+
+// GitDirtyError is returned when an existing checkout has uncommitted changes
+// to tracked files; the checkout is left untouched.
+type GitDirtyError struct {
+	Path string
+	// Changes lists the changed files in `git status --porcelain` format.
+	Changes []string
+}
+
+func (e *GitDirtyError) Error() string {
+	return fmt.Sprintf("existing checkout at %s has uncommitted changes, refusing to update:\n%s", e.Path, strings.Join(e.Changes, "\n"))
+}
+
+// commitSHAPattern matches abbreviated and full commit SHAs.
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// isAbbreviatedSHA reports whether ref looks like a commit SHA shorter than
+// the full 40 characters.
+func isAbbreviatedSHA(ref string) bool {
+	return len(ref) < 40 && commitSHAPattern.MatchString(ref)
+}
+
+func abbreviatedSHAError(sha string) error {
+	return fmt.Errorf("commit %s is not in the checkout and abbreviated SHAs cannot be fetched: use the full 40-character SHA", sha)
+}
+
+// isCommitRef reports whether ref names a commit rather than a branch or tag
+// of the remote at url. Branch and tag names such as "20240101" or "deadbeef"
+// look like abbreviated SHAs, so SHA-shaped refs are looked up on the remote.
+func isCommitRef(ctx context.Context, url, ref string) (bool, error) {
+	if !commitSHAPattern.MatchString(ref) {
+		return false, nil
+	}
+	out, err := runGit(ctx, ".", "ls-remote", "--heads", "--tags", "--", url, ref)
+	if err != nil {
+		return false, fmt.Errorf("failed to list remote refs: %w", err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		_, name, _ := strings.Cut(line, "\t")
+		if name == "refs/heads/"+ref || name == "refs/tags/"+ref {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // CloneGitRepo clones the repository described by repo into destPath using the git CLI.
 // The token is embedded in the clone URL when non-empty.
 func CloneGitRepo(ctx context.Context, repo *osdd.GitRepository, destPath string, token string) error {
+	return CloneGitRepoWithOptions(ctx, repo, destPath, token, GitCheckoutOptions{})
+}
+
+// CloneGitRepoWithOptions clones the repository described by repo into destPath
+// and checks out opts.Ref, applying the shallow, sparse, submodule and
+// single-branch options. An existing checkout of the same remote at destPath
+// is updated according to opts.Update instead. The token is embedded in the
+// clone URL when non-empty.
+func CloneGitRepoWithOptions(ctx context.Context, repo *osdd.GitRepository, destPath string, token string, opts GitCheckoutOptions) error {
 	if repo == nil {
 		return fmt.Errorf("git repository cannot be nil")
 	}
@@ -55,14 +185,247 @@ func CloneGitRepo(ctx context.Context, repo *osdd.GitRepository, destPath string
 		return fmt.Errorf("failed to build clone URL: %w", err)
 	}
 
-	slog.Debug("Cloning git repository", "fullName", repo.GetFullName(), "provider", repo.GetProvider(), "dest", destPath)
+	slog.Debug("Cloning git repository", "fullName", repo.GetFullName(), "provider", repo.GetProvider(), "dest", destPath, "ref", opts.Ref)
+
+	return checkoutGitURL(ctx, url, destPath, opts)
+}
+
+// checkoutGitURL clones url into destPath, or updates destPath when it already
+// holds a checkout, so that re-running a recipe in the same workspace works.
+func checkoutGitURL(ctx context.Context, url, destPath string, opts GitCheckoutOptions) error {
+	if isGitCheckout(destPath) {
+		return updateGitCheckout(ctx, url, destPath, opts)
+	}
+	if err := cloneGitURL(ctx, url, destPath, opts); err != nil {
+		return err
+	}
+
+	slog.Debug("Git clone successful", "dest", destPath)
+	return nil
+}
+
+// isGitCheckout reports whether dir is the root of an existing git working tree.
+func isGitCheckout(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// updateGitCheckout brings the existing checkout at dir to opts.Ref according
+// to opts.Update. The checkout must track the same remote as url and have no
+// uncommitted changes to tracked files.
+func updateGitCheckout(ctx context.Context, url, dir string, opts GitCheckoutOptions) error {
+	policy := opts.Update
+	switch policy {
+	case "":
+		policy = GitUpdateFastForward
+	case GitUpdateFastForward, GitUpdateReset:
+	default:
+		return fmt.Errorf("unknown git update policy %q", policy)
+	}
+
+	origin, err := runGit(ctx, dir, "remote", "get-url", "origin")
+	if err != nil {
+		return fmt.Errorf("failed to read remote of existing checkout at %s: %w", dir, err)
+	}
+	if stripURLCredentials(origin) != stripURLCredentials(url) {
+		return fmt.Errorf("existing checkout at %s tracks %s, not %s", dir, stripURLCredentials(origin), stripURLCredentials(url))
+	}
 
-	cmd := exec.CommandContext(ctx, "git", "clone", url, destPath)
-	output, err := cmd.CombinedOutput()
+	status, err := runGit(ctx, dir, "status", "--porcelain", "--untracked-files=no")
 	if err != nil {
+		return fmt.Errorf("failed to check status of existing checkout at %s: %w", dir, err)
+	}
+	if status != "" {
+		return &GitDirtyError{Path: dir, Changes: strings.Split(status, "\n")}
+	}
+
+	// Fast-forward the checked-out branch when it is the requested ref;
+	// otherwise move to the fetched commit.
+	branch, _ := runGit(ctx, dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	fastForward := policy == GitUpdateFastForward && branch != "" && (opts.Ref == "" || opts.Ref == branch)
+
+	// The remote may carry a refreshed token; fetch through the given URL.
+	ref := opts.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	target := "FETCH_HEAD"
+	if isAbbreviatedSHA(ref) {
+		// Servers only fetch full SHAs; an abbreviated one must already be here.
+		isCommit, err := isCommitRef(ctx, url, ref)
+		if err != nil {
+			return err
+		}
+		if isCommit {
+			if _, err := runGit(ctx, dir, "cat-file", "-e", ref+"^{commit}"); err != nil {
+				return abbreviatedSHAError(ref)
+			}
+			target = ref
+		}
+	}
+	if target == "FETCH_HEAD" {
+		// Keep the existing depth: deepening is not requested, and a
+		// depth-limited fetch would turn a full checkout shallow or cut the
+		// ancestry a fast-forward needs.
+		depth := 0
+		if shallow, _ := runGit(ctx, dir, "rev-parse", "--is-shallow-repository"); shallow == "true" && !fastForward {
+			depth = opts.Depth
+		}
+		fetchArgs := []string{"fetch"}
+		if depth > 0 {
+			fetchArgs = append(fetchArgs, "--depth", strconv.Itoa(depth))
+		}
+		fetchArgs = append(fetchArgs, "--", url, ref)
+		if _, err := runGit(ctx, dir, fetchArgs...); err != nil {
+			return fmt.Errorf("git fetch of %s failed: %w", ref, err)
+		}
+	}
+
+	before, _ := runGit(ctx, dir, "rev-parse", "HEAD")
+	if len(opts.SparsePaths) > 0 {
+		setArgs := append([]string{"sparse-checkout", "set", "--"}, opts.SparsePaths...)
+		if _, err := runGit(ctx, dir, setArgs...); err != nil {
+			return fmt.Errorf("git sparse-checkout failed: %w", err)
+		}
+	} else if sparse, _ := runGit(ctx, dir, "config", "--bool", "core.sparseCheckout"); sparse == "true" {
+		// A previous run checked out a subset; restore the full tree.
+		if _, err := runGit(ctx, dir, "sparse-checkout", "disable"); err != nil {
+			return fmt.Errorf("git sparse-checkout disable failed: %w", err)
+		}
+	}
+
+	switch {
+	case policy == GitUpdateReset:
+		if _, err := runGit(ctx, dir, "reset", "--hard", target); err != nil {
+			return fmt.Errorf("git reset failed: %w", err)
+		}
+	case fastForward:
+		if _, err := runGit(ctx, dir, "merge", "--ff-only", target); err != nil {
+			return fmt.Errorf("cannot fast-forward %s in %s (use the reset update policy to discard local commits): %w", branch, dir, err)
+		}
+	default:
+		if _, err := runGit(ctx, dir, "checkout", "--detach", target); err != nil {
+			return fmt.Errorf("git checkout failed: %w", err)
+		}
+	}
+
+	if opts.Submodules {
+		depth := 0
+		if shallow, _ := runGit(ctx, dir, "rev-parse", "--is-shallow-repository"); shallow == "true" {
+			depth = opts.Depth
+		}
+		if err := updateSubmodules(ctx, dir, depth); err != nil {
+			return err
+		}
+	}
+	after, _ := runGit(ctx, dir, "rev-parse", "HEAD")
+	slog.Debug("Updated existing git checkout", "dest", dir, "ref", ref, "policy", policy, "from", before, "to", after)
+	return nil
+}
+
+// stripURLCredentials removes user info (such as embedded tokens) from a URL.
+func stripURLCredentials(raw string) string {
+	u, err := neturl.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	u.User = nil
+	return u.String()
+}
+
+// cloneGitURL clones url into destPath according to opts.
+func cloneGitURL(ctx context.Context, url, destPath string, opts GitCheckoutOptions) error {
+	isCommit, err := isCommitRef(ctx, url, opts.Ref)
+	if err != nil {
+		return err
+	}
+	sparse := len(opts.SparsePaths) > 0
+
+	args := []string{"clone"}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	if opts.SingleBranch {
+		args = append(args, "--single-branch")
+	}
+	if opts.Ref != "" && !isCommit {
+		args = append(args, "--branch", opts.Ref)
+	}
+	if sparse {
+		args = append(args, "--filter=blob:none", "--sparse")
+	}
+	if isCommit {
+		args = append(args, "--no-checkout")
+	}
+	args = append(args, "--", url, destPath)
+
+	if output, err := exec.CommandContext(ctx, "git", args...).CombinedOutput(); err != nil {
 		return fmt.Errorf("git clone failed: %w (output: %s)", err, string(output))
 	}
 
-	slog.Debug("Git clone successful", "dest", destPath)
+	if sparse {
+		setArgs := append([]string{"sparse-checkout", "set", "--"}, opts.SparsePaths...)
+		if _, err := runGit(ctx, destPath, setArgs...); err != nil {
+			return fmt.Errorf("git sparse-checkout failed: %w", err)
+		}
+	}
+	if isCommit {
+		if err := checkoutCommit(ctx, destPath, opts.Ref, opts.Depth); err != nil {
+			return err
+		}
+	}
+	if opts.Submodules {
+		if err := updateSubmodules(ctx, destPath, opts.Depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkoutCommit detaches HEAD at sha, fetching it first when the clone does
+// not contain it (e.g. shallow or single-branch clones).
+func checkoutCommit(ctx context.Context, dir, sha string, depth int) error {
+	if _, err := runGit(ctx, dir, "cat-file", "-e", sha+"^{commit}"); err != nil {
+		if isAbbreviatedSHA(sha) {
+			return abbreviatedSHAError(sha)
+		}
+		fetchArgs := []string{"fetch"}
+		if depth > 0 {
+			fetchArgs = append(fetchArgs, "--depth", strconv.Itoa(depth))
+		}
+		fetchArgs = append(fetchArgs, "origin", sha)
+		if _, err := runGit(ctx, dir, fetchArgs...); err != nil {
+			return fmt.Errorf("git fetch of commit %s failed: %w", sha, err)
+		}
+		sha = "FETCH_HEAD"
+	}
+	if _, err := runGit(ctx, dir, "checkout", "--detach", sha); err != nil {
+		return fmt.Errorf("git checkout failed: %w", err)
+	}
+	return nil
+}
+
+func updateSubmodules(ctx context.Context, dir string, depth int) error {
+	args := []string{"submodule", "update", "--init", "--recursive"}
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+	}
+	if _, err := runGit(ctx, dir, args...); err != nil {
+		return fmt.Errorf("git submodule update failed: %w", err)
+	}
 	return nil
 }
+
+// runGit runs git with args in dir and returns its stdout without trailing
+// whitespace; leading whitespace is significant in porcelain output. Failures
+// include the combined output.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w (output: %s)", args[0], err, strings.TrimSpace(stdout.String()+stderr.String()))
+	}
+	return strings.TrimRight(stdout.String(), " \t\r\n"), nil
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+)
+
+// GitFileTokens holds the per-provider tokens used by FetchGitFile. Empty
+// tokens fetch anonymously.
+type GitFileTokens struct {
+	GitHub string
+	GitLab string
+	// Gitea is used for Gitea and Forgejo hosts.
+	Gitea string
+}
+
+// FetchGitFile fetches a single file referenced by ref from the provider its
+// path belongs to: GitLab (see IsGitLabFileURL) and Gitea or Forgejo (see
+// IsGiteaFileURL) file URLs are read through the API of their host, everything
+// else through FetchGithubWithToken.
+func FetchGitFile(ctx context.Context, ref *osdd.GitReference, tokens GitFileTokens) (string, error) {
+	switch path := ref.GetPath(); {
+	case IsGitLabFileURL(path):
+		return FetchGitLabFile(ctx, ref, tokens.GitLab)
+	case IsGiteaFileURL(path):
+		return FetchGiteaFile(ctx, ref, tokens.Gitea)
+	}
+	return FetchGithubWithToken(ctx, ref, tokens.GitHub)
+}
+
+// ResolveGitFileRevision returns the commit SHA the ref of a file reference
+// points to, so callers can tell whether the file may have changed without
+// fetching it. Branches, tags and GitHub release tags are resolved through the
+// API of the file's provider. An empty SHA is returned for paths that name no
+// repository, such as raw content URLs. Full commit SHAs are returned without
+// a request.
+func ResolveGitFileRevision(ctx context.Context, ref *osdd.GitReference, tokens GitFileTokens) (string, error) {
+	if ref == nil {
+		return "", fmt.Errorf("git reference cannot be nil")
+	}
+	switch path := ref.GetPath(); {
+	case IsGitLabFileURL(path):
+		return resolveGitLabRevision(ctx, ref, tokens.GitLab)
+	case IsGiteaFileURL(path):
+		return resolveGiteaRevision(ctx, ref, tokens.Gitea)
+	}
+	return resolveGithubRevision(ctx, ref, tokens.GitHub)
+}
+
+// fullCommitSHAPattern matches a full, unabbreviated commit SHA.
+var fullCommitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+func isFullCommitSHA(ref string) bool {
+	return fullCommitSHAPattern.MatchString(ref)
+}
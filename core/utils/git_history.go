@@ -13,7 +13,6 @@ import (
 
 	"github.com/opensdd/osdd-api/clients/go/osdd"
 	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
-	"github.com/pkoukk/tiktoken-go"
 )
 
 const (
@@ -125,7 +124,7 @@ func FetchGitHistory(ctx context.Context, src *recipes.GitHistorySource, token s
 			prCh <- prResult{}
 			return
 		}
-		provider := strings.ToLower(strings.TrimSpace(repo.GetProvider()))
+		provider := repo.GetProvider()
 		var dateFilter *osdd.DatesFilter
 		if src.HasDateFilter() {
 			dateFilter = src.GetDateFilter()
@@ -291,11 +290,27 @@ func fetchPRs(ctx context.Context, provider, fullName, token string, dateFilter
 	}
 	owner, repo := parts[0], parts[1]
 
-	switch provider {
-	case "", "github":
+	name, host, err := ParseGitProvider(provider)
+	if err != nil {
+		return prFetchResult{}, fmt.Errorf("unsupported provider for PR fetching: %s", provider)
+	}
+	switch name {
+	case "github":
 		return fetchGitHubPRs(ctx, owner, repo, token, dateFilter, summaryOnly)
 	case "bitbucket":
 		return fetchBitbucketPRs(ctx, owner, repo, token, dateFilter, summaryOnly)
+	case "gitlab":
+		return fetchGitLabMRs(ctx, host, fullName, token, dateFilter, summaryOnly)
+	case "gitea":
+		if host == "" {
+			host = giteaDefaultHost
+		}
+		return fetchGiteaPRs(ctx, host, owner, repo, token, dateFilter, summaryOnly)
+	case "forgejo":
+		if host == "" {
+			host = forgejoDefaultHost
+		}
+		return fetchGiteaPRs(ctx, host, owner, repo, token, dateFilter, summaryOnly)
 	default:
 		return prFetchResult{}, fmt.Errorf("unsupported provider for PR fetching: %s", provider)
 	}
@@ -431,7 +446,7 @@ func splitByTokenLimit(items []formattedItem, maxTokens int, prefix string) []Gi
 	fileNum := 1
 
 	for _, item := range items {
-		itemTokens := countTokens(item.Content)
+		itemTokens := CountTokens(item.Content)
 
 		// If adding this item would exceed the limit and we already have content, flush.
 		if currentTokens > 0 && currentTokens+itemTokens > maxTokens {
@@ -475,13 +490,3 @@ func formatTimeIfSet(t time.Time) string {
 	}
 	return t.Format(time.RFC3339)
 }
-
-// countTokens estimates the number of tokens in text using cl100k_base encoding.
-func countTokens(text string) int {
-	enc, err := tiktoken.GetEncoding("cl100k_base")
-	if err != nil {
-		// Fallback: rough estimate of 4 chars per token.
-		return len(text) / 4
-	}
-	return len(enc.Encode(text, nil, nil))
-}
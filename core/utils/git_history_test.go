@@ -259,12 +259,12 @@ func TestCountTokens(t *testing.T) {
 	t.Parallel()
 
 	// Basic sanity check: a short string should have a reasonable token count.
-	tokens := countTokens("Hello, world!")
+	tokens := CountTokens("Hello, world!")
 	assert.Greater(t, tokens, 0)
 	assert.Less(t, tokens, 20)
 
 	// Empty string should have zero tokens.
-	assert.Equal(t, 0, countTokens(""))
+	assert.Equal(t, 0, CountTokens(""))
 }
 
 func TestFetchGitHistory_SkipCommits(t *testing.T) {
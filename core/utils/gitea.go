@@ -0,0 +1,365 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+)
+
+// giteaAPIBaseURL can be overridden in tests to point at a httptest server.
+// When empty, the API of the repository's host is used.
+var giteaAPIBaseURL string
+
+const (
+	giteaDefaultHost   = "gitea.com"
+	forgejoDefaultHost = "codeberg.org"
+	// giteaPageLimit is the page size requested from list endpoints; it is
+	// the default maximum of Gitea and Forgejo instances.
+	giteaPageLimit = 50
+)
+
+// giteaAPIBase returns the REST API root for a Gitea or Forgejo host.
+func giteaAPIBase(scheme, host string) string {
+	if giteaAPIBaseURL != "" {
+		return strings.TrimSuffix(giteaAPIBaseURL, "/")
+	}
+	if scheme == "" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/api/v1", scheme, host)
+}
+
+// giteaFileURLMarkers are the path segments that separate the repository from
+// the ref in Gitea and Forgejo web URLs of files.
+var giteaFileURLMarkers = []string{
+	"/src/branch/", "/src/tag/", "/src/commit/",
+	"/raw/branch/", "/raw/tag/", "/raw/commit/",
+}
+
+// IsGiteaFileURL reports whether path is a Gitea or Forgejo web URL of a single
+// file, e.g. https://git.example.com/owner/repo/src/branch/main/README.md.
+func IsGiteaFileURL(path string) bool {
+	for _, m := range giteaFileURLMarkers {
+		if strings.Contains(path, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// giteaFile identifies a file at a ref within a Gitea repository.
+type giteaFile struct {
+	scheme, host, owner, repo, ref, path string
+}
+
+// parseGiteaFileURL parses a Gitea or Forgejo file URL. A Tag or Commit
+// version overrides the ref in the URL.
+func parseGiteaFileURL(rawURL string, version *osdd.GitVersion) (giteaFile, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return giteaFile{}, fmt.Errorf("invalid gitea url %q: %w", rawURL, err)
+	}
+	// Path format: owner/repo/src|raw/branch|tag|commit/ref/file
+	parts := strings.SplitN(strings.Trim(u.Path, "/"), "/", 6)
+	if len(parts) < 6 || (parts[2] != "src" && parts[2] != "raw") || parts[4] == "" || parts[5] == "" {
+		return giteaFile{}, fmt.Errorf("invalid gitea file url: %s", rawURL)
+	}
+	file := giteaFile{scheme: u.Scheme, host: u.Host, owner: parts[0], repo: parts[1], ref: parts[4], path: parts[5]}
+	if version != nil && version.HasType() {
+		switch version.WhichType() {
+		case osdd.GitVersion_Tag_case:
+			file.ref = version.GetTag()
+		case osdd.GitVersion_Commit_case:
+			file.ref = version.GetCommit()
+		}
+	}
+	return file, nil
+}
+
+// FetchGiteaFile fetches a single file referenced by a Gitea or Forgejo file
+// URL through the raw file API of the URL's host. The token, when set,
+// authenticates the request so files in private repositories can be read.
+func FetchGiteaFile(ctx context.Context, ref *osdd.GitReference, token string) (string, error) {
+	if ref == nil {
+		return "", fmt.Errorf("gitea reference cannot be nil")
+	}
+	if ref.GetPath() == "" {
+		return "", fmt.Errorf("gitea path cannot be empty")
+	}
+	file, err := parseGiteaFileURL(ref.GetPath(), ref.GetVersion())
+	if err != nil {
+		return "", err
+	}
+
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/raw/%s?ref=%s", giteaAPIBase(file.scheme, file.host),
+		file.owner, file.repo, file.path, url.QueryEscape(file.ref))
+	body, err := giteaGet(ctx, apiURL, token)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s from gitea %s/%s@%s: %w", file.path, file.owner, file.repo, file.ref, err)
+	}
+	return string(body), nil
+}
+
+// resolveGiteaRevision returns the commit SHA of the ref a Gitea or Forgejo
+// file URL points to.
+func resolveGiteaRevision(ctx context.Context, ref *osdd.GitReference, token string) (string, error) {
+	file, err := parseGiteaFileURL(ref.GetPath(), ref.GetVersion())
+	if err != nil {
+		return "", err
+	}
+	if isFullCommitSHA(file.ref) {
+		return file.ref, nil
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/commits?sha=%s&limit=1&stat=false&files=false&verification=false",
+		giteaAPIBase(file.scheme, file.host), file.owner, file.repo, url.QueryEscape(file.ref))
+	body, err := giteaGet(ctx, apiURL, token)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s of gitea %s/%s: %w", file.ref, file.owner, file.repo, err)
+	}
+	var commits []struct {
+		SHA string `json:"sha"`
+	}
+	if err := json.Unmarshal(body, &commits); err != nil {
+		return "", fmt.Errorf("failed to parse Gitea commits: %w", err)
+	}
+	if len(commits) == 0 {
+		return "", fmt.Errorf("ref %s of gitea %s/%s has no commits", file.ref, file.owner, file.repo)
+	}
+	return commits[0].SHA, nil
+}
+
+// Gitea API response types.
+
+type giteaUser struct {
+	Login    string `json:"login"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+func (u *giteaUser) display() string {
+	if u == nil {
+		return ""
+	}
+	if u.FullName == "" || u.FullName == u.Login {
+		return u.Login
+	}
+	return u.FullName + " (" + u.Login + ")"
+}
+
+type giteaPR struct {
+	Number       int        `json:"number"`
+	Title        string     `json:"title"`
+	Body         string     `json:"body"`
+	State        string     `json:"state"`
+	HTMLURL      string     `json:"html_url"`
+	User         giteaUser  `json:"user"`
+	Merged       bool       `json:"merged"`
+	MergedAt     *time.Time `json:"merged_at"`
+	MergedBy     *giteaUser `json:"merged_by"`
+	Additions    int        `json:"additions"`
+	Deletions    int        `json:"deletions"`
+	ChangedFiles int        `json:"changed_files"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Labels       []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Head struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+}
+
+type giteaReview struct {
+	User  giteaUser `json:"user"`
+	State string    `json:"state"`
+	Body  string    `json:"body"`
+}
+
+type giteaComment struct {
+	User giteaUser `json:"user"`
+	Body string    `json:"body"`
+}
+
+// fetchGiteaPRs fetches pull requests from the Gitea or Forgejo REST API of
+// host, including reviews, comments and diffs, filtered by the given date range.
+func fetchGiteaPRs(ctx context.Context, host, owner, repo, token string, dateFilter *osdd.DatesFilter, summaryOnly bool) (prFetchResult, error) {
+	baseURL := giteaAPIBase("", host)
+	repoURL := fmt.Sprintf("%s/repos/%s/%s", baseURL, owner, repo)
+	sinceTime, untilTime := resolvePRDateRange(dateFilter)
+
+	// Phase 1: collect PR metadata. Pull requests are listed most recently
+	// updated first, so listing stops at the first one updated before sinceTime.
+	var allPRs []pullRequest
+	for page := 1; ; page++ {
+		body, err := giteaGet(ctx, fmt.Sprintf("%s/pulls?state=all&sort=recentupdate&limit=%d&page=%d", repoURL, giteaPageLimit, page), token)
+		if err != nil {
+			return prFetchResult{}, fmt.Errorf("failed to list Gitea PRs: %w", err)
+		}
+		var prs []giteaPR
+		if err := json.Unmarshal(body, &prs); err != nil {
+			return prFetchResult{}, fmt.Errorf("failed to parse Gitea PRs response: %w", err)
+		}
+
+		done := len(prs) < giteaPageLimit
+		for _, pr := range prs {
+			if pr.UpdatedAt.Before(sinceTime) {
+				done = true
+				break
+			}
+			if !isInDateRange(pr.CreatedAt, pr.UpdatedAt, sinceTime, untilTime) {
+				continue
+			}
+			allPRs = append(allPRs, giteaPullRequest(pr))
+		}
+		if done {
+			break
+		}
+	}
+
+	// Phase 2: fetch reviews, comments and diffs.
+	fetchPRDetails(ctx, allPRs, func(ctx context.Context, pr *pullRequest) {
+		if summaryOnly {
+			return
+		}
+
+		reviews, err := fetchGiteaReviews(ctx, repoURL, pr.Number, token)
+		if err != nil {
+			slog.Warn("Failed to fetch reviews for Gitea PR", "number", pr.Number, "error", err)
+		}
+		pr.Reviews = append(pr.Reviews, reviews...)
+
+		comments, err := fetchGiteaComments(ctx, repoURL, pr.Number, token)
+		if err != nil {
+			slog.Warn("Failed to fetch comments for Gitea PR", "number", pr.Number, "error", err)
+		}
+		pr.Reviews = append(pr.Reviews, comments...)
+
+		diff, err := giteaGet(ctx, fmt.Sprintf("%s/pulls/%d.diff", repoURL, pr.Number), token)
+		if err != nil {
+			slog.Warn("Failed to fetch diff for Gitea PR", "number", pr.Number, "error", err)
+		} else {
+			pr.Diff = string(diff)
+		}
+	})
+
+	slog.Debug("Gitea PRs fetched", "count", len(allPRs))
+	return prFetchResult{PRs: allPRs}, nil
+}
+
+func giteaPullRequest(pr giteaPR) pullRequest {
+	p := pullRequest{
+		Number:       pr.Number,
+		Title:        pr.Title,
+		URL:          pr.HTMLURL,
+		Author:       pr.User.display(),
+		AuthorEmail:  pr.User.Email,
+		State:        pr.State,
+		BaseBranch:   pr.Base.Ref,
+		HeadBranch:   pr.Head.Ref,
+		Additions:    pr.Additions,
+		Deletions:    pr.Deletions,
+		ChangedFiles: pr.ChangedFiles,
+		CreatedAt:    pr.CreatedAt,
+		UpdatedAt:    pr.UpdatedAt,
+		Body:         pr.Body,
+	}
+	for _, l := range pr.Labels {
+		p.Labels = append(p.Labels, l.Name)
+	}
+	if pr.Merged {
+		p.State = "merged"
+		p.MergedBy = pr.MergedBy.display()
+		if pr.MergedBy != nil {
+			p.MergedByEmail = pr.MergedBy.Email
+		}
+		if pr.MergedAt != nil {
+			p.MergedAt = *pr.MergedAt
+		}
+	}
+	return p
+}
+
+func fetchGiteaReviews(ctx context.Context, repoURL string, number int, token string) ([]prReview, error) {
+	body, err := giteaGet(ctx, repoURL+"/pulls/"+strconv.Itoa(number)+"/reviews", token)
+	if err != nil {
+		return nil, err
+	}
+	var reviews []giteaReview
+	if err := json.Unmarshal(body, &reviews); err != nil {
+		return nil, fmt.Errorf("failed to parse reviews: %w", err)
+	}
+	var result []prReview
+	for _, r := range reviews {
+		result = append(result, prReview{
+			Author:      r.User.display(),
+			AuthorEmail: r.User.Email,
+			State:       r.State,
+			Body:        r.Body,
+		})
+	}
+	return result, nil
+}
+
+func fetchGiteaComments(ctx context.Context, repoURL string, number int, token string) ([]prReview, error) {
+	// Pull request conversation comments live on the underlying issue.
+	body, err := giteaGet(ctx, repoURL+"/issues/"+strconv.Itoa(number)+"/comments", token)
+	if err != nil {
+		return nil, err
+	}
+	var comments []giteaComment
+	if err := json.Unmarshal(body, &comments); err != nil {
+		return nil, fmt.Errorf("failed to parse comments: %w", err)
+	}
+	var result []prReview
+	for _, c := range comments {
+		result = append(result, prReview{
+			Author:      c.User.display(),
+			AuthorEmail: c.User.Email,
+			State:       "COMMENTED",
+			Body:        c.Body,
+		})
+	}
+	return result, nil
+}
+
+func giteaGet(ctx context.Context, apiURL, token string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := readAllWithProgress(ctx, resp.Body, resp.ContentLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gitea API returned status %d: %s", resp.StatusCode, truncateBody(body))
+	}
+
+	return body, nil
+}
@@ -0,0 +1,242 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func withGiteaServer(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	old := giteaAPIBaseURL
+	giteaAPIBaseURL = server.URL
+	t.Cleanup(func() { giteaAPIBaseURL = old })
+	return server.URL
+}
+
+func TestFetchGiteaPRs_Success(t *testing.T) {
+	mergedAt := time.Date(2025, 6, 17, 0, 0, 0, 0, time.UTC)
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/repos/org/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token gt-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "all", r.URL.Query().Get("state"))
+		pr := giteaPR{
+			Number:       3,
+			Title:        "Forgejo PR",
+			Body:         "PR description",
+			State:        "closed",
+			HTMLURL:      "https://git.example.com/org/repo/pulls/3",
+			User:         giteaUser{Login: "alice", FullName: "Alice", Email: "alice@example.com"},
+			Merged:       true,
+			MergedAt:     &mergedAt,
+			MergedBy:     &giteaUser{Login: "bob", Email: "bob@example.com"},
+			Additions:    5,
+			Deletions:    2,
+			ChangedFiles: 1,
+			CreatedAt:    time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC),
+			UpdatedAt:    time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC),
+		}
+		pr.Labels = append(pr.Labels, struct {
+			Name string `json:"name"`
+		}{Name: "bug"})
+		pr.Base.Ref = "main"
+		pr.Head.Ref = "fix"
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]giteaPR{pr})
+	})
+
+	mux.HandleFunc("/repos/org/repo/pulls/3/reviews", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]giteaReview{
+			{User: giteaUser{Login: "bob"}, State: "APPROVED", Body: "LGTM"},
+		})
+	})
+
+	mux.HandleFunc("/repos/org/repo/issues/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]giteaComment{
+			{User: giteaUser{Login: "carol", FullName: "Carol"}, Body: "Thanks!"},
+		})
+	})
+
+	mux.HandleFunc("/repos/org/repo/pulls/3.diff", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("diff --git a/file.go b/file.go\n+added line"))
+	})
+
+	withGiteaServer(t, mux)
+
+	df := osdd.DatesFilter_builder{
+		From: timestamppb.New(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)),
+		To:   timestamppb.New(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)),
+	}.Build()
+
+	result, err := fetchGiteaPRs(t.Context(), "git.example.com", "org", "repo", "gt-token", df, false)
+	require.NoError(t, err)
+	require.Len(t, result.PRs, 1)
+
+	pr := result.PRs[0]
+	assert.Equal(t, 3, pr.Number)
+	assert.Equal(t, "Forgejo PR", pr.Title)
+	assert.Equal(t, "Alice (alice)", pr.Author)
+	assert.Equal(t, "alice@example.com", pr.AuthorEmail)
+	assert.Equal(t, "merged", pr.State)
+	assert.Equal(t, "bob", pr.MergedBy)
+	assert.Equal(t, "bob@example.com", pr.MergedByEmail)
+	assert.Equal(t, mergedAt, pr.MergedAt)
+	assert.Equal(t, []string{"bug"}, pr.Labels)
+	assert.Equal(t, "main", pr.BaseBranch)
+	assert.Equal(t, "fix", pr.HeadBranch)
+	assert.Equal(t, 5, pr.Additions)
+	assert.Equal(t, 2, pr.Deletions)
+	assert.Equal(t, 1, pr.ChangedFiles)
+	assert.Contains(t, pr.Diff, "+added line")
+
+	require.Len(t, pr.Reviews, 2)
+	assert.Equal(t, "bob", pr.Reviews[0].Author)
+	assert.Equal(t, "APPROVED", pr.Reviews[0].State)
+	assert.Equal(t, "LGTM", pr.Reviews[0].Body)
+	assert.Equal(t, "Carol (carol)", pr.Reviews[1].Author)
+	assert.Equal(t, "COMMENTED", pr.Reviews[1].State)
+	assert.Equal(t, "Thanks!", pr.Reviews[1].Body)
+}
+
+func TestFetchGiteaPRs_DateFiltering(t *testing.T) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/repos/org/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]giteaPR{
+			{
+				Number:    2,
+				Title:     "After range",
+				State:     "open",
+				CreatedAt: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC),
+			},
+			{
+				Number:    1,
+				Title:     "In range",
+				State:     "open",
+				CreatedAt: time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC),
+			},
+			{
+				Number:    0,
+				Title:     "Before range",
+				State:     "closed",
+				CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+			},
+		})
+	})
+
+	withGiteaServer(t, mux)
+
+	df := osdd.DatesFilter_builder{
+		From: timestamppb.New(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)),
+		To:   timestamppb.New(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)),
+	}.Build()
+
+	result, err := fetchGiteaPRs(t.Context(), "git.example.com", "org", "repo", "", df, true)
+	require.NoError(t, err)
+	require.Len(t, result.PRs, 1)
+	assert.Equal(t, "In range", result.PRs[0].Title)
+	assert.Empty(t, result.PRs[0].Diff)
+}
+
+func TestFetchGiteaPRs_Pagination(t *testing.T) {
+	callCount := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/org/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		now := time.Now().UTC()
+		var prs []giteaPR
+		if r.URL.Query().Get("page") == "1" {
+			// A full page means more pages may follow.
+			for i := 0; i < giteaPageLimit; i++ {
+				prs = append(prs, giteaPR{Number: i + 1, Title: "PR " + strconv.Itoa(i+1), State: "open", CreatedAt: now, UpdatedAt: now})
+			}
+		} else {
+			prs = []giteaPR{{Number: 100, Title: "Last", State: "open", CreatedAt: now, UpdatedAt: now}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(prs)
+	})
+
+	withGiteaServer(t, mux)
+
+	result, err := fetchGiteaPRs(t.Context(), "git.example.com", "org", "repo", "", nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, callCount)
+	assert.Len(t, result.PRs, giteaPageLimit+1)
+}
+
+func TestFetchGiteaPRs_ListError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/org/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	})
+
+	withGiteaServer(t, mux)
+
+	_, err := fetchGiteaPRs(t.Context(), "git.example.com", "org", "repo", "", nil, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gitea API returned status 403")
+}
+
+func TestFetchPRs_ForgejoProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/org/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]giteaPR{})
+	})
+
+	withGiteaServer(t, mux)
+
+	result, err := fetchPRs(t.Context(), "forgejo:git.example.com", "org/repo", "", nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, result.PRs)
+}
+
+func TestFetchGiteaFile(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/org/repo/raw/docs/guide.md", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token gt-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "v1", r.URL.Query().Get("ref"))
+		_, _ = w.Write([]byte("# Guide"))
+	})
+
+	withGiteaServer(t, mux)
+
+	ref := osdd.GitReference_builder{Path: "https://git.example.com/org/repo/src/tag/v1/docs/guide.md"}.Build()
+	content, err := FetchGitFile(t.Context(), ref, GitFileTokens{Gitea: "gt-token"})
+	require.NoError(t, err)
+	assert.Equal(t, "# Guide", content)
+}
+
+func TestParseGiteaFileURL(t *testing.T) {
+	t.Parallel()
+	got, err := parseGiteaFileURL("https://git.example.com/org/repo/src/branch/main/docs/a.md", nil)
+	require.NoError(t, err)
+	assert.Equal(t, giteaFile{scheme: "https", host: "git.example.com", owner: "org", repo: "repo", ref: "main", path: "docs/a.md"}, got)
+
+	got, err = parseGiteaFileURL("codeberg.org/org/repo/raw/commit/abc/a.md", osdd.GitVersion_builder{Tag: strPtr("v2")}.Build())
+	require.NoError(t, err)
+	assert.Equal(t, "codeberg.org", got.host)
+	assert.Equal(t, "v2", got.ref)
+
+	_, err = parseGiteaFileURL("https://git.example.com/org/repo/src/branch/main", nil)
+	assert.Error(t, err)
+}
@@ -3,26 +3,36 @@ package utils
 import (
 	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 
+	"github.com/google/go-github/v83/github"
 	"github.com/opensdd/osdd-api/clients/go/osdd"
 )
 
-// ConvertToRawURL converts a github.com URL to raw.githubusercontent.com format.
-// It handles various GitHub URL formats including /blob/ and /tree/ patterns.
-// If a version is provided, it will be used; otherwise defaults to "main" branch.
-func ConvertToRawURL(githubPath string, version *osdd.GitVersion) (string, error) {
+// GithubReleaseTagPrefix marks a GitVersion tag that selects a GitHub release
+// rather than a git ref: "release:latest" resolves to the latest release and
+// "release:<name>" to the release published for tag <name>.
+const GithubReleaseTagPrefix = "release:"
+
+// githubFile identifies a file at a ref within a GitHub repository.
+type githubFile struct {
+	owner, repo, ref, path string
+}
+
+func (f githubFile) rawURL() string {
+	return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", f.owner, f.repo, f.ref, f.path)
+}
+
+// parseGithubPath splits a github.com URL into repository, ref and file path.
+// ok is false for raw.githubusercontent.com and non-GitHub URLs, which are
+// fetched as-is.
+func parseGithubPath(githubPath string, version *osdd.GitVersion) (file githubFile, ok bool, err error) {
 	// If it's already a raw.githubusercontent.com URL or doesn't contain github.com, return as-is
 	if strings.Contains(githubPath, "raw.githubusercontent.com") || !strings.Contains(githubPath, "github.com") {
-		return githubPath, nil
+		return githubFile{}, false, nil
 	}
 
-	// Convert github.com URL to raw.githubusercontent.com
-	// Example: https://github.com/myorg/repo/blob/main/README.MD
-	// To: https://raw.githubusercontent.com/myorg/repo/main/README.MD
-
 	githubPath = strings.TrimPrefix(githubPath, "https://")
 	githubPath = strings.TrimPrefix(githubPath, "http://")
 	githubPath = strings.TrimPrefix(githubPath, "github.com/")
@@ -33,43 +43,62 @@ func ConvertToRawURL(githubPath string, version *osdd.GitVersion) (string, error
 
 	parts := strings.SplitN(githubPath, "/", 5)
 
-	var owner, repo, ref, filePath string
-
 	if len(parts) >= 4 && (parts[2] == "blob" || parts[2] == "tree") {
 		// Format: owner/repo/blob|tree/ref/file.md
 		if len(parts) < 5 {
-			return "", fmt.Errorf("invalid github path format: %s", githubPath)
+			return githubFile{}, false, fmt.Errorf("invalid github path format: %s", githubPath)
 		}
-		owner = parts[0]
-		repo = parts[1]
-		ref = parts[3]
-		filePath = parts[4]
-	} else if len(parts) >= 3 {
-		// Format: owner/repo/file.md
-		owner = parts[0]
-		repo = parts[1]
-		filePath = strings.Join(parts[2:], "/")
-
-		// Use version from parameter if provided
-		ref = "main"
-		if version != nil && version.HasType() {
-			switch version.WhichType() {
-			case osdd.GitVersion_Tag_case:
-				ref = version.GetTag()
-			case osdd.GitVersion_Commit_case:
-				ref = version.GetCommit()
-			}
+		return githubFile{owner: parts[0], repo: parts[1], ref: parts[3], path: parts[4]}, true, nil
+	}
+	if len(parts) < 3 {
+		return githubFile{}, false, fmt.Errorf("invalid github path format: %s", githubPath)
+	}
+
+	// Format: owner/repo/file.md
+	file = githubFile{owner: parts[0], repo: parts[1], path: strings.Join(parts[2:], "/")}
+
+	// Use version from parameter if provided
+	file.ref = "main"
+	if version != nil && version.HasType() {
+		switch version.WhichType() {
+		case osdd.GitVersion_Tag_case:
+			file.ref = version.GetTag()
+		case osdd.GitVersion_Commit_case:
+			file.ref = version.GetCommit()
 		}
-	} else {
-		return "", fmt.Errorf("invalid github path format: %s", githubPath)
 	}
+	return file, true, nil
+}
 
-	return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", owner, repo, ref, filePath), nil
+// ConvertToRawURL converts a github.com URL to raw.githubusercontent.com format.
+// It handles various GitHub URL formats including /blob/ and /tree/ patterns.
+// If a version is provided, it will be used; otherwise defaults to "main" branch.
+// Release tags (see GithubReleaseTagPrefix) are not resolved.
+func ConvertToRawURL(githubPath string, version *osdd.GitVersion) (string, error) {
+	// Convert github.com URL to raw.githubusercontent.com
+	// Example: https://github.com/myorg/repo/blob/main/README.MD
+	// To: https://raw.githubusercontent.com/myorg/repo/main/README.MD
+	file, ok, err := parseGithubPath(githubPath, version)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return githubPath, nil
+	}
+	return file.rawURL(), nil
 }
 
 // FetchGithub fetches the content of a GitHub file reference using a raw content URL.
 // If the provided ref.Path is not a github.com URL, it is used as-is.
 func FetchGithub(ctx context.Context, ref *osdd.GitReference) (string, error) {
+	return FetchGithubWithToken(ctx, ref, "")
+}
+
+// FetchGithubWithToken fetches the content of a GitHub file reference. When a
+// token is given, github.com files are read through the authenticated contents
+// API so private repositories work; otherwise a raw content URL is used.
+// Release tags are resolved through the releases API in both cases.
+func FetchGithubWithToken(ctx context.Context, ref *osdd.GitReference, token string) (string, error) {
 	if ref == nil {
 		return "", fmt.Errorf("github reference cannot be nil")
 	}
@@ -79,10 +108,22 @@ func FetchGithub(ctx context.Context, ref *osdd.GitReference) (string, error) {
 		return "", fmt.Errorf("github path cannot be empty")
 	}
 
-	url, err := ConvertToRawURL(githubPath, ref.GetVersion())
+	file, ok, err := parseGithubPath(githubPath, ref.GetVersion())
 	if err != nil {
 		return "", err
 	}
+	url := githubPath
+	if ok {
+		if release, isRelease := strings.CutPrefix(file.ref, GithubReleaseTagPrefix); isRelease {
+			if file.ref, err = resolveGithubRelease(ctx, newGitHubClient(token), file.owner, file.repo, release); err != nil {
+				return "", err
+			}
+		}
+		if token != "" {
+			return fetchGithubContents(ctx, newGitHubClient(token), file)
+		}
+		url = file.rawURL()
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -99,10 +140,76 @@ func FetchGithub(ctx context.Context, ref *osdd.GitReference) (string, error) {
 		return "", fmt.Errorf("github fetch returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readAllWithProgress(ctx, resp.Body, resp.ContentLength)
 	if err != nil {
 		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	return string(body), nil
 }
+
+// resolveGithubRelease returns the tag of the named release, or of the latest
+// release when name is "latest".
+func resolveGithubRelease(ctx context.Context, client *github.Client, owner, repo, name string) (string, error) {
+	var (
+		release *github.RepositoryRelease
+		err     error
+	)
+	if name == "latest" {
+		release, _, err = client.Repositories.GetLatestRelease(ctx, owner, repo)
+	} else {
+		release, _, err = client.Repositories.GetReleaseByTag(ctx, owner, repo, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve release %s of %s/%s: %w", name, owner, repo, err)
+	}
+	if release.GetTagName() == "" {
+		return "", fmt.Errorf("release %s of %s/%s has no tag", name, owner, repo)
+	}
+	return release.GetTagName(), nil
+}
+
+// fetchGithubContents reads a file through the GitHub contents API.
+func fetchGithubContents(ctx context.Context, client *github.Client, file githubFile) (string, error) {
+	body, resp, err := client.Repositories.DownloadContents(ctx, file.owner, file.repo, file.path,
+		&github.RepositoryContentGetOptions{Ref: file.ref})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s from github %s/%s@%s: %w", file.path, file.owner, file.repo, file.ref, err)
+	}
+	defer func() { _ = body.Close() }()
+
+	// The download may fall back to the file's download URL, whose status is
+	// not checked by go-github.
+	if resp != nil && resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("github fetch returned status %d", resp.StatusCode)
+	}
+
+	content, err := readAllWithProgress(ctx, body, -1)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	return string(content), nil
+}
+
+// resolveGithubRevision returns the commit SHA of the ref a github.com file
+// reference points to, or "" for raw and non-GitHub URLs.
+func resolveGithubRevision(ctx context.Context, ref *osdd.GitReference, token string) (string, error) {
+	file, ok, err := parseGithubPath(ref.GetPath(), ref.GetVersion())
+	if err != nil || !ok {
+		return "", err
+	}
+	if isFullCommitSHA(file.ref) {
+		return file.ref, nil
+	}
+	client := newGitHubClient(token)
+	if release, isRelease := strings.CutPrefix(file.ref, GithubReleaseTagPrefix); isRelease {
+		if file.ref, err = resolveGithubRelease(ctx, client, file.owner, file.repo, release); err != nil {
+			return "", err
+		}
+	}
+	sha, _, err := client.Repositories.GetCommitSHA1(ctx, file.owner, file.repo, file.ref, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s of %s/%s: %w", file.ref, file.owner, file.repo, err)
+	}
+	return sha, nil
+}
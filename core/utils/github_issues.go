@@ -0,0 +1,300 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/go-github/v83/github"
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+)
+
+const githubMaxIssues = 1000
+
+// GitHubIssuesOptions selects the issues FetchGitHubIssues fetches. Values
+// are passed to the GitHub issues API.
+type GitHubIssuesOptions struct {
+	// State is "open", "closed" or "all". The default is "open".
+	State string
+	// Labels matches issues carrying all of the labels.
+	Labels []string
+	// Milestone matches a milestone number or title. "*" matches issues with
+	// any milestone, "none" issues without one.
+	Milestone string
+	// Assignee matches a login. "*" matches assigned issues, "none"
+	// unassigned ones.
+	Assignee string
+
+	// Format selects the rendering of per-issue files.
+	Format IssueFormat
+
+	// MaxIssues caps the number of fetched issues. The default is 1000. A
+	// warning is logged when more issues match.
+	MaxIssues int
+}
+
+func (o GitHubIssuesOptions) maxIssues() int {
+	if o.MaxIssues > 0 {
+		return o.MaxIssues
+	}
+	return githubMaxIssues
+}
+
+// githubIssue is the per-issue output of FetchGitHubIssues.
+type githubIssue struct {
+	Number      int                  `json:"number"`
+	Title       string               `json:"title"`
+	URL         string               `json:"url"`
+	State       string               `json:"state"`
+	StateReason string               `json:"stateReason,omitempty"`
+	Author      string               `json:"author"`
+	Assignees   []string             `json:"assignees,omitempty"`
+	Labels      []string             `json:"labels,omitempty"`
+	Milestone   string               `json:"milestone,omitempty"`
+	CreatedAt   string               `json:"createdAt"`
+	UpdatedAt   string               `json:"updatedAt"`
+	ClosedAt    string               `json:"closedAt,omitempty"`
+	Body        string               `json:"body"`
+	Comments    []githubIssueComment `json:"comments,omitempty"`
+
+	commentCount int
+}
+
+type githubIssueComment struct {
+	Author    string `json:"author"`
+	CreatedAt string `json:"createdAt"`
+	Body      string `json:"body"`
+}
+
+var githubIssueTmpl = template.Must(template.New("github-issue").Funcs(template.FuncMap{"join": strings.Join}).Parse(
+	`# #{{.Number}}: {{.Title}}
+
+**State:** {{.State}}{{if .StateReason}} ({{.StateReason}}){{end}}
+**Author:** {{.Author}}
+{{- if .Assignees}}
+**Assignees:** {{join .Assignees ", "}}
+{{- end}}
+{{- if .Labels}}
+**Labels:** {{join .Labels ", "}}
+{{- end}}
+{{- if .Milestone}}
+**Milestone:** {{.Milestone}}
+{{- end}}
+**Created:** {{.CreatedAt}}
+**Updated:** {{.UpdatedAt}}
+{{- if .ClosedAt}}
+**Closed:** {{.ClosedAt}}
+{{- end}}
+**URL:** {{.URL}}
+{{if .Body}}
+## Description
+
+{{.Body}}
+{{end}}{{if .Comments}}
+## Comments
+{{range .Comments}}
+### {{.Author}} ({{.CreatedAt}})
+
+{{.Body}}
+{{end}}{{end}}`))
+
+// FetchGitHubIssues fetches the issues of a GitHub repository with their
+// comments and returns them in the IssuesResult layout of the Jira and
+// Linear sources. Pull requests are skipped. dateFilter keeps issues created
+// or updated within its range.
+func FetchGitHubIssues(ctx context.Context, repo *osdd.GitRepository, dateFilter *osdd.DatesFilter, token string, opts GitHubIssuesOptions) (*IssuesResult, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("github issues repository cannot be nil")
+	}
+	if err := opts.Format.validate(); err != nil {
+		return nil, err
+	}
+	if name, _, err := ParseGitProvider(repo.GetProvider()); err != nil || name != "github" {
+		return nil, fmt.Errorf("issues are only supported for GitHub repositories, got provider %q", repo.GetProvider())
+	}
+	owner, name, ok := strings.Cut(repo.GetFullName(), "/")
+	if !ok || owner == "" || name == "" {
+		return nil, fmt.Errorf("invalid full_name %q: expected owner/repo", repo.GetFullName())
+	}
+	switch opts.State {
+	case "", "open", "closed", "all":
+	default:
+		return nil, fmt.Errorf("unsupported github issue state %q", opts.State)
+	}
+
+	client := newGitHubClient(token)
+	listOpts := &github.IssueListByRepoOptions{
+		State:       opts.State,
+		Labels:      opts.Labels,
+		Assignee:    opts.Assignee,
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	if opts.Milestone != "" {
+		milestone, err := resolveGitHubMilestone(ctx, client, owner, name, opts.Milestone)
+		if err != nil {
+			return nil, err
+		}
+		listOpts.Milestone = milestone
+	}
+
+	var sinceTime, untilTime time.Time
+	if dateFilter != nil {
+		if dateFilter.HasFrom() {
+			sinceTime = dateFilter.GetFrom().AsTime().UTC()
+			listOpts.Since = sinceTime
+		}
+		if dateFilter.HasTo() {
+			// Make "to" inclusive by adding one day.
+			untilTime = dateFilter.GetTo().AsTime().UTC().AddDate(0, 0, 1)
+		}
+	}
+
+	slog.Debug("Fetching GitHub issues", "repo", owner+"/"+name, "state", opts.State, "labels", opts.Labels)
+
+	maxIssues := opts.maxIssues()
+	var allIssues []githubIssue
+	truncated := false
+list:
+	for {
+		ghIssues, resp, err := client.Issues.ListByRepo(ctx, owner, name, listOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list GitHub issues: %w", err)
+		}
+		for _, issue := range ghIssues {
+			if issue.IsPullRequest() {
+				continue
+			}
+			if !isInDateRange(issue.GetCreatedAt().Time, issue.GetUpdatedAt().Time, sinceTime, untilTime) {
+				continue
+			}
+			if len(allIssues) == maxIssues {
+				truncated = true
+				break list
+			}
+			allIssues = append(allIssues, newGitHubIssue(issue))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.ListOptions.Page = resp.NextPage
+	}
+	if truncated {
+		slog.Warn("GitHub issues truncated: more issues match the filter than the configured maximum", "max", maxIssues, "repo", owner+"/"+name)
+	}
+
+	forEachConcurrent(ctx, maxPRFetchConcurrency, allIssues, func(ctx context.Context, issue *githubIssue) {
+		if issue.commentCount == 0 {
+			return
+		}
+		comments, err := fetchGitHubIssueComments(ctx, client, owner, name, issue.Number)
+		if err != nil {
+			slog.Warn("Failed to fetch GitHub issue comments", "number", issue.Number, "err", err)
+		}
+		issue.Comments = comments
+	})
+
+	slog.Debug("GitHub issues fetched", "count", len(allIssues))
+
+	result := &IssuesResult{
+		Summary: make([]IssueSummary, 0, len(allIssues)),
+		Issues:  make(map[string]string, len(allIssues)),
+		Format:  opts.Format,
+	}
+	for _, issue := range allIssues {
+		id := strconv.Itoa(issue.Number)
+		result.Summary = append(result.Summary, IssueSummary{ID: id, Title: issue.Title})
+		if opts.Format == IssueFormatMarkdown {
+			var buf bytes.Buffer
+			if err := githubIssueTmpl.Execute(&buf, issue); err != nil {
+				return nil, fmt.Errorf("failed to render github issue %d: %w", issue.Number, err)
+			}
+			result.Issues[id] = buf.String()
+			continue
+		}
+		raw, err := json.MarshalIndent(issue, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal github issue %d: %w", issue.Number, err)
+		}
+		result.Issues[id] = string(raw)
+	}
+	return result, nil
+}
+
+func newGitHubIssue(issue *github.Issue) githubIssue {
+	i := githubIssue{
+		Number:       issue.GetNumber(),
+		Title:        issue.GetTitle(),
+		URL:          issue.GetHTMLURL(),
+		State:        issue.GetState(),
+		StateReason:  issue.GetStateReason(),
+		Author:       issue.GetUser().GetLogin(),
+		Milestone:    issue.GetMilestone().GetTitle(),
+		CreatedAt:    formatTimeIfSet(issue.GetCreatedAt().Time),
+		UpdatedAt:    formatTimeIfSet(issue.GetUpdatedAt().Time),
+		ClosedAt:     formatTimeIfSet(issue.GetClosedAt().Time),
+		Body:         issue.GetBody(),
+		commentCount: issue.GetComments(),
+	}
+	for _, a := range issue.Assignees {
+		i.Assignees = append(i.Assignees, a.GetLogin())
+	}
+	for _, l := range issue.Labels {
+		i.Labels = append(i.Labels, l.GetName())
+	}
+	return i
+}
+
+// resolveGitHubMilestone returns the milestone parameter of the issues API
+// for milestone: numbers, "*" and "none" are passed through, titles are
+// looked up among the repository's milestones.
+func resolveGitHubMilestone(ctx context.Context, client *github.Client, owner, repo, milestone string) (string, error) {
+	if milestone == "*" || strings.EqualFold(milestone, "none") || isDigits(milestone) {
+		return milestone, nil
+	}
+	opts := &github.MilestoneListOptions{State: "all", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		milestones, resp, err := client.Issues.ListMilestones(ctx, owner, repo, opts)
+		if err != nil {
+			return "", fmt.Errorf("failed to list GitHub milestones: %w", err)
+		}
+		for _, m := range milestones {
+			if strings.EqualFold(m.GetTitle(), milestone) {
+				return strconv.Itoa(m.GetNumber()), nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return "", fmt.Errorf("github milestone %q not found in %s/%s", milestone, owner, repo)
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func fetchGitHubIssueComments(ctx context.Context, client *github.Client, owner, repo string, number int) ([]githubIssueComment, error) {
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var comments []githubIssueComment
+	for {
+		page, resp, err := client.Issues.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return comments, err
+		}
+		for _, c := range page {
+			comments = append(comments, githubIssueComment{
+				Author:    c.GetUser().GetLogin(),
+				CreatedAt: formatTimeIfSet(c.GetCreatedAt().Time),
+				Body:      c.GetBody(),
+			})
+		}
+		if resp.NextPage == 0 {
+			return comments, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v83/github"
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func githubRepo(fullName, provider string) *osdd.GitRepository {
+	return osdd.GitRepository_builder{FullName: fullName, Provider: provider}.Build()
+}
+
+func TestFetchGitHubIssues(t *testing.T) {
+	created := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	var query map[string]string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/milestones", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]*github.Milestone{
+			{Number: github.Ptr(3), Title: github.Ptr("v1.0")},
+			{Number: github.Ptr(4), Title: github.Ptr("v2.0")},
+		})
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		query = map[string]string{}
+		for k := range r.URL.Query() {
+			query[k] = r.URL.Query().Get(k)
+		}
+		_ = json.NewEncoder(w).Encode([]*github.Issue{
+			{
+				Number:    github.Ptr(7),
+				Title:     github.Ptr("Crash on start"),
+				HTMLURL:   github.Ptr("https://github.com/owner/repo/issues/7"),
+				State:     github.Ptr("open"),
+				Body:      github.Ptr("Steps to reproduce."),
+				User:      &github.User{Login: github.Ptr("alice")},
+				Assignees: []*github.User{{Login: github.Ptr("bob")}},
+				Labels:    []*github.Label{{Name: github.Ptr("bug")}},
+				Milestone: &github.Milestone{Title: github.Ptr("v2.0")},
+				Comments:  github.Ptr(1),
+				CreatedAt: ghTimestamp(created),
+				UpdatedAt: ghTimestamp(created),
+			},
+			{
+				Number:           github.Ptr(8),
+				Title:            github.Ptr("A pull request"),
+				PullRequestLinks: &github.PullRequestLinks{URL: github.Ptr("https://api.github.com/repos/owner/repo/pulls/8")},
+				CreatedAt:        ghTimestamp(created),
+				UpdatedAt:        ghTimestamp(created),
+			},
+			{
+				Number:    github.Ptr(9),
+				Title:     github.Ptr("Too late"),
+				CreatedAt: ghTimestamp(created.AddDate(0, 1, 0)),
+				UpdatedAt: ghTimestamp(created.AddDate(0, 1, 0)),
+			},
+		})
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]*github.IssueComment{
+			{Body: github.Ptr("Confirmed."), User: &github.User{Login: github.Ptr("carol")}, CreatedAt: ghTimestamp(created.Add(time.Hour))},
+		})
+	})
+	withGitHubServer(t, mux)
+
+	df := osdd.DatesFilter_builder{
+		From: timestamppb.New(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)),
+		To:   timestamppb.New(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)),
+	}.Build()
+	opts := GitHubIssuesOptions{State: "all", Labels: []string{"bug", "p1"}, Milestone: "v2.0", Assignee: "bob"}
+	result, err := FetchGitHubIssues(context.Background(), githubRepo("owner/repo", ""), df, "test-token", opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, "all", query["state"])
+	assert.Equal(t, "bug,p1", query["labels"])
+	assert.Equal(t, "4", query["milestone"])
+	assert.Equal(t, "bob", query["assignee"])
+	assert.Equal(t, "2025-06-01T00:00:00Z", query["since"])
+
+	assert.Equal(t, []IssueSummary{{ID: "7", Title: "Crash on start"}}, result.Summary)
+	raw := result.Issues["7"]
+	assert.Contains(t, raw, `"author": "alice"`)
+	assert.Contains(t, raw, `"milestone": "v2.0"`)
+	assert.Contains(t, raw, `"body": "Confirmed."`)
+
+	opts.Format = IssueFormatMarkdown
+	result, err = FetchGitHubIssues(context.Background(), githubRepo("owner/repo", "github"), df, "test-token", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "# #7: Crash on start\n\n"+
+		"**State:** open\n"+
+		"**Author:** alice\n"+
+		"**Assignees:** bob\n"+
+		"**Labels:** bug\n"+
+		"**Milestone:** v2.0\n"+
+		"**Created:** 2025-06-10T00:00:00Z\n"+
+		"**Updated:** 2025-06-10T00:00:00Z\n"+
+		"**URL:** https://github.com/owner/repo/issues/7\n\n"+
+		"## Description\n\nSteps to reproduce.\n\n"+
+		"## Comments\n\n### carol (2025-06-10T01:00:00Z)\n\nConfirmed.\n", result.Issues["7"])
+}
+
+func TestFetchGitHubIssues_MaxIssues(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		var issues []*github.Issue
+		for i := 1; i <= 3; i++ {
+			issues = append(issues, &github.Issue{Number: github.Ptr(i), Title: github.Ptr(fmt.Sprintf("Issue %d", i))})
+		}
+		_ = json.NewEncoder(w).Encode(issues)
+	})
+	withGitHubServer(t, mux)
+
+	result, err := FetchGitHubIssues(context.Background(), githubRepo("owner/repo", ""), nil, "", GitHubIssuesOptions{MaxIssues: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []IssueSummary{{ID: "1", Title: "Issue 1"}, {ID: "2", Title: "Issue 2"}}, result.Summary)
+}
+
+func TestFetchGitHubIssues_Validation(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	_, err := FetchGitHubIssues(ctx, githubRepo("owner/repo", "gitlab"), nil, "", GitHubIssuesOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only supported for GitHub repositories")
+
+	_, err = FetchGitHubIssues(ctx, githubRepo("repo", ""), nil, "", GitHubIssuesOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected owner/repo")
+
+	_, err = FetchGitHubIssues(ctx, githubRepo("owner/repo", ""), nil, "", GitHubIssuesOptions{State: "merged"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported github issue state "merged"`)
+}
+
+func TestFetchGitHubIssues_UnknownMilestone(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/milestones", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	withGitHubServer(t, mux)
+
+	_, err := FetchGitHubIssues(context.Background(), githubRepo("owner/repo", ""), nil, "", GitHubIssuesOptions{Milestone: "v9"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `github milestone "v9" not found`)
+}
@@ -275,21 +275,7 @@ func fetchGitHubPRs(ctx context.Context, owner, repo, token string, dateFilter *
 
 // fetchPRDetails runs fn for each PR in parallel with bounded concurrency.
 func fetchPRDetails(ctx context.Context, prs []pullRequest, fn func(context.Context, *pullRequest)) {
-	if len(prs) == 0 {
-		return
-	}
-	sem := make(chan struct{}, maxPRFetchConcurrency)
-	var wg sync.WaitGroup
-	for i := range prs {
-		wg.Add(1)
-		sem <- struct{}{}
-		go func(pr *pullRequest) {
-			defer wg.Done()
-			defer func() { <-sem }()
-			fn(ctx, pr)
-		}(&prs[i])
-	}
-	wg.Wait()
+	forEachConcurrent(ctx, maxPRFetchConcurrency, prs, fn)
 }
 
 func fetchGitHubReviews(ctx context.Context, client *github.Client, owner, repo string, number int) ([]prReview, error) {
@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
 	"testing"
 
+	"github.com/google/go-github/v83/github"
 	"github.com/opensdd/osdd-api/clients/go/osdd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -75,3 +79,114 @@ func TestConvertToRawURL_TreeFormat(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "https://raw.githubusercontent.com/owner/repo/v1.0.0/docs/guide.md", result)
 }
+
+// githubFileContent writes a contents API response for a single file.
+func githubFileContent(w http.ResponseWriter, name, content string) {
+	_ = json.NewEncoder(w).Encode(&github.RepositoryContent{
+		Type:     github.Ptr("file"),
+		Name:     github.Ptr(name),
+		Encoding: github.Ptr("base64"),
+		Content:  github.Ptr(base64.StdEncoding.EncodeToString([]byte(content))),
+	})
+}
+
+func TestFetchGithubWithToken_ContentsAPI(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/private/contents/docs/guide.md", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "v2", r.URL.Query().Get("ref"))
+		githubFileContent(w, "guide.md", "private guide")
+	})
+	withGitHubServer(t, mux)
+
+	ref := osdd.GitReference_builder{
+		Path:    "https://github.com/owner/private/docs/guide.md",
+		Version: osdd.GitVersion_builder{Tag: strPtr("v2")}.Build(),
+	}.Build()
+	content, err := FetchGithubWithToken(t.Context(), ref, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "private guide", content)
+}
+
+func TestFetchGithubWithToken_LatestRelease(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&github.RepositoryRelease{TagName: github.Ptr("v3.1.0")})
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "v3.1.0", r.URL.Query().Get("ref"))
+		githubFileContent(w, "README.md", "released readme")
+	})
+	withGitHubServer(t, mux)
+
+	ref := osdd.GitReference_builder{
+		Path:    "github.com/owner/repo/README.md",
+		Version: osdd.GitVersion_builder{Tag: strPtr(GithubReleaseTagPrefix + "latest")}.Build(),
+	}.Build()
+	content, err := FetchGithubWithToken(t.Context(), ref, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "released readme", content)
+}
+
+func TestFetchGithubWithToken_ReleaseByTag(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/releases/tags/stable", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&github.RepositoryRelease{TagName: github.Ptr("stable")})
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "stable", r.URL.Query().Get("ref"))
+		githubFileContent(w, "README.md", "stable readme")
+	})
+	withGitHubServer(t, mux)
+
+	ref := osdd.GitReference_builder{
+		Path:    "github.com/owner/repo/README.md",
+		Version: osdd.GitVersion_builder{Tag: strPtr(GithubReleaseTagPrefix + "stable")}.Build(),
+	}.Build()
+	content, err := FetchGithubWithToken(t.Context(), ref, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "stable readme", content)
+}
+
+func TestFetchGithubWithToken_MissingRelease(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/releases/tags/nope", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	})
+	withGitHubServer(t, mux)
+
+	ref := osdd.GitReference_builder{
+		Path:    "github.com/owner/repo/README.md",
+		Version: osdd.GitVersion_builder{Tag: strPtr(GithubReleaseTagPrefix + "nope")}.Build(),
+	}.Build()
+	_, err := FetchGithubWithToken(t.Context(), ref, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to resolve release nope of owner/repo")
+}
+
+func TestFetchGithubWithToken_NotFound(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	})
+	withGitHubServer(t, mux)
+
+	ref := osdd.GitReference_builder{Path: "github.com/owner/repo/missing.md"}.Build()
+	_, err := FetchGithubWithToken(t.Context(), ref, "secret")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to fetch missing.md from github owner/repo@main")
+}
+
+func TestResolveGitFileRevision_FullSHAWithoutRequest(t *testing.T) {
+	t.Parallel()
+	sha := "0123456789abcdef0123456789abcdef01234567"
+	for _, ref := range []*osdd.GitReference{
+		osdd.GitReference_builder{Path: "https://github.com/org/repo/blob/" + sha + "/README.md"}.Build(),
+		osdd.GitReference_builder{Path: "https://github.com/org/repo/README.md", Version: osdd.GitVersion_builder{Commit: strPtr(sha)}.Build()}.Build(),
+		osdd.GitReference_builder{Path: "https://gitlab.invalid/group/repo/-/blob/" + sha + "/README.md"}.Build(),
+	} {
+		got, err := ResolveGitFileRevision(t.Context(), ref, GitFileTokens{})
+		require.NoError(t, err, ref.GetPath())
+		assert.Equal(t, sha, got, ref.GetPath())
+	}
+}
@@ -0,0 +1,455 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+)
+
+// gitlabAPIBaseURL can be overridden in tests to point at a httptest server.
+// When empty, the API of the repository's host is used.
+var gitlabAPIBaseURL string
+
+const gitlabDefaultHost = "gitlab.com"
+
+// errGitLabNotFound is wrapped by gitlabGet errors for 404 responses.
+var errGitLabNotFound = errors.New("not found")
+
+// gitlabAPIBase returns the REST API root for a GitLab host.
+func gitlabAPIBase(scheme, host string) string {
+	if gitlabAPIBaseURL != "" {
+		return strings.TrimSuffix(gitlabAPIBaseURL, "/")
+	}
+	if host == "" {
+		host = gitlabDefaultHost
+	}
+	if scheme == "" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/api/v4", scheme, host)
+}
+
+// gitlabProjectPath returns the URL path of a project-scoped API resource.
+// GitLab identifies projects by their URL-encoded full path.
+func gitlabProjectPath(apiBase, project, resource string) string {
+	return fmt.Sprintf("%s/projects/%s/%s", apiBase, url.PathEscape(project), resource)
+}
+
+// IsGitLabFileURL reports whether path is a GitLab web URL of a single file,
+// i.e. contains a "/-/blob/" or "/-/raw/" segment.
+func IsGitLabFileURL(path string) bool {
+	return strings.Contains(path, "/-/blob/") || strings.Contains(path, "/-/raw/")
+}
+
+// gitlabFile identifies a file at a ref within a GitLab project.
+type gitlabFile struct {
+	scheme, host, project, ref, path string
+	// refPath is the "<ref>/<path>" part of the URL when the ref is taken from
+	// it. Branch names may contain slashes, so ref and path are only a guess
+	// that splits off the first segment; resolveGitLabFileRef settles it.
+	refPath string
+}
+
+// refCandidates returns the possible refs of the file, shortest first.
+func (f gitlabFile) refCandidates() []string {
+	if f.refPath == "" {
+		return []string{f.ref}
+	}
+	segments := strings.Split(f.refPath, "/")
+	refs := make([]string, 0, len(segments)-1)
+	for i := 1; i < len(segments); i++ {
+		refs = append(refs, strings.Join(segments[:i], "/"))
+	}
+	return refs
+}
+
+// parseGitLabFileURL parses a GitLab file URL such as
+// https://gitlab.example.com/group/project/-/blob/main/docs/guide.md.
+// A Tag or Commit version overrides the ref in the URL.
+func parseGitLabFileURL(rawURL string, version *osdd.GitVersion) (gitlabFile, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return gitlabFile{}, fmt.Errorf("invalid gitlab url %q: %w", rawURL, err)
+	}
+	project, rest, ok := strings.Cut(strings.Trim(u.Path, "/"), "/-/")
+	if !ok || project == "" {
+		return gitlabFile{}, fmt.Errorf("invalid gitlab file url: %s", rawURL)
+	}
+	// rest is "blob|raw/<ref>/<path>"
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) < 3 || (parts[0] != "blob" && parts[0] != "raw") || parts[1] == "" || parts[2] == "" {
+		return gitlabFile{}, fmt.Errorf("invalid gitlab file url: %s", rawURL)
+	}
+	file := gitlabFile{scheme: u.Scheme, host: u.Host, project: project, ref: parts[1], path: parts[2], refPath: parts[1] + "/" + parts[2]}
+	if version != nil && version.HasType() {
+		switch version.WhichType() {
+		case osdd.GitVersion_Tag_case:
+			file.ref, file.refPath = version.GetTag(), ""
+		case osdd.GitVersion_Commit_case:
+			file.ref, file.refPath = version.GetCommit(), ""
+		}
+	}
+	return file, nil
+}
+
+// FetchGitLabFile fetches a single file referenced by a GitLab file URL through
+// the repository files API of the URL's host. The token, when set, is sent as
+// a private token so files in private projects can be read.
+func FetchGitLabFile(ctx context.Context, ref *osdd.GitReference, token string) (string, error) {
+	if ref == nil {
+		return "", fmt.Errorf("gitlab reference cannot be nil")
+	}
+	if ref.GetPath() == "" {
+		return "", fmt.Errorf("gitlab path cannot be empty")
+	}
+	file, err := parseGitLabFileURL(ref.GetPath(), ref.GetVersion())
+	if err != nil {
+		return "", err
+	}
+
+	body, err := fetchGitLabFileAt(ctx, file, token)
+	if errors.Is(err, errGitLabNotFound) && len(file.refCandidates()) > 1 {
+		// The first path segment may be only part of a slashed branch name.
+		resolved, _, rerr := resolveGitLabFileRef(ctx, file, token)
+		if rerr != nil {
+			return "", rerr
+		}
+		if resolved.ref != file.ref {
+			file = resolved
+			body, err = fetchGitLabFileAt(ctx, file, token)
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s from gitlab %s@%s: %w", file.path, file.project, file.ref, err)
+	}
+	return string(body), nil
+}
+
+func fetchGitLabFileAt(ctx context.Context, file gitlabFile, token string) ([]byte, error) {
+	apiURL := gitlabProjectPath(gitlabAPIBase(file.scheme, file.host), file.project,
+		"repository/files/"+url.PathEscape(file.path)+"/raw?ref="+url.QueryEscape(file.ref))
+	body, _, err := gitlabGet(ctx, apiURL, token)
+	return body, err
+}
+
+// resolveGitLabFileRef looks up the ref candidates of file, shortest first,
+// and returns the file split at the first one naming a branch, tag or commit
+// of the project, along with its commit SHA.
+func resolveGitLabFileRef(ctx context.Context, file gitlabFile, token string) (gitlabFile, string, error) {
+	for _, candidate := range file.refCandidates() {
+		apiURL := gitlabProjectPath(gitlabAPIBase(file.scheme, file.host), file.project,
+			"repository/commits/"+url.PathEscape(candidate))
+		body, _, err := gitlabGet(ctx, apiURL, token)
+		if errors.Is(err, errGitLabNotFound) {
+			continue
+		}
+		if err != nil {
+			return file, "", fmt.Errorf("failed to resolve %s of gitlab %s: %w", candidate, file.project, err)
+		}
+		var commit struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(body, &commit); err != nil {
+			return file, "", fmt.Errorf("failed to parse GitLab commit: %w", err)
+		}
+		if file.refPath != "" {
+			file.ref, file.path = candidate, strings.TrimPrefix(file.refPath, candidate+"/")
+		}
+		return file, commit.ID, nil
+	}
+	want := file.ref
+	if file.refPath != "" {
+		want = file.refPath
+	}
+	return file, "", fmt.Errorf("no branch, tag or commit of gitlab %s matches %s", file.project, want)
+}
+
+// resolveGitLabRevision returns the commit SHA of the ref a GitLab file URL
+// points to.
+func resolveGitLabRevision(ctx context.Context, ref *osdd.GitReference, token string) (string, error) {
+	file, err := parseGitLabFileURL(ref.GetPath(), ref.GetVersion())
+	if err != nil {
+		return "", err
+	}
+	if isFullCommitSHA(file.ref) {
+		return file.ref, nil
+	}
+	_, sha, err := resolveGitLabFileRef(ctx, file, token)
+	return sha, err
+}
+
+// GitLab API response types.
+
+type gitlabUser struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+func (u *gitlabUser) display() string {
+	if u == nil {
+		return ""
+	}
+	name := u.Name
+	if u.Username != "" && u.Username != name {
+		name += " (" + u.Username + ")"
+	}
+	return name
+}
+
+type gitlabMR struct {
+	IID          int         `json:"iid"`
+	Title        string      `json:"title"`
+	Description  string      `json:"description"`
+	State        string      `json:"state"`
+	WebURL       string      `json:"web_url"`
+	SourceBranch string      `json:"source_branch"`
+	TargetBranch string      `json:"target_branch"`
+	Labels       []string    `json:"labels"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+	MergedAt     *time.Time  `json:"merged_at"`
+	Author       gitlabUser  `json:"author"`
+	MergedBy     *gitlabUser `json:"merged_by"`
+}
+
+type gitlabDiscussion struct {
+	Notes []gitlabNote `json:"notes"`
+}
+
+type gitlabNote struct {
+	Body     string     `json:"body"`
+	System   bool       `json:"system"`
+	Author   gitlabUser `json:"author"`
+	Position *struct {
+		NewPath string `json:"new_path"`
+		NewLine int    `json:"new_line"`
+	} `json:"position"`
+}
+
+type gitlabDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+type gitlabCommit struct {
+	AuthorEmail string `json:"author_email"`
+}
+
+// fetchGitLabMRs fetches merge requests from the GitLab REST API of host,
+// including discussions and diffs, filtered by the given date range.
+func fetchGitLabMRs(ctx context.Context, host, project, token string, dateFilter *osdd.DatesFilter, summaryOnly bool) (prFetchResult, error) {
+	baseURL := gitlabAPIBase("", host)
+	sinceTime, untilTime := resolvePRDateRange(dateFilter)
+
+	// Phase 1: collect MR metadata. MRs created in range were also updated
+	// after sinceTime, so updated_after bounds the listing.
+	listURL := gitlabProjectPath(baseURL, project, "merge_requests") +
+		"?state=all&scope=all&order_by=updated_at&sort=desc&updated_after=" + url.QueryEscape(sinceTime.Format(time.RFC3339))
+	mrs, err := gitlabGetAll[gitlabMR](ctx, listURL, token)
+	if err != nil {
+		return prFetchResult{}, fmt.Errorf("failed to list GitLab merge requests: %w", err)
+	}
+
+	var allPRs []pullRequest
+	for _, mr := range mrs {
+		if !isInDateRange(mr.CreatedAt, mr.UpdatedAt, sinceTime, untilTime) {
+			continue
+		}
+		state := mr.State
+		if state == "opened" {
+			state = "open"
+		}
+		p := pullRequest{
+			Number:     mr.IID,
+			Title:      mr.Title,
+			URL:        mr.WebURL,
+			Author:     mr.Author.display(),
+			State:      state,
+			BaseBranch: mr.TargetBranch,
+			HeadBranch: mr.SourceBranch,
+			Labels:     mr.Labels,
+			CreatedAt:  mr.CreatedAt,
+			UpdatedAt:  mr.UpdatedAt,
+			Body:       mr.Description,
+			MergedBy:   mr.MergedBy.display(),
+		}
+		if mr.MergedAt != nil {
+			p.MergedAt = *mr.MergedAt
+		}
+		allPRs = append(allPRs, p)
+	}
+
+	// Phase 2: fetch diffs, discussions, and resolve author emails from commits.
+	fetchPRDetails(ctx, allPRs, func(ctx context.Context, pr *pullRequest) {
+		mrURL := gitlabProjectPath(baseURL, project, "merge_requests/"+strconv.Itoa(pr.Number))
+
+		pr.AuthorEmail = resolveEmailFromGitLabCommits(ctx, mrURL, token)
+
+		diffs, err := gitlabGetAll[gitlabDiff](ctx, mrURL+"/diffs?", token)
+		if err != nil {
+			slog.Warn("Failed to fetch diffs for GitLab merge request", "iid", pr.Number, "error", err)
+		} else {
+			for _, d := range diffs {
+				adds, dels := countDiffLines(d.Diff)
+				pr.Additions += adds
+				pr.Deletions += dels
+			}
+			pr.ChangedFiles = len(diffs)
+			if !summaryOnly {
+				pr.Diff = formatGitLabDiffs(diffs)
+			}
+		}
+
+		if summaryOnly {
+			return
+		}
+
+		discussions, err := gitlabGetAll[gitlabDiscussion](ctx, mrURL+"/discussions?", token)
+		if err != nil {
+			slog.Warn("Failed to fetch discussions for GitLab merge request", "iid", pr.Number, "error", err)
+			return
+		}
+		for _, d := range discussions {
+			for _, n := range d.Notes {
+				if n.System {
+					continue
+				}
+				body := n.Body
+				if n.Position != nil && n.Position.NewPath != "" {
+					body = fmt.Sprintf("`%s:%d` %s", n.Position.NewPath, n.Position.NewLine, body)
+				}
+				pr.Reviews = append(pr.Reviews, prReview{
+					Author: n.Author.display(),
+					State:  "COMMENTED",
+					Body:   body,
+				})
+			}
+		}
+	})
+
+	slog.Debug("GitLab merge requests fetched", "count", len(allPRs))
+	return prFetchResult{PRs: allPRs}, nil
+}
+
+// resolveEmailFromGitLabCommits returns the author email of the first commit of a merge request.
+func resolveEmailFromGitLabCommits(ctx context.Context, mrURL, token string) string {
+	body, _, err := gitlabGet(ctx, mrURL+"/commits?per_page=1", token)
+	if err != nil {
+		slog.Debug("Failed to fetch GitLab merge request commits", "url", mrURL, "error", err)
+		return ""
+	}
+	var commits []gitlabCommit
+	if err := json.Unmarshal(body, &commits); err != nil {
+		slog.Debug("Failed to parse GitLab merge request commits", "url", mrURL, "error", err)
+		return ""
+	}
+	for _, c := range commits {
+		if c.AuthorEmail != "" {
+			return c.AuthorEmail
+		}
+	}
+	return ""
+}
+
+// formatGitLabDiffs joins per-file GitLab diffs into a single unified diff.
+func formatGitLabDiffs(diffs []gitlabDiff) string {
+	var b strings.Builder
+	for _, d := range diffs {
+		oldPath, newPath := "a/"+d.OldPath, "b/"+d.NewPath
+		if d.NewFile {
+			oldPath = "/dev/null"
+		}
+		if d.DeletedFile {
+			newPath = "/dev/null"
+		}
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- %s\n+++ %s\n", d.OldPath, d.NewPath, oldPath, newPath)
+		b.WriteString(d.Diff)
+		if d.Diff != "" && !strings.HasSuffix(d.Diff, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// countDiffLines counts added and removed lines in the hunks of a single-file
+// diff without file headers.
+func countDiffLines(diff string) (additions, deletions int) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			additions++
+		case strings.HasPrefix(line, "-"):
+			deletions++
+		}
+	}
+	return additions, deletions
+}
+
+// gitlabGetAll fetches every page of a GitLab list endpoint. apiURL must
+// already contain a query string (possibly empty, ending in "?").
+func gitlabGetAll[T any](ctx context.Context, apiURL, token string) ([]T, error) {
+	sep := "&"
+	if strings.HasSuffix(apiURL, "?") {
+		sep = ""
+	}
+	var all []T
+	for page := "1"; page != ""; {
+		body, header, err := gitlabGet(ctx, apiURL+sep+"per_page=100&page="+page, token)
+		if err != nil {
+			return nil, err
+		}
+		var items []T
+		if err := json.Unmarshal(body, &items); err != nil {
+			return nil, fmt.Errorf("failed to parse GitLab response: %w", err)
+		}
+		all = append(all, items...)
+		page = header.Get("X-Next-Page")
+	}
+	return all, nil
+}
+
+func gitlabGet(ctx context.Context, apiURL, token string) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := readAllWithProgress(ctx, resp.Body, resp.ContentLength)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil, fmt.Errorf("gitlab API returned status %d: %s: %w", resp.StatusCode, truncateBody(body), errGitLabNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("gitlab API returned status %d: %s", resp.StatusCode, truncateBody(body))
+	}
+
+	return body, resp.Header, nil
+}
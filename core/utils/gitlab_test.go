@@ -0,0 +1,267 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// withGitLabServer serves handlers keyed by escaped request path, since
+// GitLab project ids contain encoded slashes.
+func withGitLabServer(t *testing.T, handlers map[string]http.HandlerFunc) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := handlers[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		h(w, r)
+	}))
+	t.Cleanup(server.Close)
+	old := gitlabAPIBaseURL
+	gitlabAPIBaseURL = server.URL
+	t.Cleanup(func() { gitlabAPIBaseURL = old })
+	return server.URL
+}
+
+func writeGitLabJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestFetchGitLabMRs_Success(t *testing.T) {
+	mergedAt := time.Date(2025, 6, 17, 0, 0, 0, 0, time.UTC)
+	withGitLabServer(t, map[string]http.HandlerFunc{
+		"/projects/group%2Frepo/merge_requests": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "gl-token", r.Header.Get("PRIVATE-TOKEN"))
+			assert.Equal(t, "all", r.URL.Query().Get("state"))
+			writeGitLabJSON(w, []gitlabMR{{
+				IID:          7,
+				Title:        "Add feature",
+				Description:  "Feature description",
+				State:        "merged",
+				WebURL:       "https://gitlab.example.com/group/repo/-/merge_requests/7",
+				SourceBranch: "feature",
+				TargetBranch: "main",
+				Labels:       []string{"backend"},
+				CreatedAt:    time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC),
+				UpdatedAt:    time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC),
+				MergedAt:     &mergedAt,
+				Author:       gitlabUser{Username: "alice", Name: "Alice"},
+				MergedBy:     &gitlabUser{Username: "bob", Name: "Bob"},
+			}})
+		},
+		"/projects/group%2Frepo/merge_requests/7/commits": func(w http.ResponseWriter, r *http.Request) {
+			writeGitLabJSON(w, []gitlabCommit{{AuthorEmail: "alice@example.com"}})
+		},
+		"/projects/group%2Frepo/merge_requests/7/diffs": func(w http.ResponseWriter, r *http.Request) {
+			writeGitLabJSON(w, []gitlabDiff{
+				{OldPath: "main.go", NewPath: "main.go", Diff: "@@ -1,2 +1,2 @@\n-old line\n+new line\n context\n"},
+				{OldPath: "new.go", NewPath: "new.go", NewFile: true, Diff: "@@ -0,0 +1 @@\n+package main\n"},
+			})
+		},
+		"/projects/group%2Frepo/merge_requests/7/discussions": func(w http.ResponseWriter, r *http.Request) {
+			writeGitLabJSON(w, []gitlabDiscussion{
+				{Notes: []gitlabNote{
+					{Body: "approved this merge request", System: true, Author: gitlabUser{Name: "Bob"}},
+					{Body: "Looks good", Author: gitlabUser{Username: "bob", Name: "Bob"}},
+				}},
+			})
+		},
+	})
+
+	df := osdd.DatesFilter_builder{
+		From: timestamppb.New(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)),
+		To:   timestamppb.New(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)),
+	}.Build()
+
+	result, err := fetchGitLabMRs(t.Context(), "gitlab.example.com", "group/repo", "gl-token", df, false)
+	require.NoError(t, err)
+	require.Len(t, result.PRs, 1)
+
+	pr := result.PRs[0]
+	assert.Equal(t, 7, pr.Number)
+	assert.Equal(t, "Add feature", pr.Title)
+	assert.Equal(t, "Alice (alice)", pr.Author)
+	assert.Equal(t, "alice@example.com", pr.AuthorEmail)
+	assert.Equal(t, "Bob (bob)", pr.MergedBy)
+	assert.Equal(t, "merged", pr.State)
+	assert.Equal(t, "main", pr.BaseBranch)
+	assert.Equal(t, "feature", pr.HeadBranch)
+	assert.Equal(t, []string{"backend"}, pr.Labels)
+	assert.Equal(t, mergedAt, pr.MergedAt)
+	assert.Equal(t, 2, pr.Additions)
+	assert.Equal(t, 1, pr.Deletions)
+	assert.Equal(t, 2, pr.ChangedFiles)
+	assert.Contains(t, pr.Diff, "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@")
+	assert.Contains(t, pr.Diff, "--- /dev/null\n+++ b/new.go")
+
+	require.Len(t, pr.Reviews, 1)
+	assert.Equal(t, "Bob (bob)", pr.Reviews[0].Author)
+	assert.Equal(t, "COMMENTED", pr.Reviews[0].State)
+	assert.Equal(t, "Looks good", pr.Reviews[0].Body)
+}
+
+func TestFetchGitLabMRs_DateFilteringAndPagination(t *testing.T) {
+	pages := 0
+	withGitLabServer(t, map[string]http.HandlerFunc{
+		"/projects/group%2Frepo/merge_requests": func(w http.ResponseWriter, r *http.Request) {
+			pages++
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				writeGitLabJSON(w, []gitlabMR{{
+					IID: 1, Title: "In range", State: "opened",
+					CreatedAt: time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC),
+				}})
+				return
+			}
+			writeGitLabJSON(w, []gitlabMR{{
+				IID: 2, Title: "Out of range", State: "closed",
+				CreatedAt: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC),
+			}})
+		},
+		"/projects/group%2Frepo/merge_requests/1/commits": func(w http.ResponseWriter, r *http.Request) {
+			writeGitLabJSON(w, []gitlabCommit{})
+		},
+		"/projects/group%2Frepo/merge_requests/1/diffs": func(w http.ResponseWriter, r *http.Request) {
+			writeGitLabJSON(w, []gitlabDiff{})
+		},
+	})
+
+	df := osdd.DatesFilter_builder{
+		From: timestamppb.New(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)),
+		To:   timestamppb.New(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)),
+	}.Build()
+
+	result, err := fetchGitLabMRs(t.Context(), "", "group/repo", "", df, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, pages)
+	require.Len(t, result.PRs, 1)
+	assert.Equal(t, "In range", result.PRs[0].Title)
+	assert.Equal(t, "open", result.PRs[0].State)
+	assert.Empty(t, result.PRs[0].Reviews)
+}
+
+func TestFetchGitLabMRs_ListError(t *testing.T) {
+	withGitLabServer(t, map[string]http.HandlerFunc{})
+
+	_, err := fetchGitLabMRs(t.Context(), "", "group/repo", "", nil, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gitlab API returned status 404")
+}
+
+func TestFetchPRs_GitLabProvider(t *testing.T) {
+	withGitLabServer(t, map[string]http.HandlerFunc{
+		"/projects/group%2Fsub%2Frepo/merge_requests": func(w http.ResponseWriter, r *http.Request) {
+			writeGitLabJSON(w, []gitlabMR{})
+		},
+	})
+
+	result, err := fetchPRs(t.Context(), "gitlab:git.example.com", "group/sub/repo", "", nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, result.PRs)
+}
+
+func TestFetchGitLabFile(t *testing.T) {
+	withGitLabServer(t, map[string]http.HandlerFunc{
+		"/projects/group%2Frepo/repository/files/docs%2Fguide.md/raw": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "gl-token", r.Header.Get("PRIVATE-TOKEN"))
+			assert.Equal(t, "v1.2", r.URL.Query().Get("ref"))
+			_, _ = w.Write([]byte("# Guide"))
+		},
+	})
+
+	ref := osdd.GitReference_builder{Path: "https://gitlab.example.com/group/repo/-/blob/v1.2/docs/guide.md"}.Build()
+	content, err := FetchGitFile(t.Context(), ref, GitFileTokens{GitLab: "gl-token"})
+	require.NoError(t, err)
+	assert.Equal(t, "# Guide", content)
+}
+
+func TestFetchGitLabFile_SlashedBranch(t *testing.T) {
+	withGitLabServer(t, map[string]http.HandlerFunc{
+		"/projects/group%2Frepo/repository/commits/feature%2Fx": func(w http.ResponseWriter, r *http.Request) {
+			writeGitLabJSON(w, map[string]string{"id": "0123abcd"})
+		},
+		"/projects/group%2Frepo/repository/files/docs%2Fguide.md/raw": func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("ref") != "feature/x" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte("# Guide"))
+		},
+	})
+
+	ref := osdd.GitReference_builder{Path: "https://gitlab.example.com/group/repo/-/blob/feature/x/docs/guide.md"}.Build()
+	content, err := FetchGitFile(t.Context(), ref, GitFileTokens{})
+	require.NoError(t, err)
+	assert.Equal(t, "# Guide", content)
+
+	sha, err := ResolveGitFileRevision(t.Context(), ref, GitFileTokens{})
+	require.NoError(t, err)
+	assert.Equal(t, "0123abcd", sha)
+
+	missing := osdd.GitReference_builder{Path: "https://gitlab.example.com/group/repo/-/blob/nope/docs/guide.md"}.Build()
+	_, err = FetchGitFile(t.Context(), missing, GitFileTokens{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no branch, tag or commit of gitlab group/repo matches nope/docs/guide.md")
+}
+
+func TestParseGitLabFileURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		url     string
+		version *osdd.GitVersion
+		want    gitlabFile
+		wantErr bool
+	}{
+		{
+			name: "blob url",
+			url:  "https://gitlab.example.com/group/sub/repo/-/blob/main/README.md",
+			want: gitlabFile{scheme: "https", host: "gitlab.example.com", project: "group/sub/repo", ref: "main", path: "README.md", refPath: "main/README.md"},
+		},
+		{
+			name: "raw url without scheme",
+			url:  "gitlab.com/group/repo/-/raw/dev/docs/a.md",
+			want: gitlabFile{scheme: "https", host: "gitlab.com", project: "group/repo", ref: "dev", path: "docs/a.md", refPath: "dev/docs/a.md"},
+		},
+		{
+			name:    "version overrides ref",
+			url:     "https://gitlab.com/group/repo/-/blob/main/a.md",
+			version: osdd.GitVersion_builder{Commit: strPtr("abc123")}.Build(),
+			want:    gitlabFile{scheme: "https", host: "gitlab.com", project: "group/repo", ref: "abc123", path: "a.md"},
+		},
+		{
+			name:    "missing file path",
+			url:     "https://gitlab.com/group/repo/-/blob/main",
+			wantErr: true,
+		},
+		{
+			name:    "not a file url",
+			url:     "https://gitlab.com/group/repo/-/merge_requests/1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseGitLabFileURL(tt.url, tt.version)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
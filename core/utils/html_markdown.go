@@ -0,0 +1,445 @@
+package utils
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// IsHTMLContentType reports whether a response with the given Content-Type
+// header is an HTML document. When the header is empty the content is sniffed.
+func IsHTMLContentType(contentType string, content []byte) bool {
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// MarkdownPath returns the path under which Markdown converted from the
+// document at path is written: HTML extensions are replaced with ".md",
+// Markdown paths are kept and ".md" is appended to anything else.
+func MarkdownPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return path
+	case ".html", ".htm", ".xhtml":
+		return strings.TrimSuffix(path, filepath.Ext(path)) + ".md"
+	default:
+		return path + ".md"
+	}
+}
+
+// HTMLToMarkdown converts an HTML document to Markdown. Headings, paragraphs,
+// lists, tables, code blocks, block quotes, links and images are kept; scripts,
+// styles, forms and navigation chrome (nav, aside, page headers and footers)
+// are dropped. Only the <main> element is converted when the page has one.
+// Relative links are resolved against baseURL when it is set.
+func HTMLToMarkdown(content, baseURL string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse html: %w", err)
+	}
+	c := &mdConverter{}
+	if baseURL != "" {
+		if u, err := url.Parse(baseURL); err == nil {
+			c.base = u
+		}
+	}
+
+	root := findElement(doc, atom.Main)
+	if root == nil {
+		root = findElement(doc, atom.Body)
+	}
+	if root == nil {
+		root = doc
+	}
+	blocks := c.blocks(root)
+
+	// Use the document title as the heading when the content has none.
+	if title := findElement(doc, atom.Title); title != nil && findElement(root, atom.H1) == nil {
+		if t := collapseSpace(textContent(title)); t != "" {
+			blocks = append([]string{"# " + t}, blocks...)
+		}
+	}
+	if len(blocks) == 0 {
+		return "", nil
+	}
+	return strings.Join(blocks, "\n\n") + "\n", nil
+}
+
+type mdConverter struct {
+	base *url.URL
+}
+
+// blocks converts the children of n into Markdown blocks, grouping runs of
+// inline content into paragraphs.
+func (c *mdConverter) blocks(n *html.Node) []string {
+	var out []string
+	var inline strings.Builder
+	flush := func() {
+		if p := normalizeInline(inline.String()); p != "" {
+			out = append(out, p)
+		}
+		inline.Reset()
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if skipNode(child) {
+			continue
+		}
+		if child.Type == html.ElementNode && isBlockElement(child.DataAtom) {
+			flush()
+			out = append(out, c.block(child)...)
+			continue
+		}
+		inline.WriteString(c.inline(child))
+	}
+	flush()
+	return out
+}
+
+func (c *mdConverter) block(n *html.Node) []string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := strings.ReplaceAll(normalizeInline(c.inlineChildren(n)), "\n", " ")
+		if text == "" {
+			return nil
+		}
+		level := int(n.Data[1] - '0')
+		return []string{strings.Repeat("#", level) + " " + text}
+	case atom.P, atom.Dt, atom.Summary, atom.Figcaption:
+		if p := normalizeInline(c.inlineChildren(n)); p != "" {
+			return []string{p}
+		}
+		return nil
+	case atom.Hr:
+		return []string{"---"}
+	case atom.Pre:
+		return []string{c.codeBlock(n)}
+	case atom.Ul, atom.Ol:
+		if list := c.list(n); list != "" {
+			return []string{list}
+		}
+		return nil
+	case atom.Blockquote:
+		inner := c.blocks(n)
+		if len(inner) == 0 {
+			return nil
+		}
+		lines := strings.Split(strings.Join(inner, "\n\n"), "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimRight("> "+l, " ")
+		}
+		return []string{strings.Join(lines, "\n")}
+	case atom.Table:
+		if table := c.table(n); table != "" {
+			return []string{table}
+		}
+		return nil
+	default:
+		return c.blocks(n)
+	}
+}
+
+func (c *mdConverter) inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if !skipNode(child) {
+			b.WriteString(c.inline(child))
+		}
+	}
+	return b.String()
+}
+
+func (c *mdConverter) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return n.Data
+	case html.ElementNode:
+	default:
+		return ""
+	}
+	switch n.DataAtom {
+	case atom.Br:
+		return lineBreak
+	case atom.Strong, atom.B:
+		return wrapInline(c.inlineChildren(n), "**")
+	case atom.Em, atom.I:
+		return wrapInline(c.inlineChildren(n), "*")
+	case atom.Del, atom.S:
+		return wrapInline(c.inlineChildren(n), "~~")
+	case atom.Code, atom.Kbd, atom.Samp:
+		return inlineCode(textContent(n))
+	case atom.A:
+		text := collapseSpace(c.inlineChildren(n))
+		href := c.resolve(attr(n, "href"))
+		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			return text
+		}
+		if text == "" {
+			text = href
+		}
+		return "[" + text + "](" + href + ")"
+	case atom.Img:
+		src := c.resolve(attr(n, "src"))
+		if src == "" {
+			return ""
+		}
+		return "![" + collapseSpace(attr(n, "alt")) + "](" + src + ")"
+	default:
+		// Block content nested in inline context is flattened to text.
+		return c.inlineChildren(n)
+	}
+}
+
+func (c *mdConverter) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || c.base == nil || strings.HasPrefix(ref, "#") {
+		return ref
+	}
+	u, err := c.base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+func (c *mdConverter) codeBlock(pre *html.Node) string {
+	lang := codeLanguage(pre)
+	if code := findElement(pre, atom.Code); code != nil && lang == "" {
+		lang = codeLanguage(code)
+	}
+	text := strings.TrimSuffix(strings.TrimPrefix(textContent(pre), "\n"), "\n")
+	return strings.TrimSuffix(codeFence(text, lang), "\n")
+}
+
+// codeLanguage reads the language from "language-x" or "lang-x" classes.
+func codeLanguage(n *html.Node) string {
+	for _, class := range strings.Fields(attr(n, "class")) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if lang, ok := strings.CutPrefix(class, prefix); ok {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+func (c *mdConverter) list(n *html.Node) string {
+	var items []string
+	number := 1
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li || skipNode(li) {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+		body := strings.Join(c.blocks(li), "\n")
+		indent := strings.Repeat(" ", len(marker))
+		lines := strings.Split(body, "\n")
+		for i := 1; i < len(lines); i++ {
+			if lines[i] != "" {
+				lines[i] = indent + lines[i]
+			}
+		}
+		items = append(items, marker+strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, "\n")
+}
+
+func (c *mdConverter) table(n *html.Node) string {
+	var rows [][]string
+	var collect func(*html.Node)
+	collect = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				collect(child)
+			case atom.Tr:
+				var cells []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Th || cell.DataAtom == atom.Td) {
+						text := strings.ReplaceAll(normalizeInline(c.inlineChildren(cell)), "\n", " ")
+						cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+					}
+				}
+				rows = append(rows, cells)
+			}
+		}
+	}
+	collect(n)
+	if len(rows) == 0 {
+		return ""
+	}
+	cols := 0
+	for _, r := range rows {
+		cols = max(cols, len(r))
+	}
+	if cols == 0 {
+		return ""
+	}
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for i := 0; i < cols; i++ {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(rows[0])
+	b.WriteString("|" + strings.Repeat(" --- |", cols) + "\n")
+	for _, r := range rows[1:] {
+		writeRow(r)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// skipNode reports whether n is dropped from the output: comments, scripts,
+// styles, form controls, hidden elements and navigation chrome.
+func skipNode(n *html.Node) bool {
+	switch n.Type {
+	case html.TextNode:
+		return false
+	case html.ElementNode:
+	default:
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Head, atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Iframe,
+		atom.Svg, atom.Canvas, atom.Form, atom.Button, atom.Input, atom.Select, atom.Textarea,
+		atom.Nav, atom.Aside:
+		return true
+	case atom.Header, atom.Footer:
+		// Page headers and footers are chrome; those of an article are content.
+		return !hasAncestor(n, atom.Article)
+	}
+	if _, hidden := attrValue(n, "hidden"); hidden || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	switch attr(n, "role") {
+	case "navigation", "banner", "contentinfo", "complementary", "search":
+		return true
+	}
+	return false
+}
+
+func isBlockElement(a atom.Atom) bool {
+	switch a {
+	case atom.Address, atom.Article, atom.Aside, atom.Blockquote, atom.Body, atom.Dd, atom.Details,
+		atom.Div, atom.Dl, atom.Dt, atom.Fieldset, atom.Figcaption, atom.Figure, atom.Footer,
+		atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Header, atom.Hr, atom.Li,
+		atom.Main, atom.Nav, atom.Ol, atom.P, atom.Pre, atom.Section, atom.Summary, atom.Table,
+		atom.Ul, atom.Html:
+		return true
+	}
+	return false
+}
+
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findElement(child, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func hasAncestor(n *html.Node, a atom.Atom) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.DataAtom == a {
+			return true
+		}
+	}
+	return false
+}
+
+func attrValue(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func attr(n *html.Node, key string) string {
+	v, _ := attrValue(n, key)
+	return v
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// lineBreak marks a <br> in inline content. The HTML parser replaces NUL
+// characters in text, so it cannot clash with document content.
+const lineBreak = "\x00"
+
+// normalizeInline collapses whitespace in inline content and turns <br>
+// elements into newlines.
+func normalizeInline(s string) string {
+	lines := strings.Split(s, lineBreak)
+	kept := lines[:0]
+	for _, l := range lines {
+		if l = collapseSpace(l); l != "" {
+			kept = append(kept, l)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// wrapInline wraps s in marker, keeping surrounding whitespace outside the
+// markers so emphasis stays valid Markdown.
+func wrapInline(s, marker string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	lead := s[:strings.Index(s, trimmed)]
+	trail := s[len(lead)+len(trimmed):]
+	return lead + marker + collapseSpace(trimmed) + marker + trail
+}
+
+func inlineCode(s string) string {
+	s = collapseSpace(s)
+	if s == "" {
+		return ""
+	}
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
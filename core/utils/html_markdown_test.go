@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	t.Parallel()
+	page := `<!DOCTYPE html>
+<html>
+<head>
+  <title>Ignored title</title>
+  <style>body { color: red; }</style>
+  <script>console.log("x")</script>
+</head>
+<body>
+  <header><a href="/">Wiki home</a></header>
+  <nav><ul><li><a href="/a">Nav A</a></li></ul></nav>
+  <main>
+    <h1>Deploy <em>guide</em></h1>
+    <p>Read the <a href="runbook.html">runbook</a> and the <a href="#setup">setup</a> section.
+       Use <code>make deploy</code>.</p>
+    <h2>Steps</h2>
+    <ol>
+      <li>Build<ul><li>with <strong>Go</strong></li></ul></li>
+      <li>Ship</li>
+    </ol>
+    <pre><code class="language-sh">make build
+make deploy
+</code></pre>
+    <table>
+      <thead><tr><th>Env</th><th>URL</th></tr></thead>
+      <tbody><tr><td>prod</td><td>a|b</td></tr></tbody>
+    </table>
+    <blockquote><p>Be careful.</p></blockquote>
+    <img src="/img/arch.png" alt="Architecture">
+    <div hidden>secret</div>
+    <aside>Related pages</aside>
+  </main>
+  <footer>Copyright</footer>
+</body>
+</html>`
+
+	got, err := HTMLToMarkdown(page, "https://wiki.example.com/docs/deploy.html")
+	require.NoError(t, err)
+	assert.Equal(t, `# Deploy *guide*
+
+Read the [runbook](https://wiki.example.com/docs/runbook.html) and the setup section. Use `+"`make deploy`"+`.
+
+## Steps
+
+1. Build
+   - with **Go**
+2. Ship
+
+`+"```sh\nmake build\nmake deploy\n```"+`
+
+| Env | URL |
+| --- | --- |
+| prod | a\|b |
+
+> Be careful.
+
+![Architecture](https://wiki.example.com/img/arch.png)
+`, got)
+}
+
+func TestHTMLToMarkdown_TitleAndArticleHeader(t *testing.T) {
+	t.Parallel()
+	page := `<html><head><title>Release notes</title></head><body>
+<div role="navigation">menu</div>
+<article><header><p>Posted today</p></header><p>Line one<br>Line two</p></article>
+</body></html>`
+
+	got, err := HTMLToMarkdown(page, "")
+	require.NoError(t, err)
+	assert.Equal(t, "# Release notes\n\nPosted today\n\nLine one\nLine two\n", got)
+}
+
+func TestIsHTMLContentType(t *testing.T) {
+	t.Parallel()
+	assert.True(t, IsHTMLContentType("text/html; charset=utf-8", nil))
+	assert.True(t, IsHTMLContentType("application/xhtml+xml", nil))
+	assert.False(t, IsHTMLContentType("application/json", []byte("<html>")))
+	assert.True(t, IsHTMLContentType("", []byte("<!DOCTYPE html><html><body>x</body></html>")))
+	assert.False(t, IsHTMLContentType("", []byte("plain text")))
+}
+
+func TestMarkdownPath(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "docs/page.md", MarkdownPath("docs/page.html"))
+	assert.Equal(t, "docs/page.md", MarkdownPath("docs/page.HTM"))
+	assert.Equal(t, "docs/page.md", MarkdownPath("docs/page.md"))
+	assert.Equal(t, "docs/page.md", MarkdownPath("docs/page"))
+	assert.Equal(t, "docs/page.txt.md", MarkdownPath("docs/page.txt"))
+}
@@ -1,15 +1,62 @@
 package utils
 
+import "fmt"
+
 // IssueSummary holds the minimal identifier and title for an issue.
 type IssueSummary struct {
 	ID    string `json:"id"`
 	Title string `json:"title"`
 }
 
-// IssuesResult is the structured output from FetchJiraIssues / FetchLinearIssues.
+// IssueFormat selects how issue sources render each issue.
+type IssueFormat string
+
+const (
+	// IssueFormatJSON writes the issue as returned by the API, as indented JSON.
+	// It is the default.
+	IssueFormatJSON IssueFormat = "json"
+	// IssueFormatMarkdown writes a Markdown document with the issue fields,
+	// description and comments.
+	IssueFormatMarkdown IssueFormat = "markdown"
+)
+
+// Extension returns the file extension of issues rendered in format f.
+func (f IssueFormat) Extension() string {
+	if f == IssueFormatMarkdown {
+		return ".md"
+	}
+	return ".json"
+}
+
+func (f IssueFormat) validate() error {
+	switch f {
+	case "", IssueFormatJSON, IssueFormatMarkdown:
+		return nil
+	default:
+		return fmt.Errorf("unsupported issue format %q", f)
+	}
+}
+
+// IssueFile is an additional file produced alongside the issues, such as a
+// downloaded attachment.
+type IssueFile struct {
+	// Path is relative to the issues folder, e.g. "issues/PROJ-1/attachments/log.txt".
+	Path string
+	// Content holds the raw bytes; binary content is written as is and kept
+	// out of token counting and secret scanning (see core.IsBinaryContent).
+	Content string
+}
+
+// IssuesResult is the structured output from FetchJiraIssues, FetchLinearIssues
+// and FetchGitHubIssues.
 // Summary contains one entry per issue (id + title) for the index file.
-// Issues maps each issue ID to its full raw JSON content.
+// Issues maps each issue ID to its full content in Format.
 type IssuesResult struct {
 	Summary []IssueSummary
-	Issues  map[string]string // issueID → full JSON content
+	Issues  map[string]string // issueID → full content
+	Format  IssueFormat
+	Files   []IssueFile
+	// Folder holds the per-issue files, relative to the entry path. It
+	// defaults to "issues"; Linear projects and documents use their own.
+	Folder string
 }
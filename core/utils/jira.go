@@ -9,7 +9,11 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -21,6 +25,14 @@ var jiraBaseURL string
 const jiraMaxResults = 50
 const jiraMaxIssues = 1000
 
+// jiraDefaultFields are the issue fields requested when JiraOptions.Fields is
+// empty. They are the fields decoded into jiraIssueFields.
+var jiraDefaultFields = []string{"summary", "description", "status", "resolution", "assignee", "reporter", "creator", "created", "updated", "resolutiondate", "issuetype", "priority", "labels", "components", "fixVersions", "parent", "comment", "issuelinks"}
+
+// jiraKnownFields are all fields decoded into jiraIssueFields, including those
+// only requested by options.
+var jiraKnownFields = append(slices.Clone(jiraDefaultFields), "attachment")
+
 // jiraBulkEmailResponse is the response from GET /rest/api/3/user/email/bulk.
 type jiraBulkEmailResponse struct {
 	Values []struct {
@@ -44,8 +56,67 @@ type jiraSearchResponse struct {
 }
 
 type jiraIssue struct {
+	// ID is only reported with JiraOptions.DevelopmentInfo, which looks
+	// development info up by it.
+	ID     string          `json:"id,omitempty"`
 	Key    string          `json:"key"`
 	Fields jiraIssueFields `json:"fields"`
+	// CustomFields holds the requested fields that jiraIssueFields does not
+	// decode, keyed by their friendly name from JiraOptions.CustomFields or,
+	// when unmapped, by field id.
+	CustomFields map[string]json.RawMessage `json:"customFields,omitempty"`
+	// Changelog lists the issue's field changes, oldest first. It is only
+	// fetched with JiraOptions.Changelog.
+	Changelog []jiraHistory `json:"changelog,omitempty"`
+	// RemoteLinks and Development are only fetched with JiraOptions.RemoteLinks
+	// and JiraOptions.DevelopmentInfo.
+	RemoteLinks []jiraRemoteLink `json:"remoteLinks,omitempty"`
+	Development *jiraDevelopment `json:"development,omitempty"`
+}
+
+// UnmarshalJSON decodes the known fields into Fields and collects all other
+// non-null fields into CustomFields under their field id.
+func (i *jiraIssue) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ID           string                     `json:"id"`
+		Key          string                     `json:"key"`
+		Fields       json.RawMessage            `json:"fields"`
+		CustomFields map[string]json.RawMessage `json:"customFields"`
+		Changelog    []jiraHistory              `json:"changelog"`
+		RemoteLinks  []jiraRemoteLink           `json:"remoteLinks"`
+		Development  *jiraDevelopment           `json:"development"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*i = jiraIssue{
+		ID:           raw.ID,
+		Key:          raw.Key,
+		CustomFields: raw.CustomFields,
+		Changelog:    raw.Changelog,
+		RemoteLinks:  raw.RemoteLinks,
+		Development:  raw.Development,
+	}
+	if len(raw.Fields) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw.Fields, &i.Fields); err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(raw.Fields, &all); err != nil {
+		return err
+	}
+	for id, v := range all {
+		if slices.Contains(jiraKnownFields, id) || string(v) == "null" {
+			continue
+		}
+		if i.CustomFields == nil {
+			i.CustomFields = make(map[string]json.RawMessage)
+		}
+		i.CustomFields[id] = v
+	}
+	return nil
 }
 
 type jiraIssueFields struct {
@@ -66,6 +137,8 @@ type jiraIssueFields struct {
 	ResolutionDate string                `json:"resolutiondate"`
 	Parent         *jiraParent           `json:"parent"`
 	Comment        *jiraCommentContainer `json:"comment"`
+	Attachment     []jiraAttachment      `json:"attachment,omitempty"`
+	IssueLinks     []jiraIssueLink       `json:"issuelinks,omitempty"`
 }
 
 type jiraCommentContainer struct {
@@ -98,14 +171,194 @@ type jiraUser struct {
 	AccountID    string `json:"accountId,omitempty"`
 }
 
+// JiraOptions configures FetchJiraIssuesWithOptions beyond what the
+// JiraIssuesSource schema covers.
+type JiraOptions struct {
+	// Format selects the rendering of per-issue files. IssueFormatMarkdown
+	// converts descriptions and comments from Atlassian Document Format.
+	Format IssueFormat
+
+	// JQL replaces the query built from the source's projects and filter and
+	// from Filter. It is sent as-is and cannot be combined with Filter.
+	JQL string
+
+	// Filter narrows the generated query beyond projects and dates.
+	Filter JiraFilter
+
+	// Fields replaces the default list of requested issue fields. Fields that
+	// are not part of the default list are reported under "customFields".
+	Fields []string
+
+	// CustomFields maps custom field ids, e.g. "customfield_10016", to the
+	// friendly names they are reported under, e.g. "Story Points". Mapped
+	// fields are always requested.
+	CustomFields map[string]string
+
+	// MaxIssues caps the number of fetched issues. The default is 1000. A
+	// warning is logged when more issues match.
+	MaxIssues int
+
+	// Attachments, when set, downloads issue attachments to
+	// issues/<KEY>/attachments/ next to the issue files.
+	Attachments *JiraAttachmentOptions
+
+	// Changelog fetches the history of status, assignee and other field
+	// changes of every issue.
+	Changelog bool
+
+	// RemoteLinks fetches the web links of every issue, e.g. to Confluence
+	// pages or incidents. Links between issues are part of the default
+	// fields and need no option.
+	RemoteLinks bool
+	// DevelopmentInfo fetches the branches, commits and pull requests linked
+	// to every issue through the development panel. It relies on Jira's
+	// dev-status API, which requires PAT (email:token) authentication.
+	DevelopmentInfo bool
+	// DependencyGraph adds issues/dependency-graph.md, a Mermaid graph of the
+	// links between issues.
+	DependencyGraph bool
+}
+
+// JiraFilter holds structured issue filters. Values within a filter are
+// combined with OR, filters with AND. Values ending in "()" are passed as
+// JQL functions, e.g. "currentUser()" or "openSprints()".
+type JiraFilter struct {
+	// Statuses matches status names, e.g. "In Review".
+	Statuses []string
+	// StatusCategories matches "To Do", "In Progress" or "Done".
+	StatusCategories []string
+	// Assignees matches account ids or emails. "unassigned" matches issues
+	// without an assignee.
+	Assignees []string
+	Labels    []string
+	// IssueTypes matches issue type names, e.g. "Bug".
+	IssueTypes []string
+	// Sprints matches sprint ids or names.
+	Sprints []string
+	// Epics matches the keys of parent epics.
+	Epics []string
+}
+
+func (f JiraFilter) isZero() bool {
+	return len(f.Statuses) == 0 && len(f.StatusCategories) == 0 && len(f.Assignees) == 0 &&
+		len(f.Labels) == 0 && len(f.IssueTypes) == 0 && len(f.Sprints) == 0 && len(f.Epics) == 0
+}
+
+// clauses returns one JQL clause per set filter.
+func (f JiraFilter) clauses() []string {
+	var clauses []string
+	add := func(field string, values []string) {
+		if len(values) > 0 {
+			clauses = append(clauses, fmt.Sprintf("%s IN (%s)", field, jqlValues(values)))
+		}
+	}
+	add("status", f.Statuses)
+	add("statusCategory", f.StatusCategories)
+
+	var assignees []string
+	unassigned := false
+	for _, a := range f.Assignees {
+		if strings.EqualFold(a, "unassigned") {
+			unassigned = true
+		} else {
+			assignees = append(assignees, a)
+		}
+	}
+	switch {
+	case unassigned && len(assignees) > 0:
+		clauses = append(clauses, fmt.Sprintf("(assignee IN (%s) OR assignee IS EMPTY)", jqlValues(assignees)))
+	case unassigned:
+		clauses = append(clauses, "assignee IS EMPTY")
+	default:
+		add("assignee", assignees)
+	}
+
+	add("labels", f.Labels)
+	add("issuetype", f.IssueTypes)
+	add("sprint", f.Sprints)
+	add("parent", f.Epics)
+	return clauses
+}
+
+// jqlValues renders a comma-separated JQL value list, quoting all values but
+// functions and numbers.
+func jqlValues(values []string) string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		if strings.HasSuffix(v, "()") || isDigits(v) {
+			out[i] = v
+		} else {
+			out[i] = strconv.Quote(v)
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// fields returns the issue fields to request.
+func (o JiraOptions) fields() []string {
+	fields := jiraDefaultFields
+	if len(o.Fields) > 0 {
+		fields = o.Fields
+	}
+	fields = slices.Clone(fields)
+	if o.Attachments != nil && !slices.Contains(fields, "attachment") {
+		fields = append(fields, "attachment")
+	}
+	if o.DependencyGraph && !slices.Contains(fields, "issuelinks") {
+		fields = append(fields, "issuelinks")
+	}
+	ids := make([]string, 0, len(o.CustomFields))
+	for id := range o.CustomFields {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if !slices.Contains(fields, id) {
+			fields = append(fields, id)
+		}
+	}
+	return fields
+}
+
+func (o JiraOptions) maxIssues() int {
+	if o.MaxIssues > 0 {
+		return o.MaxIssues
+	}
+	return jiraMaxIssues
+}
+
 // FetchJiraIssues fetches issues from Jira Cloud using the REST API and returns
 // a structured IssuesResult containing a summary list and per-issue JSON.
 // The token is used as a Bearer token for OAuth authentication via the
 // Atlassian API gateway (https://api.atlassian.com/ex/jira/{siteId}).
 func FetchJiraIssues(ctx context.Context, src *recipes.JiraIssuesSource, token string) (*IssuesResult, error) {
+	return FetchJiraIssuesWithOptions(ctx, src, token, JiraOptions{})
+}
+
+// FetchJiraIssuesWithOptions behaves like FetchJiraIssues and renders issues
+// as configured by opts.
+func FetchJiraIssuesWithOptions(ctx context.Context, src *recipes.JiraIssuesSource, token string, opts JiraOptions) (*IssuesResult, error) {
 	if src == nil {
 		return nil, fmt.Errorf("jira issues source cannot be nil")
 	}
+	if err := opts.Format.validate(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(opts.JQL) != "" && !opts.Filter.isZero() {
+		return nil, fmt.Errorf("jira JQL cannot be combined with structured filters")
+	}
 
 	siteID := strings.TrimSpace(src.GetSiteId())
 	if siteID == "" {
@@ -120,16 +373,22 @@ func FetchJiraIssues(ctx context.Context, src *recipes.JiraIssuesSource, token s
 		baseURL = fmt.Sprintf("https://api.atlassian.com/ex/jira/%s", siteID)
 	}
 
-	jql := buildJQL(projects, src.GetFilter())
+	jql := strings.TrimSpace(opts.JQL)
+	if jql == "" {
+		jql = buildJQL(projects, src.GetFilter(), opts.Filter)
+	}
+	fields := opts.fields()
+	maxIssues := opts.maxIssues()
 
 	var allIssues []jiraIssue
 	nextPageToken := ""
+	truncated := false
 
 	for {
 		reqBody := jiraSearchRequest{
 			JQL:           jql,
-			MaxResults:    jiraMaxResults,
-			Fields:        []string{"summary", "description", "status", "resolution", "assignee", "reporter", "creator", "created", "updated", "resolutiondate", "issuetype", "priority", "labels", "components", "fixVersions", "parent", "comment"},
+			MaxResults:    min(jiraMaxResults, maxIssues-len(allIssues)),
+			Fields:        fields,
 			NextPageToken: nextPageToken,
 		}
 
@@ -145,15 +404,7 @@ func FetchJiraIssues(ctx context.Context, src *recipes.JiraIssuesSource, token s
 		}
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Accept", "application/json")
-		if token != "" {
-			if strings.Contains(token, ":") {
-				// PAT (email:token) → Basic Auth
-				req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(token)))
-			} else {
-				// OAuth token → Bearer Auth
-				req.Header.Set("Authorization", "Bearer "+token)
-			}
-		}
+		setJiraAuth(req, token)
 
 		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
@@ -178,13 +429,49 @@ func FetchJiraIssues(ctx context.Context, src *recipes.JiraIssuesSource, token s
 		allIssues = append(allIssues, searchResp.Issues...)
 		slog.Debug("Jira pagination", "issuesSoFar", len(allIssues))
 
-		if searchResp.NextPageToken == "" || len(allIssues) >= jiraMaxIssues {
+		if len(allIssues) >= maxIssues {
+			truncated = len(allIssues) > maxIssues || searchResp.NextPageToken != ""
+			allIssues = allIssues[:maxIssues]
+			break
+		}
+		if searchResp.NextPageToken == "" {
 			break
 		}
 		nextPageToken = searchResp.NextPageToken
 	}
 
+	if truncated {
+		slog.Warn("Jira issues truncated: more issues match the query than the configured maximum", "max", maxIssues, "jql", jql)
+	}
 	slog.Debug("Jira issues fetched", "count", len(allIssues))
+	renameJiraCustomFields(allIssues, opts.CustomFields)
+
+	forEachConcurrent(ctx, maxPRFetchConcurrency, allIssues, func(ctx context.Context, issue *jiraIssue) {
+		if err := fetchJiraRemainingComments(ctx, baseURL, token, issue); err != nil {
+			slog.Warn("Failed to fetch all Jira comments", "key", issue.Key, "err", err)
+		}
+		if opts.Changelog {
+			changelog, err := fetchJiraChangelog(ctx, baseURL, token, issue.Key)
+			if err != nil {
+				slog.Warn("Failed to fetch Jira changelog", "key", issue.Key, "err", err)
+			}
+			issue.Changelog = changelog
+		}
+		if opts.RemoteLinks {
+			links, err := fetchJiraRemoteLinks(ctx, baseURL, token, issue.Key)
+			if err != nil {
+				slog.Warn("Failed to fetch Jira remote links", "key", issue.Key, "err", err)
+			}
+			issue.RemoteLinks = links
+		}
+		if opts.DevelopmentInfo && issue.ID != "" {
+			dev, err := fetchJiraDevelopment(ctx, baseURL, token, issue.ID)
+			if err != nil {
+				slog.Warn("Failed to fetch Jira development info", "key", issue.Key, "err", err)
+			}
+			issue.Development = dev
+		}
+	})
 
 	// Enrich users with emails via the bulk email API (bypasses profile visibility).
 	if emails, err := fetchJiraEmailsBulk(ctx, baseURL, token, allIssues); err != nil {
@@ -196,12 +483,26 @@ func FetchJiraIssues(ctx context.Context, src *recipes.JiraIssuesSource, token s
 	result := &IssuesResult{
 		Summary: make([]IssueSummary, 0, len(allIssues)),
 		Issues:  make(map[string]string, len(allIssues)),
+		Format:  opts.Format,
+	}
+	if opts.Attachments != nil {
+		result.Files = downloadJiraAttachments(ctx, baseURL, token, allIssues, *opts.Attachments)
+	}
+	if opts.DependencyGraph {
+		result.Files = append(result.Files, IssueFile{Path: "issues/dependency-graph.md", Content: jiraDependencyGraph(allIssues)})
 	}
 	for _, issue := range allIssues {
 		result.Summary = append(result.Summary, IssueSummary{
 			ID:    issue.Key,
 			Title: issue.Fields.Summary,
 		})
+		if opts.Format == IssueFormatMarkdown {
+			result.Issues[issue.Key] = formatJiraIssueMarkdown(issue)
+			continue
+		}
+		if !opts.DevelopmentInfo {
+			issue.ID = ""
+		}
 		raw, err := json.MarshalIndent(issue, "", "  ")
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal jira issue %s: %w", issue.Key, err)
@@ -211,8 +512,272 @@ func FetchJiraIssues(ctx context.Context, src *recipes.JiraIssuesSource, token s
 	return result, nil
 }
 
+// jiraIssueTmplData holds the pre-rendered parts of a Jira issue for jiraIssueTmpl.
+type jiraIssueTmplData struct {
+	jiraIssue
+	Description string
+	Custom      []jiraCustomFieldTmplData
+	Comments    []jiraCommentTmplData
+	History     []jiraHistoryTmplData
+	Links       []string
+	// OmittedComments is the number of comments Jira did not return.
+	OmittedComments int
+}
+
+type jiraCustomFieldTmplData struct {
+	Name  string
+	Value string
+}
+
+type jiraHistoryTmplData struct {
+	Author  string
+	Created string
+	Changes []string
+}
+
+type jiraCommentTmplData struct {
+	Author  string
+	Created string
+	Body    string
+}
+
+var jiraIssueFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"user":  jiraUserDisplay,
+	"names": jiraNames,
+	"size":  formatByteSize,
+}
+
+var jiraIssueTmpl = template.Must(template.New("jira-issue").Funcs(jiraIssueFuncs).Parse(
+	`# {{.Key}}: {{.Fields.Summary}}
+
+**Type:** {{.Fields.IssueType.Name}}
+**Status:** {{.Fields.Status.Name}}
+{{- with .Fields.Resolution}}
+**Resolution:** {{.Name}}
+{{- end}}
+{{- if .Fields.Priority.Name}}
+**Priority:** {{.Fields.Priority.Name}}
+{{- end}}
+{{- with .Fields.Assignee}}
+**Assignee:** {{user .}}
+{{- end}}
+{{- with .Fields.Reporter}}
+**Reporter:** {{user .}}
+{{- end}}
+{{- with .Fields.Creator}}
+**Creator:** {{user .}}
+{{- end}}
+{{- if .Fields.Labels}}
+**Labels:** {{join .Fields.Labels ", "}}
+{{- end}}
+{{- if .Fields.Components}}
+**Components:** {{names .Fields.Components}}
+{{- end}}
+{{- if .Fields.FixVersions}}
+**Fix Versions:** {{names .Fields.FixVersions}}
+{{- end}}
+{{- with .Fields.Parent}}
+**Parent:** {{.Key}}{{if .Fields.Summary}}: {{.Fields.Summary}}{{end}}
+{{- end}}
+{{- if .Fields.Created}}
+**Created:** {{.Fields.Created}}
+{{- end}}
+{{- if .Fields.Updated}}
+**Updated:** {{.Fields.Updated}}
+{{- end}}
+{{- if .Fields.ResolutionDate}}
+**Resolved:** {{.Fields.ResolutionDate}}
+{{- end}}
+{{- range .Custom}}
+**{{.Name}}:** {{.Value}}
+{{- end}}
+{{if .Description}}
+## Description
+
+{{.Description}}
+{{end}}{{if .Comments}}
+## Comments
+{{range .Comments}}
+### {{.Author}}{{if .Created}} ({{.Created}}){{end}}
+
+{{.Body}}
+{{end}}{{if .OmittedComments}}
+_{{.OmittedComments}} more comment(s) not fetched._
+{{end}}{{end}}{{if .Links}}
+## Links
+
+{{range .Links}}- {{.}}
+{{end}}{{end}}{{with .Development}}
+## Development
+{{if .PullRequests}}
+### Pull Requests
+
+{{range .PullRequests}}- {{.}}
+{{end}}{{end}}{{if .Branches}}
+### Branches
+
+{{range .Branches}}- {{.}}
+{{end}}{{end}}{{if .Commits}}
+### Commits
+
+{{range .Commits}}- {{.}}
+{{end}}{{end}}{{end}}{{if .History}}
+## History
+{{range .History}}
+- {{.Created}} {{.Author}}:{{range .Changes}}
+  - {{.}}{{end}}{{end}}
+{{end}}{{if .Fields.Attachment}}
+## Attachments
+
+{{range .Fields.Attachment}}- {{if .LocalPath}}[{{.Filename}}]({{.LocalPath}}){{else}}{{.Filename}}{{end}} ({{.MimeType}}, {{size .Size}}{{if not .LocalPath}}, not downloaded{{end}})
+{{end}}{{end}}`))
+
+// formatJiraIssueMarkdown renders issue as a Markdown document, converting its
+// description and comments from Atlassian Document Format.
+func formatJiraIssueMarkdown(issue jiraIssue) string {
+	data := jiraIssueTmplData{
+		jiraIssue:   issue,
+		Description: jiraADFToMarkdown(issue.Key, issue.Fields.Description),
+	}
+	names := make([]string, 0, len(issue.CustomFields))
+	for name := range issue.CustomFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if v := jiraFieldValue(issue.CustomFields[name]); v != "" {
+			data.Custom = append(data.Custom, jiraCustomFieldTmplData{Name: name, Value: v})
+		}
+	}
+	if c := issue.Fields.Comment; c != nil {
+		for _, comment := range c.Comments {
+			author := "Unknown"
+			if comment.Author != nil {
+				author = jiraUserDisplay(comment.Author)
+			}
+			data.Comments = append(data.Comments, jiraCommentTmplData{
+				Author:  author,
+				Created: comment.Created,
+				Body:    jiraADFToMarkdown(issue.Key, comment.Body),
+			})
+		}
+		data.OmittedComments = max(c.Total-len(c.Comments), 0)
+	}
+	for _, l := range issue.Fields.IssueLinks {
+		data.Links = append(data.Links, l.String())
+	}
+	for _, l := range issue.RemoteLinks {
+		data.Links = append(data.Links, l.String())
+	}
+	for _, h := range issue.Changelog {
+		item := jiraHistoryTmplData{Author: "Unknown", Created: h.Created}
+		if h.Author != nil {
+			item.Author = jiraUserDisplay(h.Author)
+		}
+		for _, c := range h.Items {
+			item.Changes = append(item.Changes, c.String())
+		}
+		data.History = append(data.History, item)
+	}
+	var buf bytes.Buffer
+	if err := jiraIssueTmpl.Execute(&buf, data); err != nil {
+		slog.Warn("Failed to execute Jira issue template", "key", issue.Key, "err", err)
+		return ""
+	}
+	return buf.String()
+}
+
+// jiraADFToMarkdown converts rich text of issue key, falling back to the raw
+// JSON in a code fence when it is not valid ADF.
+func jiraADFToMarkdown(key string, raw json.RawMessage) string {
+	md, err := ADFToMarkdown(raw)
+	if err != nil {
+		slog.Warn("Failed to convert Jira rich text to Markdown", "key", key, "err", err)
+		return strings.TrimSuffix(codeFence(string(raw), "json"), "\n")
+	}
+	return md
+}
+
+// jiraFieldValue renders the value of a custom field on a single line: the
+// display name of objects such as users and select options, comma-separated
+// arrays, and Markdown for rich text.
+func jiraFieldValue(raw json.RawMessage) string {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return string(raw)
+	}
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []any:
+		var items []json.RawMessage
+		_ = json.Unmarshal(raw, &items)
+		out := make([]string, 0, len(items))
+		for _, item := range items {
+			if s := jiraFieldValue(item); s != "" {
+				out = append(out, s)
+			}
+		}
+		return strings.Join(out, ", ")
+	case map[string]any:
+		if val["type"] == "doc" {
+			if md, err := ADFToMarkdown(raw); err == nil {
+				return strings.ReplaceAll(md, "\n", " ")
+			}
+		}
+		for _, key := range []string{"displayName", "name", "value", "key"} {
+			if s, ok := val[key].(string); ok && s != "" {
+				return s
+			}
+		}
+		var b bytes.Buffer
+		if err := json.Compact(&b, raw); err == nil {
+			return b.String()
+		}
+		return string(raw)
+	default:
+		return string(raw)
+	}
+}
+
+func jiraUserDisplay(u *jiraUser) string {
+	if u == nil {
+		return ""
+	}
+	if u.EmailAddress != "" {
+		return u.DisplayName + " (" + u.EmailAddress + ")"
+	}
+	return u.DisplayName
+}
+
+func jiraNames(names []jiraName) string {
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = n.Name
+	}
+	return strings.Join(out, ", ")
+}
+
+// renameJiraCustomFields re-keys custom fields by their friendly names.
+func renameJiraCustomFields(issues []jiraIssue, names map[string]string) {
+	if len(names) == 0 {
+		return
+	}
+	for i := range issues {
+		for id, name := range names {
+			if v, ok := issues[i].CustomFields[id]; ok && name != "" {
+				delete(issues[i].CustomFields, id)
+				issues[i].CustomFields[name] = v
+			}
+		}
+	}
+}
+
 // buildJQL constructs a JQL query string from projects and filters.
-func buildJQL(projects []string, filter *recipes.IssuesFilter) string {
+func buildJQL(projects []string, filter *recipes.IssuesFilter, jiraFilter JiraFilter) string {
 	var clauses []string
 
 	if len(projects) > 0 {
@@ -244,6 +809,8 @@ func buildJQL(projects []string, filter *recipes.IssuesFilter) string {
 		}
 	}
 
+	clauses = append(clauses, jiraFilter.clauses()...)
+
 	if len(clauses) == 0 {
 		// Jira Cloud rejects unbounded JQL queries; default to recent issues.
 		return "created >= -30d ORDER BY created DESC"
@@ -259,6 +826,19 @@ func formatTimestampForJQL(ts *timestamppb.Timestamp) string {
 	return ts.AsTime().UTC().Format("2006-01-02")
 }
 
+// setJiraAuth authenticates req with token: a PAT (email:token) as Basic
+// Auth, anything else as an OAuth Bearer token.
+func setJiraAuth(req *http.Request, token string) {
+	if token == "" {
+		return
+	}
+	if strings.Contains(token, ":") {
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(token)))
+	} else {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
 const jiraBulkEmailBatchSize = 100
 
 // fetchJiraEmailsBulk calls GET /rest/api/3/user/email/bulk and returns a map
@@ -267,19 +847,11 @@ const jiraBulkEmailBatchSize = 100
 func fetchJiraEmailsBulk(ctx context.Context, baseURL, token string, issues []jiraIssue) (map[string]string, error) {
 	seen := make(map[string]struct{})
 	for i := range issues {
-		f := &issues[i].Fields
-		for _, u := range []*jiraUser{f.Assignee, f.Reporter, f.Creator} {
-			if u != nil && u.AccountID != "" {
+		for _, u := range issues[i].users() {
+			if u.AccountID != "" {
 				seen[u.AccountID] = struct{}{}
 			}
 		}
-		if f.Comment != nil {
-			for j := range f.Comment.Comments {
-				if a := f.Comment.Comments[j].Author; a != nil && a.AccountID != "" {
-					seen[a.AccountID] = struct{}{}
-				}
-			}
-		}
 	}
 
 	if len(seen) == 0 {
@@ -310,13 +882,7 @@ func fetchJiraEmailsBulk(ctx context.Context, baseURL, token string, issues []ji
 			return nil, fmt.Errorf("failed to create bulk email request: %w", err)
 		}
 		req.Header.Set("Accept", "application/json")
-		if token != "" {
-			if strings.Contains(token, ":") {
-				req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(token)))
-			} else {
-				req.Header.Set("Authorization", "Bearer "+token)
-			}
-		}
+		setJiraAuth(req, token)
 
 		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
@@ -351,22 +917,32 @@ func enrichJiraIssuesWithEmails(issues []jiraIssue, emails map[string]string) {
 	if len(emails) == 0 {
 		return
 	}
-	setEmail := func(u *jiraUser) {
-		if u != nil && u.EmailAddress == "" {
-			if e, ok := emails[u.AccountID]; ok {
-				u.EmailAddress = e
+	for i := range issues {
+		for _, u := range issues[i].users() {
+			if u.EmailAddress == "" {
+				if e, ok := emails[u.AccountID]; ok {
+					u.EmailAddress = e
+				}
 			}
 		}
 	}
-	for i := range issues {
-		f := &issues[i].Fields
-		setEmail(f.Assignee)
-		setEmail(f.Reporter)
-		setEmail(f.Creator)
-		if f.Comment != nil {
-			for j := range f.Comment.Comments {
-				setEmail(f.Comment.Comments[j].Author)
-			}
+}
+
+// users returns all users referenced by the issue: people, comment and
+// attachment authors, and changelog authors.
+func (i *jiraIssue) users() []*jiraUser {
+	f := &i.Fields
+	users := []*jiraUser{f.Assignee, f.Reporter, f.Creator}
+	if f.Comment != nil {
+		for j := range f.Comment.Comments {
+			users = append(users, f.Comment.Comments[j].Author)
 		}
 	}
+	for j := range f.Attachment {
+		users = append(users, f.Attachment[j].Author)
+	}
+	for j := range i.Changelog {
+		users = append(users, i.Changelog[j].Author)
+	}
+	return slices.DeleteFunc(users, func(u *jiraUser) bool { return u == nil })
 }
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// jiraDefaultMaxAttachmentBytes is the attachment size limit used when
+// JiraAttachmentOptions.MaxBytes is zero.
+const jiraDefaultMaxAttachmentBytes = 10 << 20
+
+// JiraAttachmentOptions selects the issue attachments that are downloaded.
+type JiraAttachmentOptions struct {
+	// MaxBytes skips attachments larger than this size. The default is 10 MiB.
+	MaxBytes int64
+	// MIMETypes lists the accepted MIME types, either exact ("application/pdf")
+	// or by top-level type ("image/*"). Empty accepts every type.
+	MIMETypes []string
+}
+
+func (o JiraAttachmentOptions) maxBytes() int64 {
+	if o.MaxBytes > 0 {
+		return o.MaxBytes
+	}
+	return jiraDefaultMaxAttachmentBytes
+}
+
+// skipReason returns why attachment a is not downloaded, or "" when it is.
+func (o JiraAttachmentOptions) skipReason(a jiraAttachment) string {
+	if a.Size > o.maxBytes() {
+		return "too large"
+	}
+	if len(o.MIMETypes) == 0 {
+		return ""
+	}
+	mimeType := strings.ToLower(strings.TrimSpace(strings.SplitN(a.MimeType, ";", 2)[0]))
+	for _, pattern := range o.MIMETypes {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mimeType, prefix+"/") {
+				return ""
+			}
+		} else if pattern == mimeType {
+			return ""
+		}
+	}
+	return "type not selected"
+}
+
+type jiraAttachment struct {
+	ID       string    `json:"id"`
+	Filename string    `json:"filename"`
+	MimeType string    `json:"mimeType"`
+	Size     int64     `json:"size"`
+	Created  string    `json:"created,omitempty"`
+	Author   *jiraUser `json:"author,omitempty"`
+	// LocalPath is the downloaded file relative to the issue file, e.g.
+	// "PROJ-1/attachments/log.txt". Empty when the attachment was not downloaded.
+	LocalPath string `json:"localPath,omitempty"`
+}
+
+// downloadJiraAttachments downloads the attachments of issues selected by
+// opts and records their local paths on the issues. Failed downloads are
+// logged and skipped.
+func downloadJiraAttachments(ctx context.Context, baseURL, token string, issues []jiraIssue, opts JiraAttachmentOptions) []IssueFile {
+	var files []IssueFile
+	for i := range issues {
+		issue := &issues[i]
+		used := map[string]bool{}
+		for j := range issue.Fields.Attachment {
+			a := &issue.Fields.Attachment[j]
+			if reason := opts.skipReason(*a); reason != "" {
+				slog.Debug("Skipping Jira attachment", "key", issue.Key, "file", a.Filename, "reason", reason)
+				continue
+			}
+			content, err := fetchJiraAttachment(ctx, baseURL, token, a.ID, opts.maxBytes())
+			if err != nil {
+				slog.Warn("Failed to download Jira attachment", "key", issue.Key, "file", a.Filename, "err", err)
+				continue
+			}
+
+			name := attachmentFileName(a.Filename, a.ID)
+			if used[name] {
+				name = a.ID + "-" + name
+			}
+			used[name] = true
+			a.LocalPath = issue.Key + "/attachments/" + name
+			files = append(files, IssueFile{Path: "issues/" + a.LocalPath, Content: string(content)})
+		}
+	}
+	return files
+}
+
+// attachmentFileName returns a file name safe to use as a single path
+// component, falling back to the attachment id.
+func attachmentFileName(filename, id string) string {
+	name := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	if name == "." || name == "/" || name == ".." || name == "" {
+		return id
+	}
+	return name
+}
+
+func fetchJiraAttachment(ctx context.Context, baseURL, token, id string, maxBytes int64) ([]byte, error) {
+	url := baseURL + "/rest/api/3/attachment/content/" + id
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attachment request: %w", err)
+	}
+	setJiraAuth(req, token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attachment: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Read one byte past the limit to detect attachments whose metadata
+	// understated their size.
+	body, err := readAllWithProgress(ctx, io.LimitReader(resp.Body, maxBytes+1), resp.ContentLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jira API returned status %d: %s", resp.StatusCode, truncateBody(body))
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("attachment exceeds %s", formatByteSize(maxBytes))
+	}
+	return body, nil
+}
+
+// formatByteSize formats n bytes with a binary unit, e.g. "1.5 KiB".
+func formatByteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
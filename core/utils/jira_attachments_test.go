@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withJiraAttachmentServer(t *testing.T, issues []jiraIssue, contents map[string]string) *[]string {
+	t.Helper()
+	var requested []string
+	var searchFields []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/3/search/jql", func(w http.ResponseWriter, r *http.Request) {
+		var req jiraSearchRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		searchFields = req.Fields
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(jiraSearchResponse{Issues: issues})
+	})
+	mux.HandleFunc("/rest/api/3/attachment/content/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer jira-token", r.Header.Get("Authorization"))
+		id := strings.TrimPrefix(r.URL.Path, "/rest/api/3/attachment/content/")
+		requested = append(requested, id)
+		content, ok := contents[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	old := jiraBaseURL
+	jiraBaseURL = server.URL
+	t.Cleanup(func() {
+		jiraBaseURL = old
+		assert.Contains(t, searchFields, "attachment")
+	})
+	return &requested
+}
+
+func TestFetchJiraIssues_Attachments(t *testing.T) {
+	issues := []jiraIssue{{
+		Key: "PROJ-1",
+		Fields: jiraIssueFields{
+			Summary: "With attachments",
+			Status:  jiraName{Name: "Open"},
+			Attachment: []jiraAttachment{
+				{ID: "10", Filename: "screenshot.png", MimeType: "image/png", Size: 3},
+				{ID: "11", Filename: "../../etc/app.log", MimeType: "text/plain", Size: 4},
+				{ID: "12", Filename: "spec.pdf", MimeType: "application/pdf", Size: 100},
+				{ID: "13", Filename: "video.mp4", MimeType: "video/mp4", Size: 2},
+				{ID: "14", Filename: "screenshot.png", MimeType: "image/png", Size: 3},
+				{ID: "15", Filename: "gone.txt", MimeType: "text/plain", Size: 1},
+			},
+		},
+	}}
+	requested := withJiraAttachmentServer(t, issues, map[string]string{
+		"10": "png", "11": "logs", "14": "PNG",
+	})
+
+	src := jiraSource("test-org", nil, nil, nil)
+	opts := JiraOptions{Attachments: &JiraAttachmentOptions{
+		MaxBytes:  50,
+		MIMETypes: []string{"image/*", "text/plain", "application/pdf"},
+	}}
+	result, err := FetchJiraIssuesWithOptions(context.Background(), src, "jira-token", opts)
+	require.NoError(t, err)
+
+	// The PDF is too large and the video's type is not selected.
+	assert.Equal(t, []string{"10", "11", "14", "15"}, *requested)
+	assert.Equal(t, []IssueFile{
+		{Path: "issues/PROJ-1/attachments/screenshot.png", Content: "png"},
+		{Path: "issues/PROJ-1/attachments/app.log", Content: "logs"},
+		{Path: "issues/PROJ-1/attachments/14-screenshot.png", Content: "PNG"},
+	}, result.Files)
+
+	raw := result.Issues["PROJ-1"]
+	assert.Contains(t, raw, `"localPath": "PROJ-1/attachments/screenshot.png"`)
+	assert.Contains(t, raw, `"localPath": "PROJ-1/attachments/14-screenshot.png"`)
+
+	opts.Format = IssueFormatMarkdown
+	*requested = nil
+	result, err = FetchJiraIssuesWithOptions(context.Background(), src, "jira-token", opts)
+	require.NoError(t, err)
+	md := result.Issues["PROJ-1"]
+	assert.Contains(t, md, "## Attachments\n\n")
+	assert.Contains(t, md, "- [screenshot.png](PROJ-1/attachments/screenshot.png) (image/png, 3 B)\n")
+	assert.Contains(t, md, "- [../../etc/app.log](PROJ-1/attachments/app.log) (text/plain, 4 B)\n")
+	assert.Contains(t, md, "- spec.pdf (application/pdf, 100 B, not downloaded)\n")
+	assert.Contains(t, md, "- gone.txt (text/plain, 1 B, not downloaded)\n")
+}
+
+func TestFetchJiraIssues_AttachmentExceedingLimit(t *testing.T) {
+	issues := []jiraIssue{{
+		Key: "PROJ-1",
+		Fields: jiraIssueFields{
+			Attachment: []jiraAttachment{{ID: "10", Filename: "big.bin", MimeType: "application/octet-stream", Size: 1}},
+		},
+	}}
+	withJiraAttachmentServer(t, issues, map[string]string{"10": "much larger than reported"})
+
+	src := jiraSource("test-org", nil, nil, nil)
+	result, err := FetchJiraIssuesWithOptions(context.Background(), src, "jira-token", JiraOptions{
+		Attachments: &JiraAttachmentOptions{MaxBytes: 8},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Files)
+	assert.NotContains(t, result.Issues["PROJ-1"], "localPath")
+}
+
+func TestFormatByteSize(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "512 B", formatByteSize(512))
+	assert.Equal(t, "1.5 KiB", formatByteSize(1536))
+	assert.Equal(t, "10.0 MiB", formatByteSize(10<<20))
+}
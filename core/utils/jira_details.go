@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// jiraPageSize is the page size requested from the comment and changelog endpoints.
+const jiraPageSize = 100
+
+// jiraHistory is one changelog entry: the field changes made by one edit.
+type jiraHistory struct {
+	ID      string           `json:"id"`
+	Author  *jiraUser        `json:"author,omitempty"`
+	Created string           `json:"created"`
+	Items   []jiraChangeItem `json:"items"`
+}
+
+type jiraChangeItem struct {
+	Field      string `json:"field"`
+	FromString string `json:"fromString,omitempty"`
+	ToString   string `json:"toString,omitempty"`
+}
+
+// String renders the change as "Field: from → to".
+func (c jiraChangeItem) String() string {
+	from, to := c.FromString, c.ToString
+	if from == "" {
+		from = "(none)"
+	}
+	if to == "" {
+		to = "(none)"
+	}
+	return fmt.Sprintf("%s: %s → %s", c.Field, from, to)
+}
+
+type jiraCommentPage struct {
+	StartAt  int           `json:"startAt"`
+	Total    int           `json:"total"`
+	Comments []jiraComment `json:"comments"`
+}
+
+type jiraChangelogPage struct {
+	StartAt int           `json:"startAt"`
+	Total   int           `json:"total"`
+	IsLast  bool          `json:"isLast"`
+	Values  []jiraHistory `json:"values"`
+}
+
+// fetchJiraRemainingComments appends the comments the search response left
+// out, since search only returns the first page inline.
+func fetchJiraRemainingComments(ctx context.Context, baseURL, token string, issue *jiraIssue) error {
+	c := issue.Fields.Comment
+	if c == nil {
+		return nil
+	}
+	for len(c.Comments) < c.Total {
+		apiURL := fmt.Sprintf("%s/rest/api/3/issue/%s/comment?startAt=%d&maxResults=%d&orderBy=created",
+			baseURL, url.PathEscape(issue.Key), len(c.Comments), jiraPageSize)
+		var page jiraCommentPage
+		if err := jiraGetJSON(ctx, apiURL, token, &page); err != nil {
+			return err
+		}
+		if len(page.Comments) == 0 {
+			break
+		}
+		c.Comments = append(c.Comments, page.Comments...)
+		c.Total = page.Total
+	}
+	return nil
+}
+
+// fetchJiraChangelog fetches all changelog entries of the issue, oldest first.
+func fetchJiraChangelog(ctx context.Context, baseURL, token, key string) ([]jiraHistory, error) {
+	var histories []jiraHistory
+	for {
+		apiURL := fmt.Sprintf("%s/rest/api/3/issue/%s/changelog?startAt=%d&maxResults=%d",
+			baseURL, url.PathEscape(key), len(histories), jiraPageSize)
+		var page jiraChangelogPage
+		if err := jiraGetJSON(ctx, apiURL, token, &page); err != nil {
+			return histories, err
+		}
+		histories = append(histories, page.Values...)
+		if page.IsLast || len(page.Values) == 0 || len(histories) >= page.Total {
+			return histories, nil
+		}
+	}
+}
+
+// jiraGetJSON sends an authenticated GET request and decodes the JSON response into v.
+func jiraGetJSON(ctx context.Context, apiURL, token string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create jira request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	setJiraAuth(req, token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch from jira: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := readAllWithProgress(ctx, resp.Body, resp.ContentLength)
+	if err != nil {
+		return fmt.Errorf("failed to read jira response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jira API returned status %d: %s", resp.StatusCode, truncateBody(body))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse jira response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withJiraDetailsServer(t *testing.T, issues []jiraIssue, handlers map[string]http.HandlerFunc) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/3/search/jql", jiraServerResponse(issues, ""))
+	mux.HandleFunc("/rest/api/3/user/email/bulk", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"values":[{"accountId":"acc-carol","email":"carol@example.com"}]}`))
+	})
+	for pattern, h := range handlers {
+		mux.HandleFunc(pattern, h)
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	old := jiraBaseURL
+	jiraBaseURL = server.URL
+	t.Cleanup(func() { jiraBaseURL = old })
+}
+
+func commentBody(text string) json.RawMessage {
+	return json.RawMessage(`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"` + text + `"}]}]}`)
+}
+
+func TestFetchJiraIssues_CommentPagination(t *testing.T) {
+	issues := []jiraIssue{{
+		Key: "PROJ-1",
+		Fields: jiraIssueFields{
+			Summary: "Many comments",
+			Comment: &jiraCommentContainer{Total: 3, Comments: []jiraComment{{ID: "1", Body: commentBody("first")}}},
+		},
+	}}
+	var startAts []string
+	withJiraDetailsServer(t, issues, map[string]http.HandlerFunc{
+		"/rest/api/3/issue/PROJ-1/comment": func(w http.ResponseWriter, r *http.Request) {
+			startAt := r.URL.Query().Get("startAt")
+			startAts = append(startAts, startAt)
+			page := jiraCommentPage{Total: 3}
+			switch startAt {
+			case "1":
+				page.Comments = []jiraComment{{ID: "2", Body: commentBody("second")}}
+			case "2":
+				page.Comments = []jiraComment{{ID: "3", Body: commentBody("third"), Author: &jiraUser{DisplayName: "Carol", AccountID: "acc-carol"}}}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(page)
+		},
+	})
+
+	src := jiraSource("test-org", nil, nil, nil)
+	result, err := FetchJiraIssuesWithOptions(context.Background(), src, "", JiraOptions{Format: IssueFormatMarkdown})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, startAts)
+
+	md := result.Issues["PROJ-1"]
+	assert.Contains(t, md, "first")
+	assert.Contains(t, md, "second")
+	// Authors of paginated comments are enriched with emails too.
+	assert.Contains(t, md, "### Carol (carol@example.com)\n\nthird\n")
+	assert.NotContains(t, md, "not fetched")
+}
+
+func TestFetchJiraIssues_Changelog(t *testing.T) {
+	issues := []jiraIssue{{Key: "PROJ-1", Fields: jiraIssueFields{Summary: "History"}}}
+	withJiraDetailsServer(t, issues, map[string]http.HandlerFunc{
+		"/rest/api/3/issue/PROJ-1/changelog": func(w http.ResponseWriter, r *http.Request) {
+			page := jiraChangelogPage{Total: 2, StartAt: 0}
+			if r.URL.Query().Get("startAt") == "0" {
+				page.Values = []jiraHistory{{
+					ID: "100", Created: "2025-01-02T10:00:00.000+0000",
+					Author: &jiraUser{DisplayName: "Carol", AccountID: "acc-carol"},
+					Items: []jiraChangeItem{
+						{Field: "status", FromString: "To Do", ToString: "In Progress"},
+						{Field: "assignee", ToString: "Bob"},
+					},
+				}}
+			} else {
+				page.StartAt = 1
+				page.IsLast = true
+				page.Values = []jiraHistory{{
+					ID: "101", Created: "2025-01-03T10:00:00.000+0000",
+					Items: []jiraChangeItem{{Field: "resolution", ToString: "Done"}},
+				}}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(page)
+		},
+	})
+
+	src := jiraSource("test-org", nil, nil, nil)
+	result, err := FetchJiraIssuesWithOptions(context.Background(), src, "", JiraOptions{Changelog: true})
+	require.NoError(t, err)
+	raw := result.Issues["PROJ-1"]
+	assert.Contains(t, raw, `"changelog": [`)
+	assert.Contains(t, raw, `"fromString": "To Do"`)
+	assert.Contains(t, raw, `"emailAddress": "carol@example.com"`)
+
+	result, err = FetchJiraIssuesWithOptions(context.Background(), src, "", JiraOptions{Changelog: true, Format: IssueFormatMarkdown})
+	require.NoError(t, err)
+	assert.Contains(t, result.Issues["PROJ-1"], "## History\n\n"+
+		"- 2025-01-02T10:00:00.000+0000 Carol (carol@example.com):\n"+
+		"  - status: To Do → In Progress\n"+
+		"  - assignee: (none) → Bob\n"+
+		"- 2025-01-03T10:00:00.000+0000 Unknown:\n"+
+		"  - resolution: (none) → Done\n")
+}
+
+func TestFetchJiraIssues_ChangelogNotRequested(t *testing.T) {
+	issues := []jiraIssue{{Key: "PROJ-1"}}
+	withJiraDetailsServer(t, issues, map[string]http.HandlerFunc{
+		"/rest/api/3/issue/PROJ-1/changelog": func(w http.ResponseWriter, r *http.Request) {
+			t.Error("changelog must not be fetched without the option")
+		},
+	})
+
+	src := jiraSource("test-org", nil, nil, nil)
+	result, err := FetchJiraIssues(context.Background(), src, "")
+	require.NoError(t, err)
+	assert.NotContains(t, result.Issues["PROJ-1"], "changelog")
+}
@@ -0,0 +1,313 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type jiraIssueLink struct {
+	ID           string            `json:"id"`
+	Type         jiraIssueLinkType `json:"type"`
+	InwardIssue  *jiraLinkedIssue  `json:"inwardIssue,omitempty"`
+	OutwardIssue *jiraLinkedIssue  `json:"outwardIssue,omitempty"`
+}
+
+type jiraIssueLinkType struct {
+	Name    string `json:"name"`
+	Inward  string `json:"inward"`
+	Outward string `json:"outward"`
+}
+
+type jiraLinkedIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary string   `json:"summary"`
+		Status  jiraName `json:"status"`
+	} `json:"fields"`
+}
+
+func (i *jiraLinkedIssue) String() string {
+	s := i.Key
+	if i.Fields.Summary != "" {
+		s += ": " + i.Fields.Summary
+	}
+	if i.Fields.Status.Name != "" {
+		s += " (" + i.Fields.Status.Name + ")"
+	}
+	return s
+}
+
+// String renders the link from the point of view of the issue holding it,
+// e.g. "is blocked by PROJ-2: Summary (Open)".
+func (l jiraIssueLink) String() string {
+	if l.OutwardIssue != nil {
+		return l.Type.Outward + " " + l.OutwardIssue.String()
+	}
+	if l.InwardIssue != nil {
+		return l.Type.Inward + " " + l.InwardIssue.String()
+	}
+	return l.Type.Name
+}
+
+type jiraRemoteLink struct {
+	Relationship string `json:"relationship,omitempty"`
+	Object       struct {
+		URL   string `json:"url"`
+		Title string `json:"title"`
+	} `json:"object"`
+}
+
+func (l jiraRemoteLink) String() string {
+	title := l.Object.Title
+	if title == "" {
+		title = l.Object.URL
+	}
+	link := "[" + title + "](" + l.Object.URL + ")"
+	if l.Relationship != "" {
+		return l.Relationship + " " + link
+	}
+	return "links to " + link
+}
+
+// fetchJiraRemoteLinks fetches the web links of the issue.
+func fetchJiraRemoteLinks(ctx context.Context, baseURL, token, key string) ([]jiraRemoteLink, error) {
+	var links []jiraRemoteLink
+	apiURL := fmt.Sprintf("%s/rest/api/3/issue/%s/remotelink", baseURL, url.PathEscape(key))
+	if err := jiraGetJSON(ctx, apiURL, token, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// jiraDevelopment is the development panel of an issue: the branches, commits
+// and pull requests that mention its key.
+type jiraDevelopment struct {
+	PullRequests []jiraDevPR     `json:"pullRequests,omitempty"`
+	Branches     []jiraDevBranch `json:"branches,omitempty"`
+	Commits      []jiraDevCommit `json:"commits,omitempty"`
+}
+
+type jiraDevPR struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Status string `json:"status"`
+	Author struct {
+		Name string `json:"name"`
+	} `json:"author"`
+	Source struct {
+		Branch string `json:"branch"`
+	} `json:"source"`
+	Destination struct {
+		Branch string `json:"branch"`
+	} `json:"destination"`
+}
+
+func (p jiraDevPR) String() string {
+	s := "[" + p.Name + "](" + p.URL + ") (" + p.Status
+	if p.Source.Branch != "" && p.Destination.Branch != "" {
+		s += ", " + p.Source.Branch + " → " + p.Destination.Branch
+	}
+	if p.Author.Name != "" {
+		s += ", by " + p.Author.Name
+	}
+	return s + ")"
+}
+
+type jiraDevBranch struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Repository struct {
+		Name string `json:"name"`
+	} `json:"repository"`
+}
+
+func (b jiraDevBranch) String() string {
+	s := "[" + b.Name + "](" + b.URL + ")"
+	if b.Repository.Name != "" {
+		s += " in " + b.Repository.Name
+	}
+	return s
+}
+
+type jiraDevCommit struct {
+	ID         string `json:"id"`
+	DisplayID  string `json:"displayId"`
+	Message    string `json:"message"`
+	URL        string `json:"url"`
+	Repository string `json:"repository,omitempty"`
+	Author     struct {
+		Name string `json:"name"`
+	} `json:"author"`
+}
+
+func (c jiraDevCommit) String() string {
+	id := c.DisplayID
+	if id == "" {
+		id = c.ID
+	}
+	msg, _, _ := strings.Cut(c.Message, "\n")
+	s := "[" + id + "](" + c.URL + ") " + msg
+	if c.Author.Name != "" {
+		s += " (" + c.Author.Name + ")"
+	}
+	return s
+}
+
+type jiraDevSummaryResponse struct {
+	Summary map[string]struct {
+		ByInstanceType map[string]struct {
+			Count int `json:"count"`
+		} `json:"byInstanceType"`
+	} `json:"summary"`
+}
+
+type jiraDevDetailResponse struct {
+	Detail []struct {
+		PullRequests []jiraDevPR     `json:"pullRequests"`
+		Branches     []jiraDevBranch `json:"branches"`
+		Repositories []struct {
+			Name    string          `json:"name"`
+			Commits []jiraDevCommit `json:"commits"`
+		} `json:"repositories"`
+	} `json:"detail"`
+}
+
+// jiraDevDataTypes are the dev-status data types fetched per application.
+var jiraDevDataTypes = []string{"pullrequest", "branch", "repository"}
+
+// fetchJiraDevelopment fetches the development panel of the issue with the
+// numeric id issueID from the dev-status API: the summary names the linked
+// applications (e.g. "GitHub"), whose details are then fetched per data type.
+func fetchJiraDevelopment(ctx context.Context, baseURL, token, issueID string) (*jiraDevelopment, error) {
+	var summary jiraDevSummaryResponse
+	summaryURL := fmt.Sprintf("%s/rest/dev-status/latest/issue/summary?issueId=%s", baseURL, url.QueryEscape(issueID))
+	if err := jiraGetJSON(ctx, summaryURL, token, &summary); err != nil {
+		return nil, err
+	}
+	var apps []string
+	for _, dataType := range jiraDevDataTypes {
+		for app, s := range summary.Summary[dataType].ByInstanceType {
+			if s.Count > 0 && !slices.Contains(apps, app) {
+				apps = append(apps, app)
+			}
+		}
+	}
+	if len(apps) == 0 {
+		return nil, nil
+	}
+	sort.Strings(apps)
+
+	dev := &jiraDevelopment{}
+	seenPRs := map[string]bool{}
+	seenBranches := map[string]bool{}
+	for _, app := range apps {
+		for _, dataType := range jiraDevDataTypes {
+			detailURL := fmt.Sprintf("%s/rest/dev-status/latest/issue/detail?issueId=%s&applicationType=%s&dataType=%s",
+				baseURL, url.QueryEscape(issueID), url.QueryEscape(app), dataType)
+			var detail jiraDevDetailResponse
+			if err := jiraGetJSON(ctx, detailURL, token, &detail); err != nil {
+				return dev, err
+			}
+			for _, d := range detail.Detail {
+				// Branch details repeat the pull requests of the branches.
+				for _, pr := range d.PullRequests {
+					if !seenPRs[pr.URL] {
+						seenPRs[pr.URL] = true
+						dev.PullRequests = append(dev.PullRequests, pr)
+					}
+				}
+				for _, b := range d.Branches {
+					if !seenBranches[b.URL] {
+						seenBranches[b.URL] = true
+						dev.Branches = append(dev.Branches, b)
+					}
+				}
+				for _, r := range d.Repositories {
+					for _, c := range r.Commits {
+						c.Repository = r.Name
+						dev.Commits = append(dev.Commits, c)
+					}
+				}
+			}
+		}
+	}
+	return dev, nil
+}
+
+// jiraDependencyGraph renders the links between issues as a Mermaid graph.
+// Every link points from the issue on its outward side, e.g. "A -->|blocks| B".
+func jiraDependencyGraph(issues []jiraIssue) string {
+	type edge struct{ from, to, label string }
+	var edges []edge
+	seen := map[edge]bool{}
+	labels := map[string]string{}
+	addEdge := func(e edge) {
+		if !seen[e] {
+			seen[e] = true
+			edges = append(edges, e)
+		}
+	}
+	for _, issue := range issues {
+		labels[issue.Key] = issue.Key + ": " + issue.Fields.Summary
+	}
+	for _, issue := range issues {
+		for _, l := range issue.Fields.IssueLinks {
+			switch {
+			case l.OutwardIssue != nil:
+				addEdge(edge{from: issue.Key, to: l.OutwardIssue.Key, label: l.Type.Outward})
+				if _, ok := labels[l.OutwardIssue.Key]; !ok {
+					labels[l.OutwardIssue.Key] = l.OutwardIssue.Key + ": " + l.OutwardIssue.Fields.Summary
+				}
+			case l.InwardIssue != nil:
+				addEdge(edge{from: l.InwardIssue.Key, to: issue.Key, label: l.Type.Outward})
+				if _, ok := labels[l.InwardIssue.Key]; !ok {
+					labels[l.InwardIssue.Key] = l.InwardIssue.Key + ": " + l.InwardIssue.Fields.Summary
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("# Issue Dependency Graph\n\n")
+	if len(edges) == 0 {
+		b.WriteString("No links between issues.\n")
+		return b.String()
+	}
+
+	linked := map[string]bool{}
+	for _, e := range edges {
+		linked[e.from] = true
+		linked[e.to] = true
+	}
+	keys := make([]string, 0, len(linked))
+	for k := range linked {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b.WriteString("```mermaid\ngraph LR\n")
+	for _, k := range keys {
+		label := strings.TrimSuffix(labels[k], ": ")
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", mermaidID(k), strings.ReplaceAll(label, `"`, "#quot;"))
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", mermaidID(e.from), strings.ReplaceAll(e.label, "|", "#124;"), mermaidID(e.to))
+	}
+	b.WriteString("```\n")
+	return b.String()
+}
+
+// mermaidID turns an issue key into a Mermaid node id.
+func mermaidID(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key)
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func linkedIssue(key, summary, status string) *jiraLinkedIssue {
+	li := &jiraLinkedIssue{Key: key}
+	li.Fields.Summary = summary
+	li.Fields.Status.Name = status
+	return li
+}
+
+var blocksLinkType = jiraIssueLinkType{Name: "Blocks", Inward: "is blocked by", Outward: "blocks"}
+
+func TestFetchJiraIssues_LinksAndDevelopment(t *testing.T) {
+	issues := []jiraIssue{{
+		ID:  "10001",
+		Key: "PROJ-1",
+		Fields: jiraIssueFields{
+			Summary: "Linked",
+			IssueLinks: []jiraIssueLink{
+				{ID: "1", Type: blocksLinkType, OutwardIssue: linkedIssue("PROJ-2", "Follow-up", "Open")},
+				{ID: "2", Type: blocksLinkType, InwardIssue: linkedIssue("PROJ-3", "Prerequisite", "Done")},
+			},
+		},
+	}}
+	withJiraDetailsServer(t, issues, map[string]http.HandlerFunc{
+		"/rest/api/3/issue/PROJ-1/remotelink": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[{"id":1,"relationship":"mentioned in","object":{"url":"https://wiki.example.com/design","title":"Design doc"}},` +
+				`{"id":2,"object":{"url":"https://status.example.com/incident/7"}}]`))
+		},
+		"/rest/dev-status/latest/issue/summary": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "10001", r.URL.Query().Get("issueId"))
+			_, _ = w.Write([]byte(`{"summary":{"pullrequest":{"byInstanceType":{"GitHub":{"count":1,"name":"GitHub"}}},` +
+				`"repository":{"byInstanceType":{"GitHub":{"count":1}}},"branch":{"byInstanceType":{}}}}`))
+		},
+		"/rest/dev-status/latest/issue/detail": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "GitHub", r.URL.Query().Get("applicationType"))
+			pr := `{"id":"#12","name":"PROJ-1 Add feature","url":"https://github.com/org/repo/pull/12","status":"MERGED",` +
+				`"author":{"name":"Alice"},"source":{"branch":"feature"},"destination":{"branch":"main"}}`
+			switch r.URL.Query().Get("dataType") {
+			case "pullrequest":
+				_, _ = w.Write([]byte(`{"detail":[{"pullRequests":[` + pr + `]}]}`))
+			case "branch":
+				_, _ = w.Write([]byte(`{"detail":[{"branches":[{"name":"feature","url":"https://github.com/org/repo/tree/feature","repository":{"name":"org/repo"}}],"pullRequests":[` + pr + `]}]}`))
+			case "repository":
+				_, _ = w.Write([]byte(`{"detail":[{"repositories":[{"name":"org/repo","commits":[` +
+					`{"id":"abcdef123456","displayId":"abcdef1","message":"PROJ-1 implement\n\nbody","url":"https://github.com/org/repo/commit/abcdef1","author":{"name":"Alice"}}]}]}]}`))
+			}
+		},
+	})
+
+	src := jiraSource("test-org", nil, nil, nil)
+	opts := JiraOptions{RemoteLinks: true, DevelopmentInfo: true, Format: IssueFormatMarkdown}
+	result, err := FetchJiraIssuesWithOptions(context.Background(), src, "user@example.com:token", opts)
+	require.NoError(t, err)
+
+	md := result.Issues["PROJ-1"]
+	assert.Contains(t, md, "## Links\n\n"+
+		"- blocks PROJ-2: Follow-up (Open)\n"+
+		"- is blocked by PROJ-3: Prerequisite (Done)\n"+
+		"- mentioned in [Design doc](https://wiki.example.com/design)\n"+
+		"- links to [https://status.example.com/incident/7](https://status.example.com/incident/7)\n")
+	assert.Contains(t, md, "### Pull Requests\n\n- [PROJ-1 Add feature](https://github.com/org/repo/pull/12) (MERGED, feature → main, by Alice)\n")
+	assert.Contains(t, md, "### Branches\n\n- [feature](https://github.com/org/repo/tree/feature) in org/repo\n")
+	assert.Contains(t, md, "### Commits\n\n- [abcdef1](https://github.com/org/repo/commit/abcdef1) PROJ-1 implement (Alice)\n")
+	assert.Equal(t, 1, strings.Count(md, "pull/12)"))
+
+	opts.Format = IssueFormatJSON
+	result, err = FetchJiraIssuesWithOptions(context.Background(), src, "user@example.com:token", opts)
+	require.NoError(t, err)
+	raw := result.Issues["PROJ-1"]
+	assert.Contains(t, raw, `"issuelinks": [`)
+	assert.Contains(t, raw, `"remoteLinks": [`)
+	assert.Contains(t, raw, `"development": {`)
+}
+
+func TestJiraDependencyGraph(t *testing.T) {
+	t.Parallel()
+	issues := []jiraIssue{
+		{Key: "PROJ-1", Fields: jiraIssueFields{Summary: `Add "quotes"`, IssueLinks: []jiraIssueLink{
+			{ID: "1", Type: blocksLinkType, OutwardIssue: linkedIssue("PROJ-2", "Second", "")},
+		}}},
+		// The same link seen from the other side.
+		{Key: "PROJ-2", Fields: jiraIssueFields{Summary: "Second", IssueLinks: []jiraIssueLink{
+			{ID: "1", Type: blocksLinkType, InwardIssue: linkedIssue("PROJ-1", `Add "quotes"`, "")},
+			{ID: "3", Type: jiraIssueLinkType{Outward: "relates to", Inward: "relates to"}, InwardIssue: linkedIssue("OTHER-9", "", "")},
+		}}},
+		{Key: "PROJ-4", Fields: jiraIssueFields{Summary: "Unlinked"}},
+	}
+	assert.Equal(t, "# Issue Dependency Graph\n\n```mermaid\ngraph LR\n"+
+		"  OTHER_9[\"OTHER-9\"]\n"+
+		"  PROJ_1[\"PROJ-1: Add #quot;quotes#quot;\"]\n"+
+		"  PROJ_2[\"PROJ-2: Second\"]\n"+
+		"  PROJ_1 -->|blocks| PROJ_2\n"+
+		"  OTHER_9 -->|relates to| PROJ_2\n"+
+		"```\n", jiraDependencyGraph(issues))
+
+	assert.Equal(t, "# Issue Dependency Graph\n\nNo links between issues.\n", jiraDependencyGraph(issues[2:]))
+}
+
+func TestFetchJiraIssues_DependencyGraphFile(t *testing.T) {
+	issues := []jiraIssue{{Key: "PROJ-1", Fields: jiraIssueFields{IssueLinks: []jiraIssueLink{
+		{Type: blocksLinkType, OutwardIssue: linkedIssue("PROJ-2", "", "")},
+	}}}}
+	withJiraDetailsServer(t, issues, nil)
+
+	src := jiraSource("test-org", nil, nil, nil)
+	result, err := FetchJiraIssuesWithOptions(context.Background(), src, "", JiraOptions{DependencyGraph: true})
+	require.NoError(t, err)
+	require.Len(t, result.Files, 1)
+	assert.Equal(t, "issues/dependency-graph.md", result.Files[0].Path)
+	assert.Contains(t, result.Files[0].Content, "PROJ_1 -->|blocks| PROJ_2")
+}
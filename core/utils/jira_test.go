@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -291,6 +292,64 @@ func TestFetchJiraIssues_PlainStringDescription(t *testing.T) {
 	assert.Contains(t, result.Issues["PROJ-1"], "This is a plain description")
 }
 
+func TestFetchJiraIssues_MarkdownFormat(t *testing.T) {
+	issues := []jiraIssue{
+		{
+			Key: "PROJ-1",
+			Fields: jiraIssueFields{
+				Summary:     "Markdown test",
+				Description: json.RawMessage(`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Hello ","marks":[]},{"type":"text","text":"world","marks":[{"type":"strong"}]}]}]}`),
+				Status:      jiraName{Name: "Open"},
+				IssueType:   jiraName{Name: "Bug"},
+				Priority:    jiraName{Name: "High"},
+				Assignee:    &jiraUser{DisplayName: "Alice", EmailAddress: "alice@example.com"},
+				Labels:      []string{"backend", "urgent"},
+				Parent:      &jiraParent{Key: "PROJ-0"},
+				Created:     "2025-01-15T10:00:00.000+0000",
+				Comment: &jiraCommentContainer{
+					Total: 3,
+					Comments: []jiraComment{
+						{Author: &jiraUser{DisplayName: "Bob"}, Created: "2025-01-16T10:00:00.000+0000", Body: json.RawMessage(`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Looks good"}]}]}`)},
+						{Body: json.RawMessage(`[1]`)},
+					},
+				},
+			},
+		},
+	}
+
+	server := httptest.NewServer(jiraServerResponse(issues, ""))
+	defer server.Close()
+
+	old := jiraBaseURL
+	jiraBaseURL = server.URL
+	defer func() { jiraBaseURL = old }()
+
+	src := jiraSource("test-org", nil, nil, nil)
+	result, err := FetchJiraIssuesWithOptions(context.Background(), src, "", JiraOptions{Format: IssueFormatMarkdown})
+	require.NoError(t, err)
+	assert.Equal(t, IssueFormatMarkdown, result.Format)
+
+	md := result.Issues["PROJ-1"]
+	assert.True(t, strings.HasPrefix(md, "# PROJ-1: Markdown test\n"))
+	assert.Contains(t, md, "**Status:** Open\n")
+	assert.Contains(t, md, "**Assignee:** Alice (alice@example.com)\n")
+	assert.Contains(t, md, "**Labels:** backend, urgent\n")
+	assert.Contains(t, md, "**Parent:** PROJ-0\n")
+	assert.Contains(t, md, "## Description\n\nHello **world**\n")
+	assert.Contains(t, md, "### Bob (2025-01-16T10:00:00.000+0000)\n\nLooks good\n")
+	// Rich text that is not valid ADF is kept as JSON.
+	assert.Contains(t, md, "### Unknown\n\n```json\n[1]\n```\n")
+	assert.Contains(t, md, "_1 more comment(s) not fetched._")
+	assert.NotContains(t, md, `"type":"doc"`)
+}
+
+func TestFetchJiraIssues_UnsupportedFormat(t *testing.T) {
+	t.Parallel()
+	src := jiraSource("test-org", nil, nil, nil)
+	_, err := FetchJiraIssuesWithOptions(context.Background(), src, "", JiraOptions{Format: "xml"})
+	assert.ErrorContains(t, err, `unsupported issue format "xml"`)
+}
+
 func TestFetchJiraIssues_RequestContainsJQL(t *testing.T) {
 	var receivedBody jiraSearchRequest
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -314,7 +373,7 @@ func TestFetchJiraIssues_RequestContainsJQL(t *testing.T) {
 
 func TestBuildJQL_ProjectsOnly(t *testing.T) {
 	t.Parallel()
-	jql := buildJQL([]string{"PROJ1", "PROJ2"}, nil)
+	jql := buildJQL([]string{"PROJ1", "PROJ2"}, nil, JiraFilter{})
 	assert.Contains(t, jql, "project IN")
 	assert.Contains(t, jql, "PROJ1")
 	assert.Contains(t, jql, "PROJ2")
@@ -322,7 +381,7 @@ func TestBuildJQL_ProjectsOnly(t *testing.T) {
 
 func TestBuildJQL_NoFilters(t *testing.T) {
 	t.Parallel()
-	jql := buildJQL(nil, nil)
+	jql := buildJQL(nil, nil, JiraFilter{})
 	assert.Contains(t, jql, "created >= -30d")
 	assert.Contains(t, jql, "ORDER BY created DESC")
 }
@@ -334,7 +393,7 @@ func TestBuildJQL_WithDates(t *testing.T) {
 		CreatedAtFilter: osdd.DatesFilter_builder{From: ts}.Build(),
 	}.Build()
 
-	jql := buildJQL(nil, filter)
+	jql := buildJQL(nil, filter, JiraFilter{})
 	assert.Contains(t, jql, `created >= "2025-06-15"`)
 }
 
@@ -347,10 +406,164 @@ func TestBuildJQL_ProjectsAndDates(t *testing.T) {
 		UpdatedAtFilter: osdd.DatesFilter_builder{From: from}.Build(),
 	}.Build()
 
-	jql := buildJQL([]string{"PROJ"}, filter)
+	jql := buildJQL([]string{"PROJ"}, filter, JiraFilter{})
 	assert.Contains(t, jql, `project IN ("PROJ")`)
 	assert.Contains(t, jql, `created >= "2025-01-01"`)
 	assert.Contains(t, jql, `created <= "2025-12-31"`)
 	assert.Contains(t, jql, `updated >= "2025-01-01"`)
 	assert.Contains(t, jql, " AND ")
 }
+
+func TestBuildJQL_StructuredFilters(t *testing.T) {
+	t.Parallel()
+	jql := buildJQL([]string{"PROJ"}, nil, JiraFilter{
+		Statuses:         []string{"In Review", "Done"},
+		StatusCategories: []string{"In Progress"},
+		Assignees:        []string{"alice@example.com", "unassigned"},
+		Labels:           []string{"backend"},
+		IssueTypes:       []string{"Bug"},
+		Sprints:          []string{"openSprints()", "42"},
+		Epics:            []string{"PROJ-7"},
+	})
+	assert.Equal(t, `project IN ("PROJ") AND status IN ("In Review", "Done") AND statusCategory IN ("In Progress") AND `+
+		`(assignee IN ("alice@example.com") OR assignee IS EMPTY) AND labels IN ("backend") AND issuetype IN ("Bug") AND `+
+		`sprint IN (openSprints(), 42) AND parent IN ("PROJ-7") ORDER BY created DESC`, jql)
+}
+
+func TestBuildJQL_FilterWithoutProjectsSkipsDefault(t *testing.T) {
+	t.Parallel()
+	jql := buildJQL(nil, nil, JiraFilter{Assignees: []string{"currentUser()"}})
+	assert.Equal(t, "assignee IN (currentUser()) ORDER BY created DESC", jql)
+}
+
+func TestFetchJiraIssues_JQLPassthroughAndFields(t *testing.T) {
+	var receivedBody jiraSearchRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&receivedBody)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"issues":[{"key":"PROJ-1","fields":{"summary":"Custom","status":{"name":"Open"},` +
+			`"customfield_10016":5,"customfield_10020":[{"id":1,"name":"Sprint 1"}],"duedate":"2025-07-01","customfield_99":null}}]}`))
+	}))
+	defer server.Close()
+
+	old := jiraBaseURL
+	jiraBaseURL = server.URL
+	defer func() { jiraBaseURL = old }()
+
+	src := jiraSource("test-org", []string{"IGNORED"}, nil, nil)
+	opts := JiraOptions{
+		JQL:          "project = PROJ AND fixVersion = 1.0 ORDER BY rank",
+		Fields:       []string{"summary", "status", "duedate"},
+		CustomFields: map[string]string{"customfield_10016": "Story Points", "customfield_10020": "Sprint"},
+	}
+	result, err := FetchJiraIssuesWithOptions(context.Background(), src, "", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "project = PROJ AND fixVersion = 1.0 ORDER BY rank", receivedBody.JQL)
+	assert.Equal(t, []string{"summary", "status", "duedate", "customfield_10016", "customfield_10020"}, receivedBody.Fields)
+
+	raw := result.Issues["PROJ-1"]
+	assert.Contains(t, raw, `"Story Points": 5`)
+	assert.Contains(t, raw, `"Sprint": [`)
+	assert.Contains(t, raw, `"duedate": "2025-07-01"`)
+	assert.NotContains(t, raw, "customfield_")
+
+	opts.Format = IssueFormatMarkdown
+	result, err = FetchJiraIssuesWithOptions(context.Background(), src, "", opts)
+	require.NoError(t, err)
+	md := result.Issues["PROJ-1"]
+	assert.Contains(t, md, "**Sprint:** Sprint 1\n**Story Points:** 5\n**duedate:** 2025-07-01\n")
+}
+
+func TestFetchJiraIssues_JQLWithFilter(t *testing.T) {
+	t.Parallel()
+	src := jiraSource("test-org", nil, nil, nil)
+	_, err := FetchJiraIssuesWithOptions(context.Background(), src, "", JiraOptions{
+		JQL:    "project = PROJ",
+		Filter: JiraFilter{Labels: []string{"x"}},
+	})
+	assert.ErrorContains(t, err, "cannot be combined with structured filters")
+}
+
+func TestFetchJiraIssues_MaxIssuesTruncates(t *testing.T) {
+	var maxResults []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jiraSearchRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		maxResults = append(maxResults, req.MaxResults)
+		issues := make([]jiraIssue, req.MaxResults)
+		for i := range issues {
+			issues[i] = jiraIssue{Key: "P-" + strconv.Itoa(len(maxResults)*100+i)}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(jiraSearchResponse{Issues: issues, NextPageToken: "more"})
+	}))
+	defer server.Close()
+
+	old := jiraBaseURL
+	jiraBaseURL = server.URL
+	defer func() { jiraBaseURL = old }()
+
+	src := jiraSource("test-org", nil, nil, nil)
+	result, err := FetchJiraIssuesWithOptions(context.Background(), src, "", JiraOptions{MaxIssues: 60})
+	require.NoError(t, err)
+	assert.Equal(t, []int{50, 10}, maxResults)
+	assert.Len(t, result.Summary, 60)
+}
+
+func TestFetchJiraIssues_IssueLinksAndRemoteLinks(t *testing.T) {
+	issues := []jiraIssue{{ID: "10001", Key: "PROJ-1", Fields: jiraIssueFields{
+		Summary: "Linked",
+		IssueLinks: []jiraIssueLink{
+			{ID: "1", Type: jiraIssueLinkType{Name: "Blocks", Inward: "is blocked by", Outward: "blocks"}, OutwardIssue: &jiraLinkedIssue{Key: "PROJ-2"}},
+		},
+	}}}
+	var fields [][]string
+	var remoteLinkCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/3/search/jql":
+			var req jiraSearchRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			fields = append(fields, req.Fields)
+			jiraServerResponse(issues, "")(w, r)
+		case "/rest/api/3/issue/PROJ-1/remotelink":
+			remoteLinkCalls++
+			_, _ = w.Write([]byte(`[{"id":1,"object":{"url":"https://wiki.example.com/design","title":"Design doc"}}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	old := jiraBaseURL
+	jiraBaseURL = server.URL
+	defer func() { jiraBaseURL = old }()
+
+	src := jiraSource("test-org", nil, nil, nil)
+
+	t.Run("issue links are fetched by default", func(t *testing.T) {
+		result, err := FetchJiraIssuesWithOptions(context.Background(), src, "user@example.com:token", JiraOptions{Format: IssueFormatMarkdown})
+		require.NoError(t, err)
+		assert.Contains(t, fields[len(fields)-1], "issuelinks")
+		assert.Contains(t, result.Issues["PROJ-1"], "## Links\n\n- blocks PROJ-2\n")
+		assert.Equal(t, 0, remoteLinkCalls)
+
+		result, err = FetchJiraIssuesWithOptions(context.Background(), src, "user@example.com:token", JiraOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, result.Issues["PROJ-1"], `"issuelinks": [`)
+		assert.NotContains(t, result.Issues["PROJ-1"], `"id": "10001"`)
+	})
+
+	t.Run("remote links are fetched on request", func(t *testing.T) {
+		result, err := FetchJiraIssuesWithOptions(context.Background(), src, "user@example.com:token", JiraOptions{RemoteLinks: true, Format: IssueFormatMarkdown})
+		require.NoError(t, err)
+		assert.Equal(t, 1, remoteLinkCalls)
+		assert.Contains(t, result.Issues["PROJ-1"], "- blocks PROJ-2\n- links to [Design doc](https://wiki.example.com/design)\n")
+	})
+
+	t.Run("the dependency graph requests issue links with custom fields", func(t *testing.T) {
+		_, err := FetchJiraIssuesWithOptions(context.Background(), src, "user@example.com:token", JiraOptions{Fields: []string{"summary"}, DependencyGraph: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"summary", "issuelinks"}, fields[len(fields)-1])
+	})
+}
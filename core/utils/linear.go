@@ -19,18 +19,34 @@ var linearBaseURL string
 
 const linearMaxIssues = 1000
 
-const linearQuery = `query($filter: IssueFilter, $after: String) {
-  issues(filter: $filter, after: $after, first: 50) {
+// linearPageSize is the number of issues, projects or documents requested per
+// page. Linear rejects queries whose estimated complexity, the nested
+// connection limits multiplied by the page size, exceeds 10,000 points.
+const linearPageSize = 25
+
+// Limits of the connections nested in linearQuery. Comments are paged in
+// full; labels, sub-issues and attachments beyond the limit are dropped with
+// a warning.
+const (
+	linearLabelsLimit      = 20
+	linearChildrenLimit    = 25
+	linearAttachmentsLimit = 25
+	linearCommentsLimit    = 20
+)
+
+var linearQuery = fmt.Sprintf(`query($filter: IssueFilter, $after: String, $first: Int) {
+  issues(filter: $filter, after: $after, first: $first) {
     nodes {
       identifier
       title
       url
       description
-      state { name }
+      state { name type }
+      project { name }
       assignee { name email }
       creator { name email }
       team { key name }
-      labels { nodes { name } }
+      labels(first: %d) { nodes { name } pageInfo { hasNextPage } }
       cycle { name number }
       createdAt
       updatedAt
@@ -38,12 +54,28 @@ const linearQuery = `query($filter: IssueFilter, $after: String) {
       canceledAt
       priority
       priorityLabel
-      comments {
+      parent { identifier title state { name type } }
+      children(first: %d) { nodes { identifier title state { name type } } pageInfo { hasNextPage } }
+      attachments(first: %d) {
+        nodes {
+          title
+          subtitle
+          url
+          sourceType
+          createdAt
+        }
+        pageInfo { hasNextPage }
+      }
+      comments(first: %d) {
         nodes {
           body
           user { name email }
           createdAt
         }
+        pageInfo {
+          hasNextPage
+          endCursor
+        }
       }
     }
     pageInfo {
@@ -51,6 +83,23 @@ const linearQuery = `query($filter: IssueFilter, $after: String) {
       endCursor
     }
   }
+}`, linearLabelsLimit, linearChildrenLimit, linearAttachmentsLimit, linearCommentsLimit)
+
+// linearCommentsQuery fetches the comments of one issue after a cursor.
+const linearCommentsQuery = `query($id: String!, $after: String) {
+  issue(id: $id) {
+    comments(after: $after, first: 100) {
+      nodes {
+        body
+        user { name email }
+        createdAt
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }
+  }
 }`
 
 type linearGraphQLRequest struct {
@@ -58,8 +107,8 @@ type linearGraphQLRequest struct {
 	Variables map[string]any `json:"variables"`
 }
 
-type linearGraphQLResponse struct {
-	Data   *linearData    `json:"data"`
+type linearGraphQLResponse[T any] struct {
+	Data   *T             `json:"data"`
 	Errors []linearGQLErr `json:"errors"`
 }
 
@@ -67,38 +116,84 @@ type linearData struct {
 	Issues linearIssuesData `json:"issues"`
 }
 
-type linearIssuesData struct {
-	Nodes    []linearIssue  `json:"nodes"`
+type linearIssuesData = linearConnection[linearIssue]
+
+// linearConnection is one page of a paginated GraphQL list.
+type linearConnection[T any] struct {
+	Nodes    []T            `json:"nodes"`
 	PageInfo linearPageInfo `json:"pageInfo"`
 }
 
+type linearIssueCommentsData struct {
+	Issue *struct {
+		Comments linearCommentNodes `json:"comments"`
+	} `json:"issue"`
+}
+
 type linearIssue struct {
-	Identifier    string            `json:"identifier"`
-	Title         string            `json:"title"`
-	URL           string            `json:"url"`
-	Description   string            `json:"description"`
-	State         *linearName       `json:"state"`
-	Assignee      *linearUser       `json:"assignee"`
-	Creator       *linearUser       `json:"creator"`
-	Team          *linearTeam       `json:"team"`
-	Labels        *linearLabelNodes `json:"labels"`
-	Cycle         *linearCycle      `json:"cycle"`
-	CreatedAt     string            `json:"createdAt"`
-	UpdatedAt     string            `json:"updatedAt"`
-	CompletedAt   string            `json:"completedAt"`
-	CanceledAt    string            `json:"canceledAt"`
+	Identifier    string              `json:"identifier"`
+	Title         string              `json:"title"`
+	URL           string              `json:"url"`
+	Description   string              `json:"description"`
+	State         *linearState        `json:"state"`
+	Project       *linearName         `json:"project"`
+	Assignee      *linearUser         `json:"assignee"`
+	Creator       *linearUser         `json:"creator"`
+	Team          *linearTeam         `json:"team"`
+	Labels        *linearLabelNodes   `json:"labels"`
+	Cycle         *linearCycle        `json:"cycle"`
+	CreatedAt     string              `json:"createdAt"`
+	UpdatedAt     string              `json:"updatedAt"`
+	CompletedAt   string              `json:"completedAt"`
+	CanceledAt    string              `json:"canceledAt"`
 	Priority      int                 `json:"priority"`
 	PriorityLabel string              `json:"priorityLabel"`
+	Parent        *linearIssueRef     `json:"parent"`
+	Children      *linearIssueRefs    `json:"children"`
+	Attachments   *linearAttachments  `json:"attachments"`
 	Comments      *linearCommentNodes `json:"comments"`
 }
 
+// linearIssueRef identifies a related issue, such as the parent or a sub-issue.
+type linearIssueRef struct {
+	Identifier string       `json:"identifier"`
+	Title      string       `json:"title"`
+	State      *linearState `json:"state"`
+}
+
+type linearIssueRefs struct {
+	Nodes    []linearIssueRef `json:"nodes"`
+	PageInfo *linearPageInfo  `json:"pageInfo,omitempty"`
+}
+
+// linearAttachment links an issue to an external resource: a GitHub pull
+// request (source type "github"), a Slack thread or any other URL.
+type linearAttachment struct {
+	Title      string `json:"title"`
+	Subtitle   string `json:"subtitle,omitempty"`
+	URL        string `json:"url"`
+	SourceType string `json:"sourceType,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type linearAttachments struct {
+	Nodes    []linearAttachment `json:"nodes"`
+	PageInfo *linearPageInfo    `json:"pageInfo,omitempty"`
+}
+
+type linearState struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
 type linearTeam struct {
 	Key  string `json:"key"`
 	Name string `json:"name"`
 }
 
 type linearLabelNodes struct {
-	Nodes []linearName `json:"nodes"`
+	Nodes    []linearName    `json:"nodes"`
+	PageInfo *linearPageInfo `json:"pageInfo,omitempty"`
 }
 
 type linearCycle struct {
@@ -122,7 +217,8 @@ type linearCommentNode struct {
 }
 
 type linearCommentNodes struct {
-	Nodes []linearCommentNode `json:"nodes"`
+	Nodes    []linearCommentNode `json:"nodes"`
+	PageInfo *linearPageInfo     `json:"pageInfo,omitempty"`
 }
 
 type linearPageInfo struct {
@@ -134,10 +230,115 @@ type linearGQLErr struct {
 	Message string `json:"message"`
 }
 
+// LinearMode selects what a LinearIssues source fetches.
+type LinearMode string
+
+const (
+	// LinearModeIssues fetches issues as JSON. It is the default.
+	LinearModeIssues LinearMode = "issues"
+	// LinearModeProjects fetches projects with their description and
+	// updates, rendered as Markdown under projects/.
+	LinearModeProjects LinearMode = "projects"
+	// LinearModeDocuments fetches Linear Documents, rendered as Markdown
+	// under documents/.
+	LinearModeDocuments LinearMode = "documents"
+)
+
+// LinearOptions configures FetchLinearIssuesWithOptions beyond what the
+// LinearIssuesSource schema covers.
+type LinearOptions struct {
+	// Mode selects issues, projects or documents. Teams and the created and
+	// updated ranges of the source apply to all modes.
+	Mode LinearMode
+
+	// Filter narrows the query beyond teams and dates. Projects and
+	// documents only support Filter.Projects.
+	Filter LinearFilter
+
+	// MaxIssues caps the number of fetched issues, projects or documents.
+	// The default is 1000. A warning is logged when more match.
+	MaxIssues int
+}
+
+// LinearFilter holds structured issue filters. Values within a filter are
+// combined with OR, filters with AND.
+type LinearFilter struct {
+	// StateTypes matches workflow state types: "triage", "backlog",
+	// "unstarted", "started", "completed" or "canceled".
+	StateTypes []string
+	// AssigneeEmails matches assignee emails. "unassigned" matches issues
+	// without an assignee.
+	AssigneeEmails []string
+	// Labels matches issues carrying any of the label names.
+	Labels []string
+	// Projects matches project names.
+	Projects []string
+	// CurrentCycle matches issues in the active cycle of their team.
+	CurrentCycle bool
+}
+
+// issuesOnlyZero reports whether none of the filters that only apply to
+// issues are set.
+func (lf LinearFilter) issuesOnlyZero() bool {
+	return len(lf.StateTypes) == 0 && len(lf.AssigneeEmails) == 0 && len(lf.Labels) == 0 && !lf.CurrentCycle
+}
+
+// apply adds the set filters to the GraphQL filter object f.
+func (lf LinearFilter) apply(f map[string]any) {
+	if len(lf.StateTypes) > 0 {
+		f["state"] = map[string]any{"type": map[string]any{"in": lf.StateTypes}}
+	}
+
+	var emails []string
+	unassigned := false
+	for _, e := range lf.AssigneeEmails {
+		if strings.EqualFold(e, "unassigned") {
+			unassigned = true
+		} else {
+			emails = append(emails, e)
+		}
+	}
+	byEmail := map[string]any{"email": map[string]any{"in": emails}}
+	switch {
+	case unassigned && len(emails) > 0:
+		f["or"] = []any{
+			map[string]any{"assignee": byEmail},
+			map[string]any{"assignee": map[string]any{"null": true}},
+		}
+	case unassigned:
+		f["assignee"] = map[string]any{"null": true}
+	case len(emails) > 0:
+		f["assignee"] = byEmail
+	}
+
+	if len(lf.Labels) > 0 {
+		f["labels"] = map[string]any{"some": map[string]any{"name": map[string]any{"in": lf.Labels}}}
+	}
+	if len(lf.Projects) > 0 {
+		f["project"] = map[string]any{"name": map[string]any{"in": lf.Projects}}
+	}
+	if lf.CurrentCycle {
+		f["cycle"] = map[string]any{"isActive": map[string]any{"eq": true}}
+	}
+}
+
+func (o LinearOptions) maxIssues() int {
+	if o.MaxIssues > 0 {
+		return o.MaxIssues
+	}
+	return linearMaxIssues
+}
+
 // FetchLinearIssues fetches issues from the Linear GraphQL API and returns
 // a structured IssuesResult containing a summary list and per-issue JSON.
 // The token parameter is the Linear API key used in the Authorization header.
 func FetchLinearIssues(ctx context.Context, src *recipes.LinearIssuesSource, token string) (*IssuesResult, error) {
+	return FetchLinearIssuesWithOptions(ctx, src, token, LinearOptions{})
+}
+
+// FetchLinearIssuesWithOptions behaves like FetchLinearIssues and narrows the
+// fetched issues as configured by opts.
+func FetchLinearIssuesWithOptions(ctx context.Context, src *recipes.LinearIssuesSource, token string, opts LinearOptions) (*IssuesResult, error) {
 	if src == nil {
 		return nil, fmt.Errorf("linear issues source cannot be nil")
 	}
@@ -154,13 +355,67 @@ func FetchLinearIssues(ctx context.Context, src *recipes.LinearIssuesSource, tok
 		baseURL = "https://api.linear.app/graphql"
 	}
 
-	filter := buildLinearFilter(teams, src.GetFilter())
+	switch opts.Mode {
+	case "", LinearModeIssues:
+	case LinearModeProjects, LinearModeDocuments:
+		if !opts.Filter.issuesOnlyZero() {
+			return nil, fmt.Errorf("linear %s mode only supports the project filter", opts.Mode)
+		}
+		if opts.Mode == LinearModeProjects {
+			return fetchLinearProjects(ctx, baseURL, token, teams, src.GetFilter(), opts)
+		}
+		return fetchLinearDocuments(ctx, baseURL, token, teams, src.GetFilter(), opts)
+	default:
+		return nil, fmt.Errorf("unsupported linear mode %q", opts.Mode)
+	}
 
-	var allIssues []linearIssue
-	var cursor string
+	filter := buildLinearFilter(teams, src.GetFilter(), opts.Filter)
+	maxIssues := opts.maxIssues()
+
+	allIssues, truncated, err := linearFetchAll(ctx, baseURL, token, linearQuery, filter, maxIssues,
+		func(d *linearData) *linearIssuesData { return &d.Issues })
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		slog.Warn("Linear issues truncated: more issues match the filter than the configured maximum", "max", maxIssues, "teams", teams)
+	}
+
+	forEachConcurrent(ctx, maxPRFetchConcurrency, allIssues, func(ctx context.Context, issue *linearIssue) {
+		if err := fetchLinearRemainingComments(ctx, baseURL, token, issue); err != nil {
+			slog.Warn("Failed to fetch all Linear comments", "identifier", issue.Identifier, "err", err)
+		}
+		warnLinearTruncated(issue)
+	})
+
+	slog.Debug("Linear issues fetched", "count", len(allIssues))
 
+	result := &IssuesResult{
+		Summary: make([]IssueSummary, 0, len(allIssues)),
+		Issues:  make(map[string]string, len(allIssues)),
+	}
+	for _, issue := range allIssues {
+		result.Summary = append(result.Summary, IssueSummary{
+			ID:    issue.Identifier,
+			Title: issue.Title,
+		})
+		raw, err := json.MarshalIndent(issue, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal linear issue %s: %w", issue.Identifier, err)
+		}
+		result.Issues[issue.Identifier] = string(raw)
+	}
+	return result, nil
+}
+
+// linearFetchAll pages through the connection selected by conn until the last
+// page or until maxItems nodes were fetched. It reports whether more nodes
+// matched than it returns.
+func linearFetchAll[D, T any](ctx context.Context, baseURL, token, query string, filter map[string]any, maxItems int, conn func(*D) *linearConnection[T]) ([]T, bool, error) {
+	var all []T
+	var cursor string
 	for {
-		variables := map[string]any{}
+		variables := map[string]any{"first": min(linearPageSize, maxItems-len(all))}
 		if filter != nil {
 			variables["filter"] = filter
 		}
@@ -168,86 +423,120 @@ func FetchLinearIssues(ctx context.Context, src *recipes.LinearIssuesSource, tok
 			variables["after"] = cursor
 		}
 
-		reqBody := linearGraphQLRequest{
-			Query:     linearQuery,
-			Variables: variables,
-		}
-
-		body, err := json.Marshal(reqBody)
+		data, err := linearPost[D](ctx, baseURL, token, query, variables)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal linear request: %w", err)
+			return nil, false, err
 		}
-
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL, bytes.NewReader(body))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create linear request: %w", err)
+		if data == nil {
+			return all, false, nil
 		}
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", token)
 
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch from linear: %w", err)
-		}
+		page := conn(data)
+		all = append(all, page.Nodes...)
+		slog.Debug("Linear pagination", "cursor", cursor, "fetchedSoFar", len(all))
 
-		respBody, err := io.ReadAll(resp.Body)
-		_ = resp.Body.Close()
-		if err != nil {
-			return nil, fmt.Errorf("failed to read linear response: %w", err)
+		if len(all) >= maxItems {
+			return all[:maxItems], len(all) > maxItems || page.PageInfo.HasNextPage, nil
 		}
-
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("linear API returned status %d: %s", resp.StatusCode, string(respBody))
+		if !page.PageInfo.HasNextPage {
+			return all, false, nil
 		}
+		cursor = page.PageInfo.EndCursor
+	}
+}
 
-		var gqlResp linearGraphQLResponse
-		if err := json.Unmarshal(respBody, &gqlResp); err != nil {
-			return nil, fmt.Errorf("failed to parse linear response: %w", err)
-		}
+// linearPost sends a GraphQL query to the Linear API and returns its data,
+// which is nil when the response carries none.
+func linearPost[T any](ctx context.Context, baseURL, token, query string, variables map[string]any) (*T, error) {
+	body, err := json.Marshal(linearGraphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal linear request: %w", err)
+	}
 
-		if len(gqlResp.Errors) > 0 {
-			msgs := make([]string, len(gqlResp.Errors))
-			for i, e := range gqlResp.Errors {
-				msgs[i] = e.Message
-			}
-			return nil, fmt.Errorf("linear API returned errors: %s", strings.Join(msgs, "; "))
-		}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create linear request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", token)
 
-		if gqlResp.Data == nil {
-			break
-		}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from linear: %w", err)
+	}
 
-		allIssues = append(allIssues, gqlResp.Data.Issues.Nodes...)
-		slog.Debug("Linear pagination", "cursor", cursor, "issuesSoFar", len(allIssues))
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read linear response: %w", err)
+	}
 
-		if !gqlResp.Data.Issues.PageInfo.HasNextPage || len(allIssues) >= linearMaxIssues {
-			break
-		}
-		cursor = gqlResp.Data.Issues.PageInfo.EndCursor
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("linear API returned status %d: %s", resp.StatusCode, string(respBody))
 	}
 
-	slog.Debug("Linear issues fetched", "count", len(allIssues))
+	var gqlResp linearGraphQLResponse[T]
+	if err := json.Unmarshal(respBody, &gqlResp); err != nil {
+		return nil, fmt.Errorf("failed to parse linear response: %w", err)
+	}
 
-	result := &IssuesResult{
-		Summary: make([]IssueSummary, 0, len(allIssues)),
-		Issues:  make(map[string]string, len(allIssues)),
+	if len(gqlResp.Errors) > 0 {
+		msgs := make([]string, len(gqlResp.Errors))
+		for i, e := range gqlResp.Errors {
+			msgs[i] = e.Message
+		}
+		return nil, fmt.Errorf("linear API returned errors: %s", strings.Join(msgs, "; "))
 	}
-	for _, issue := range allIssues {
-		result.Summary = append(result.Summary, IssueSummary{
-			ID:    issue.Identifier,
-			Title: issue.Title,
-		})
-		raw, err := json.MarshalIndent(issue, "", "  ")
+	return gqlResp.Data, nil
+}
+
+// fetchLinearRemainingComments appends the comments beyond the first page the
+// issues query returns inline.
+func fetchLinearRemainingComments(ctx context.Context, baseURL, token string, issue *linearIssue) error {
+	c := issue.Comments
+	if c == nil {
+		return nil
+	}
+	// The cursor is not part of the issue output.
+	defer func() { c.PageInfo = nil }()
+	for c.PageInfo != nil && c.PageInfo.HasNextPage {
+		variables := map[string]any{"id": issue.Identifier, "after": c.PageInfo.EndCursor}
+		data, err := linearPost[linearIssueCommentsData](ctx, baseURL, token, linearCommentsQuery, variables)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal linear issue %s: %w", issue.Identifier, err)
+			return err
 		}
-		result.Issues[issue.Identifier] = string(raw)
+		if data == nil || data.Issue == nil {
+			return nil
+		}
+		c.Nodes = append(c.Nodes, data.Issue.Comments.Nodes...)
+		c.PageInfo = data.Issue.Comments.PageInfo
 	}
-	return result, nil
+	return nil
 }
 
-// buildLinearFilter constructs a GraphQL filter object from teams and IssuesFilter.
-func buildLinearFilter(teams []string, filter *recipes.IssuesFilter) map[string]any {
+// warnLinearTruncated logs the nested lists of issue that linearQuery cut at
+// their limit and drops their page info, which is not part of the output.
+func warnLinearTruncated(issue *linearIssue) {
+	truncated := func(what string, limit int, info **linearPageInfo) {
+		if *info != nil && (*info).HasNextPage {
+			slog.Warn("Linear issue "+what+" truncated", "identifier", issue.Identifier, "max", limit)
+		}
+		*info = nil
+	}
+	if issue.Labels != nil {
+		truncated("labels", linearLabelsLimit, &issue.Labels.PageInfo)
+	}
+	if issue.Children != nil {
+		truncated("sub-issues", linearChildrenLimit, &issue.Children.PageInfo)
+	}
+	if issue.Attachments != nil {
+		truncated("attachments", linearAttachmentsLimit, &issue.Attachments.PageInfo)
+	}
+}
+
+// buildLinearFilter constructs a GraphQL filter object from teams, IssuesFilter
+// and the structured filters of LinearOptions.
+func buildLinearFilter(teams []string, filter *recipes.IssuesFilter, linearFilter LinearFilter) map[string]any {
 	f := map[string]any{}
 
 	if len(teams) > 0 {
@@ -258,34 +547,8 @@ func buildLinearFilter(teams []string, filter *recipes.IssuesFilter) map[string]
 		}
 	}
 
-	if filter != nil {
-		if filter.HasCreatedAtFilter() {
-			cf := filter.GetCreatedAtFilter()
-			dateFilter := map[string]any{}
-			if cf.HasFrom() {
-				dateFilter["gte"] = formatTimestampISO(cf.GetFrom())
-			}
-			if cf.HasTo() {
-				dateFilter["lte"] = formatTimestampISO(cf.GetTo())
-			}
-			if len(dateFilter) > 0 {
-				f["createdAt"] = dateFilter
-			}
-		}
-		if filter.HasUpdatedAtFilter() {
-			uf := filter.GetUpdatedAtFilter()
-			dateFilter := map[string]any{}
-			if uf.HasFrom() {
-				dateFilter["gte"] = formatTimestampISO(uf.GetFrom())
-			}
-			if uf.HasTo() {
-				dateFilter["lte"] = formatTimestampISO(uf.GetTo())
-			}
-			if len(dateFilter) > 0 {
-				f["updatedAt"] = dateFilter
-			}
-		}
-	}
+	applyLinearDateFilters(f, filter)
+	linearFilter.apply(f)
 
 	if len(f) == 0 {
 		return nil
@@ -293,6 +556,40 @@ func buildLinearFilter(teams []string, filter *recipes.IssuesFilter) map[string]
 	return f
 }
 
+// applyLinearDateFilters adds the created and updated ranges of filter to the
+// GraphQL filter object f.
+func applyLinearDateFilters(f map[string]any, filter *recipes.IssuesFilter) {
+	if filter == nil {
+		return
+	}
+	if filter.HasCreatedAtFilter() {
+		cf := filter.GetCreatedAtFilter()
+		dateFilter := map[string]any{}
+		if cf.HasFrom() {
+			dateFilter["gte"] = formatTimestampISO(cf.GetFrom())
+		}
+		if cf.HasTo() {
+			dateFilter["lte"] = formatTimestampISO(cf.GetTo())
+		}
+		if len(dateFilter) > 0 {
+			f["createdAt"] = dateFilter
+		}
+	}
+	if filter.HasUpdatedAtFilter() {
+		uf := filter.GetUpdatedAtFilter()
+		dateFilter := map[string]any{}
+		if uf.HasFrom() {
+			dateFilter["gte"] = formatTimestampISO(uf.GetFrom())
+		}
+		if uf.HasTo() {
+			dateFilter["lte"] = formatTimestampISO(uf.GetTo())
+		}
+		if len(dateFilter) > 0 {
+			f["updatedAt"] = dateFilter
+		}
+	}
+}
+
 // formatTimestampISO formats a protobuf timestamp as an ISO 8601 string.
 func formatTimestampISO(ts *timestamppb.Timestamp) string {
 	if ts == nil {
@@ -0,0 +1,296 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/opensdd/osdd-api/clients/go/osdd/recipes"
+)
+
+const linearProjectsQuery = `query($filter: ProjectFilter, $after: String, $first: Int) {
+  projects(filter: $filter, after: $after, first: $first) {
+    nodes {
+      name
+      slugId
+      url
+      description
+      content
+      status { name }
+      lead { name email }
+      teams(first: 10) { nodes { key name } }
+      startDate
+      targetDate
+      progress
+      createdAt
+      updatedAt
+      projectUpdates(first: 10) {
+        nodes {
+          body
+          health
+          user { name email }
+          createdAt
+        }
+      }
+    }
+    pageInfo {
+      hasNextPage
+      endCursor
+    }
+  }
+}`
+
+const linearDocumentsQuery = `query($filter: DocumentFilter, $after: String, $first: Int) {
+  documents(filter: $filter, after: $after, first: $first) {
+    nodes {
+      title
+      slugId
+      url
+      content
+      creator { name email }
+      project { name }
+      createdAt
+      updatedAt
+    }
+    pageInfo {
+      hasNextPage
+      endCursor
+    }
+  }
+}`
+
+type linearProjectsData struct {
+	Projects linearConnection[linearProject] `json:"projects"`
+}
+
+type linearDocumentsData struct {
+	Documents linearConnection[linearDocument] `json:"documents"`
+}
+
+// linearProject is a Linear project. Description is the one-line summary,
+// Content the full project description in Markdown.
+type linearProject struct {
+	Name        string      `json:"name"`
+	SlugID      string      `json:"slugId"`
+	URL         string      `json:"url"`
+	Description string      `json:"description"`
+	Content     string      `json:"content"`
+	Status      *linearName `json:"status"`
+	Lead        *linearUser `json:"lead"`
+	Teams       *struct {
+		Nodes []linearTeam `json:"nodes"`
+	} `json:"teams"`
+	StartDate      string  `json:"startDate"`
+	TargetDate     string  `json:"targetDate"`
+	Progress       float64 `json:"progress"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+	ProjectUpdates *struct {
+		Nodes []linearProjectUpdate `json:"nodes"`
+	} `json:"projectUpdates"`
+}
+
+type linearProjectUpdate struct {
+	Body      string      `json:"body"`
+	Health    string      `json:"health"`
+	User      *linearUser `json:"user"`
+	CreatedAt string      `json:"createdAt"`
+}
+
+type linearDocument struct {
+	Title     string      `json:"title"`
+	SlugID    string      `json:"slugId"`
+	URL       string      `json:"url"`
+	Content   string      `json:"content"`
+	Creator   *linearUser `json:"creator"`
+	Project   *linearName `json:"project"`
+	CreatedAt string      `json:"createdAt"`
+	UpdatedAt string      `json:"updatedAt"`
+}
+
+var linearDocFuncs = template.FuncMap{
+	"user": linearUserDisplay,
+	"percent": func(f float64) string {
+		return fmt.Sprintf("%.0f%%", f*100)
+	},
+	"teams": func(teams []linearTeam) string {
+		keys := make([]string, len(teams))
+		for i, t := range teams {
+			keys[i] = t.Key
+		}
+		return strings.Join(keys, ", ")
+	},
+}
+
+var linearProjectTmpl = template.Must(template.New("linear-project").Funcs(linearDocFuncs).Parse(
+	`# {{.Name}}
+{{with .Status}}
+**Status:** {{.Name}}
+{{- end}}
+{{- with .Lead}}
+**Lead:** {{user .}}
+{{- end}}
+{{- with .Teams}}{{if .Nodes}}
+**Teams:** {{teams .Nodes}}
+{{- end}}{{end}}
+{{- if .StartDate}}
+**Start Date:** {{.StartDate}}
+{{- end}}
+{{- if .TargetDate}}
+**Target Date:** {{.TargetDate}}
+{{- end}}
+**Progress:** {{percent .Progress}}
+{{- if .URL}}
+**URL:** {{.URL}}
+{{- end}}
+{{if .Description}}
+{{.Description}}
+{{end}}{{if .Content}}
+## Description
+
+{{.Content}}
+{{end}}{{with .ProjectUpdates}}{{if .Nodes}}
+## Updates
+{{range .Nodes}}
+### {{with .User}}{{user .}}{{else}}Unknown{{end}}{{if .CreatedAt}} ({{.CreatedAt}}){{end}}{{if .Health}} — {{.Health}}{{end}}
+
+{{.Body}}
+{{end}}{{end}}{{end}}`))
+
+var linearDocumentTmpl = template.Must(template.New("linear-document").Funcs(linearDocFuncs).Parse(
+	`# {{.Title}}
+{{with .Project}}
+**Project:** {{.Name}}
+{{- end}}
+{{- with .Creator}}
+**Creator:** {{user .}}
+{{- end}}
+{{- if .CreatedAt}}
+**Created:** {{.CreatedAt}}
+{{- end}}
+{{- if .UpdatedAt}}
+**Updated:** {{.UpdatedAt}}
+{{- end}}
+{{- if .URL}}
+**URL:** {{.URL}}
+{{- end}}
+{{if .Content}}
+{{.Content}}
+{{end}}`))
+
+// fetchLinearProjects fetches the projects accessible to teams and renders
+// each as a Markdown file under projects/.
+func fetchLinearProjects(ctx context.Context, baseURL, token string, teams []string, filter *recipes.IssuesFilter, opts LinearOptions) (*IssuesResult, error) {
+	f := buildLinearProjectFilter(teams, opts.Filter.Projects)
+	applyLinearDateFilters(f, filter)
+	if len(f) == 0 {
+		f = nil
+	}
+
+	maxItems := opts.maxIssues()
+	projects, truncated, err := linearFetchAll(ctx, baseURL, token, linearProjectsQuery, f, maxItems,
+		func(d *linearProjectsData) *linearConnection[linearProject] { return &d.Projects })
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		slog.Warn("Linear projects truncated: more projects match the filter than the configured maximum", "max", maxItems, "teams", teams)
+	}
+	slog.Debug("Linear projects fetched", "count", len(projects))
+
+	result := &IssuesResult{
+		Summary: make([]IssueSummary, 0, len(projects)),
+		Issues:  make(map[string]string, len(projects)),
+		Format:  IssueFormatMarkdown,
+		Folder:  "projects",
+	}
+	for _, p := range projects {
+		var buf bytes.Buffer
+		if err := linearProjectTmpl.Execute(&buf, p); err != nil {
+			return nil, fmt.Errorf("failed to render linear project %s: %w", p.Name, err)
+		}
+		id := linearSlug(p.URL, p.SlugID)
+		result.Summary = append(result.Summary, IssueSummary{ID: id, Title: p.Name})
+		result.Issues[id] = buf.String()
+	}
+	return result, nil
+}
+
+// fetchLinearDocuments fetches the documents of the projects accessible to
+// teams and renders each as a Markdown file under documents/.
+func fetchLinearDocuments(ctx context.Context, baseURL, token string, teams []string, filter *recipes.IssuesFilter, opts LinearOptions) (*IssuesResult, error) {
+	f := map[string]any{}
+	if pf := buildLinearProjectFilter(teams, opts.Filter.Projects); len(pf) > 0 {
+		f["project"] = pf
+	}
+	applyLinearDateFilters(f, filter)
+	if len(f) == 0 {
+		f = nil
+	}
+
+	maxItems := opts.maxIssues()
+	documents, truncated, err := linearFetchAll(ctx, baseURL, token, linearDocumentsQuery, f, maxItems,
+		func(d *linearDocumentsData) *linearConnection[linearDocument] { return &d.Documents })
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		slog.Warn("Linear documents truncated: more documents match the filter than the configured maximum", "max", maxItems, "teams", teams)
+	}
+	slog.Debug("Linear documents fetched", "count", len(documents))
+
+	result := &IssuesResult{
+		Summary: make([]IssueSummary, 0, len(documents)),
+		Issues:  make(map[string]string, len(documents)),
+		Format:  IssueFormatMarkdown,
+		Folder:  "documents",
+	}
+	for _, d := range documents {
+		var buf bytes.Buffer
+		if err := linearDocumentTmpl.Execute(&buf, d); err != nil {
+			return nil, fmt.Errorf("failed to render linear document %s: %w", d.Title, err)
+		}
+		id := linearSlug(d.URL, d.SlugID)
+		result.Summary = append(result.Summary, IssueSummary{ID: id, Title: d.Title})
+		result.Issues[id] = buf.String()
+	}
+	return result, nil
+}
+
+// buildLinearProjectFilter constructs a ProjectFilter from teams and project names.
+func buildLinearProjectFilter(teams, projects []string) map[string]any {
+	f := map[string]any{}
+	if len(teams) > 0 {
+		f["accessibleTeams"] = map[string]any{
+			"some": map[string]any{"key": map[string]any{"in": teams}},
+		}
+	}
+	if len(projects) > 0 {
+		f["name"] = map[string]any{"in": projects}
+	}
+	return f
+}
+
+// linearSlug returns the readable file name Linear uses in the URL of a
+// project or document, e.g. "api-redesign-8f3a2b1c9d0e", falling back to
+// the bare slug id.
+func linearSlug(rawURL, slugID string) string {
+	if base := path.Base(rawURL); strings.HasSuffix(base, slugID) && slugID != "" {
+		return base
+	}
+	return slugID
+}
+
+func linearUserDisplay(u *linearUser) string {
+	if u == nil {
+		return ""
+	}
+	if u.Email != "" {
+		return u.Name + " (" + u.Email + ")"
+	}
+	return u.Name
+}
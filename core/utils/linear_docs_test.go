@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withLinearServer(t *testing.T, data string) *linearGraphQLRequest {
+	t.Helper()
+	var received linearGraphQLRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":` + data + `}`))
+	}))
+	t.Cleanup(server.Close)
+	old := linearBaseURL
+	linearBaseURL = server.URL
+	t.Cleanup(func() { linearBaseURL = old })
+	return &received
+}
+
+func TestFetchLinearIssues_Projects(t *testing.T) {
+	received := withLinearServer(t, `{"projects":{"nodes":[{
+		"name":"API Redesign","slugId":"8f3a2b1c9d0e","url":"https://linear.app/org/project/api-redesign-8f3a2b1c9d0e",
+		"description":"New public API","content":"## Goals\n\nFaster.","status":{"name":"In Progress"},
+		"lead":{"name":"Alice","email":"alice@example.com"},"teams":{"nodes":[{"key":"ENG"},{"key":"API"}]},
+		"targetDate":"2025-09-30","progress":0.4,
+		"projectUpdates":{"nodes":[{"body":"Schema frozen.","health":"onTrack","user":{"name":"Alice"},"createdAt":"2025-06-01T10:00:00.000Z"}]}
+	}],"pageInfo":{"hasNextPage":false}}}`)
+
+	src := linearSource("ws", []string{"ENG"}, nil, nil)
+	result, err := FetchLinearIssuesWithOptions(context.Background(), src, "test-token", LinearOptions{
+		Mode:   LinearModeProjects,
+		Filter: LinearFilter{Projects: []string{"API Redesign"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, linearProjectsQuery, received.Query)
+	assert.Equal(t, map[string]any{
+		"accessibleTeams": map[string]any{"some": map[string]any{"key": map[string]any{"in": []any{"ENG"}}}},
+		"name":            map[string]any{"in": []any{"API Redesign"}},
+	}, received.Variables["filter"])
+
+	assert.Equal(t, "projects", result.Folder)
+	assert.Equal(t, IssueFormatMarkdown, result.Format)
+	assert.Equal(t, []IssueSummary{{ID: "api-redesign-8f3a2b1c9d0e", Title: "API Redesign"}}, result.Summary)
+	assert.Equal(t, "# API Redesign\n\n"+
+		"**Status:** In Progress\n"+
+		"**Lead:** Alice (alice@example.com)\n"+
+		"**Teams:** ENG, API\n"+
+		"**Target Date:** 2025-09-30\n"+
+		"**Progress:** 40%\n"+
+		"**URL:** https://linear.app/org/project/api-redesign-8f3a2b1c9d0e\n\n"+
+		"New public API\n\n"+
+		"## Description\n\n## Goals\n\nFaster.\n\n"+
+		"## Updates\n\n"+
+		"### Alice (2025-06-01T10:00:00.000Z) — onTrack\n\nSchema frozen.\n",
+		result.Issues["api-redesign-8f3a2b1c9d0e"])
+}
+
+func TestFetchLinearIssues_Documents(t *testing.T) {
+	received := withLinearServer(t, `{"documents":{"nodes":[{
+		"title":"Auth spec","slugId":"abc123","content":"Tokens expire after 1h.",
+		"creator":{"name":"Bob"},"project":{"name":"API Redesign"},"updatedAt":"2025-06-02T10:00:00.000Z"
+	}],"pageInfo":{"hasNextPage":false}}}`)
+
+	src := linearSource("ws", []string{"ENG"}, nil, nil)
+	result, err := FetchLinearIssuesWithOptions(context.Background(), src, "test-token", LinearOptions{Mode: LinearModeDocuments})
+	require.NoError(t, err)
+
+	assert.Equal(t, linearDocumentsQuery, received.Query)
+	assert.Equal(t, map[string]any{"project": map[string]any{
+		"accessibleTeams": map[string]any{"some": map[string]any{"key": map[string]any{"in": []any{"ENG"}}}},
+	}}, received.Variables["filter"])
+
+	assert.Equal(t, "documents", result.Folder)
+	assert.Equal(t, []IssueSummary{{ID: "abc123", Title: "Auth spec"}}, result.Summary)
+	assert.Equal(t, "# Auth spec\n\n"+
+		"**Project:** API Redesign\n"+
+		"**Creator:** Bob\n"+
+		"**Updated:** 2025-06-02T10:00:00.000Z\n\n"+
+		"Tokens expire after 1h.\n", result.Issues["abc123"])
+}
+
+func TestLinearSlug(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "api-redesign-8f3a", linearSlug("https://linear.app/org/project/api-redesign-8f3a", "8f3a"))
+	assert.Equal(t, "8f3a", linearSlug("https://linear.app/org/project/other", "8f3a"))
+	assert.Equal(t, "8f3a", linearSlug("", "8f3a"))
+}
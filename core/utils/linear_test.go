@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -27,7 +28,7 @@ func linearSource(workspace string, teams []string, authEnvVar *string, filter *
 
 func linearGQLResponse(issues []linearIssue, hasNextPage bool, endCursor string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		resp := linearGraphQLResponse{
+		resp := linearGraphQLResponse[linearData]{
 			Data: &linearData{
 				Issues: linearIssuesData{
 					Nodes: issues,
@@ -64,7 +65,7 @@ func TestFetchLinearIssues_Success(t *testing.T) {
 			Identifier:    "TEAM-1",
 			Title:         "First issue",
 			Description:   "Description of first issue",
-			State:         &linearName{Name: "In Progress"},
+			State:         &linearState{Name: "In Progress"},
 			Assignee:      &linearUser{Name: "Bob"},
 			CreatedAt:     "2025-01-15T10:00:00.000Z",
 			UpdatedAt:     "2025-02-01T14:30:00.000Z",
@@ -75,7 +76,7 @@ func TestFetchLinearIssues_Success(t *testing.T) {
 			Identifier:    "TEAM-2",
 			Title:         "Second issue",
 			Description:   "Description of second issue",
-			State:         &linearName{Name: "Done"},
+			State:         &linearState{Name: "Done"},
 			CreatedAt:     "2025-01-20T08:00:00.000Z",
 			UpdatedAt:     "2025-02-05T09:00:00.000Z",
 			Priority:      3,
@@ -126,7 +127,7 @@ func TestFetchLinearIssues_AuthHeader(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		receivedAuth = r.Header.Get("Authorization")
 		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(linearGraphQLResponse{Data: &linearData{}})
+		_ = json.NewEncoder(w).Encode(linearGraphQLResponse[linearData]{Data: &linearData{}})
 	}))
 	defer server.Close()
 
@@ -159,7 +160,7 @@ func TestFetchLinearIssues_HTTPError(t *testing.T) {
 
 func TestFetchLinearIssues_GraphQLErrors(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		resp := linearGraphQLResponse{
+		resp := linearGraphQLResponse[linearData]{
 			Errors: []linearGQLErr{
 				{Message: "unauthorized"},
 				{Message: "invalid query"},
@@ -186,24 +187,24 @@ func TestFetchLinearIssues_Pagination(t *testing.T) {
 	callCount := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		callCount++
-		var resp linearGraphQLResponse
+		var resp linearGraphQLResponse[linearData]
 		if callCount == 1 {
-			resp = linearGraphQLResponse{
+			resp = linearGraphQLResponse[linearData]{
 				Data: &linearData{
 					Issues: linearIssuesData{
 						Nodes: []linearIssue{
-							{Identifier: "T-1", Title: "Issue 1", State: &linearName{Name: "Open"}, PriorityLabel: "High"},
+							{Identifier: "T-1", Title: "Issue 1", State: &linearState{Name: "Open"}, PriorityLabel: "High"},
 						},
 						PageInfo: linearPageInfo{HasNextPage: true, EndCursor: "cursor1"},
 					},
 				},
 			}
 		} else {
-			resp = linearGraphQLResponse{
+			resp = linearGraphQLResponse[linearData]{
 				Data: &linearData{
 					Issues: linearIssuesData{
 						Nodes: []linearIssue{
-							{Identifier: "T-2", Title: "Issue 2", State: &linearName{Name: "Done"}, PriorityLabel: "Low"},
+							{Identifier: "T-2", Title: "Issue 2", State: &linearState{Name: "Done"}, PriorityLabel: "Low"},
 						},
 						PageInfo: linearPageInfo{HasNextPage: false},
 					},
@@ -237,7 +238,7 @@ func TestFetchLinearIssues_FilterInRequest(t *testing.T) {
 		_ = json.NewDecoder(r.Body).Decode(&req)
 		receivedVars = req.Variables
 		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(linearGraphQLResponse{Data: &linearData{}})
+		_ = json.NewEncoder(w).Encode(linearGraphQLResponse[linearData]{Data: &linearData{}})
 	}))
 	defer server.Close()
 
@@ -262,7 +263,7 @@ func TestFetchLinearIssues_FilterInRequest(t *testing.T) {
 
 func TestBuildLinearFilter_TeamsOnly(t *testing.T) {
 	t.Parallel()
-	f := buildLinearFilter([]string{"ENG"}, nil)
+	f := buildLinearFilter([]string{"ENG"}, nil, LinearFilter{})
 	require.NotNil(t, f)
 	team, ok := f["team"].(map[string]any)
 	require.True(t, ok)
@@ -275,7 +276,7 @@ func TestBuildLinearFilter_TeamsOnly(t *testing.T) {
 
 func TestBuildLinearFilter_Empty(t *testing.T) {
 	t.Parallel()
-	f := buildLinearFilter(nil, nil)
+	f := buildLinearFilter(nil, nil, LinearFilter{})
 	assert.Nil(t, f)
 }
 
@@ -286,7 +287,7 @@ func TestBuildLinearFilter_WithDates(t *testing.T) {
 		CreatedAtFilter: osdd.DatesFilter_builder{From: ts}.Build(),
 	}.Build()
 
-	f := buildLinearFilter(nil, filter)
+	f := buildLinearFilter(nil, filter, LinearFilter{})
 	require.NotNil(t, f)
 	created, ok := f["createdAt"].(map[string]any)
 	require.True(t, ok)
@@ -302,7 +303,7 @@ func TestBuildLinearFilter_TeamsAndDates(t *testing.T) {
 		UpdatedAtFilter: osdd.DatesFilter_builder{From: from}.Build(),
 	}.Build()
 
-	f := buildLinearFilter([]string{"TEAM"}, filter)
+	f := buildLinearFilter([]string{"TEAM"}, filter, LinearFilter{})
 	require.NotNil(t, f)
 	assert.Contains(t, f, "team")
 	assert.Contains(t, f, "createdAt")
@@ -315,3 +316,147 @@ func TestFormatTimestampISO(t *testing.T) {
 	assert.Equal(t, "2025-06-15T12:30:00.000Z", formatTimestampISO(ts))
 	assert.Equal(t, "", formatTimestampISO(nil))
 }
+
+func TestBuildLinearFilter_LinearFilter(t *testing.T) {
+	t.Parallel()
+	f := buildLinearFilter([]string{"ENG"}, nil, LinearFilter{
+		StateTypes:     []string{"started", "unstarted"},
+		AssigneeEmails: []string{"bob@example.com"},
+		Labels:         []string{"bug"},
+		Projects:       []string{"Roadmap"},
+		CurrentCycle:   true,
+	})
+	assert.Equal(t, map[string]any{
+		"team":     map[string]any{"key": map[string]any{"in": []string{"ENG"}}},
+		"state":    map[string]any{"type": map[string]any{"in": []string{"started", "unstarted"}}},
+		"assignee": map[string]any{"email": map[string]any{"in": []string{"bob@example.com"}}},
+		"labels":   map[string]any{"some": map[string]any{"name": map[string]any{"in": []string{"bug"}}}},
+		"project":  map[string]any{"name": map[string]any{"in": []string{"Roadmap"}}},
+		"cycle":    map[string]any{"isActive": map[string]any{"eq": true}},
+	}, f)
+}
+
+func TestBuildLinearFilter_Unassigned(t *testing.T) {
+	t.Parallel()
+	f := buildLinearFilter(nil, nil, LinearFilter{AssigneeEmails: []string{"Unassigned"}})
+	assert.Equal(t, map[string]any{"assignee": map[string]any{"null": true}}, f)
+
+	f = buildLinearFilter(nil, nil, LinearFilter{AssigneeEmails: []string{"bob@example.com", "unassigned"}})
+	assert.Equal(t, map[string]any{"or": []any{
+		map[string]any{"assignee": map[string]any{"email": map[string]any{"in": []string{"bob@example.com"}}}},
+		map[string]any{"assignee": map[string]any{"null": true}},
+	}}, f)
+}
+
+func TestFetchLinearIssues_MaxIssues(t *testing.T) {
+	var firsts []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req linearGraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		firsts = append(firsts, req.Variables["first"])
+		resp := linearGraphQLResponse[linearData]{Data: &linearData{Issues: linearIssuesData{
+			Nodes:    []linearIssue{{Identifier: fmt.Sprintf("T-%d", len(firsts)*2-1)}, {Identifier: fmt.Sprintf("T-%d", len(firsts)*2)}},
+			PageInfo: linearPageInfo{HasNextPage: true, EndCursor: "next"},
+		}}}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	old := linearBaseURL
+	linearBaseURL = server.URL
+	defer func() { linearBaseURL = old }()
+
+	src := linearSource("ws", nil, nil, nil)
+	result, err := FetchLinearIssuesWithOptions(context.Background(), src, "test-token", LinearOptions{
+		MaxIssues: 3,
+		Filter:    LinearFilter{StateTypes: []string{"started"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []any{float64(3), float64(1)}, firsts)
+	require.Len(t, result.Summary, 3)
+	assert.Equal(t, "T-3", result.Summary[2].ID)
+}
+
+func TestFetchLinearIssues_CommentPaginationAndRelations(t *testing.T) {
+	var afters []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req linearGraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if req.Query == linearCommentsQuery {
+			assert.Equal(t, "T-1", req.Variables["id"])
+			afters = append(afters, req.Variables["after"])
+			comments := linearCommentNodes{PageInfo: &linearPageInfo{HasNextPage: len(afters) == 1, EndCursor: "c2"}}
+			comments.Nodes = []linearCommentNode{{Body: fmt.Sprintf("comment %d", len(afters)+1)}}
+			_, _ = fmt.Fprintf(w, `{"data":{"issue":{"comments":%s}}}`, mustJSON(t, comments))
+			return
+		}
+		issue := linearIssue{
+			Identifier: "T-1",
+			Parent:     &linearIssueRef{Identifier: "T-0", Title: "Epic"},
+			Children: &linearIssueRefs{
+				Nodes:    []linearIssueRef{{Identifier: "T-2", Title: "Sub", State: &linearState{Name: "Todo", Type: "unstarted"}}},
+				PageInfo: &linearPageInfo{HasNextPage: true},
+			},
+			Attachments: &linearAttachments{Nodes: []linearAttachment{
+				{Title: "Add feature #12", URL: "https://github.com/org/repo/pull/12", SourceType: "github"},
+			}},
+			Comments: &linearCommentNodes{
+				Nodes:    []linearCommentNode{{Body: "comment 1"}},
+				PageInfo: &linearPageInfo{HasNextPage: true, EndCursor: "c1"},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(linearGraphQLResponse[linearData]{Data: &linearData{Issues: linearIssuesData{Nodes: []linearIssue{issue}}}})
+	}))
+	defer server.Close()
+
+	old := linearBaseURL
+	linearBaseURL = server.URL
+	defer func() { linearBaseURL = old }()
+
+	result, err := FetchLinearIssues(context.Background(), linearSource("ws", nil, nil, nil), "test-token")
+	require.NoError(t, err)
+	assert.Equal(t, []any{"c1", "c2"}, afters)
+
+	var issue linearIssue
+	require.NoError(t, json.Unmarshal([]byte(result.Issues["T-1"]), &issue))
+	require.Len(t, issue.Comments.Nodes, 3)
+	assert.Equal(t, "comment 3", issue.Comments.Nodes[2].Body)
+	assert.NotContains(t, result.Issues["T-1"], "pageInfo")
+	assert.Equal(t, "T-0", issue.Parent.Identifier)
+	assert.Equal(t, "T-2", issue.Children.Nodes[0].Identifier)
+	assert.Equal(t, "github", issue.Attachments.Nodes[0].SourceType)
+}
+
+func TestLinearQueries_LimitNestedConnections(t *testing.T) {
+	t.Parallel()
+	for _, limit := range []string{"labels(first: 20)", "children(first: 25)", "attachments(first: 25)", "comments(first: 20)"} {
+		assert.Contains(t, linearQuery, limit)
+	}
+	assert.Contains(t, linearProjectsQuery, "teams(first: 10)")
+	assert.Contains(t, linearProjectsQuery, "projectUpdates(first: 10)")
+	assert.Equal(t, 25, linearPageSize)
+}
+
+func TestFetchLinearIssues_InvalidMode(t *testing.T) {
+	t.Parallel()
+	src := linearSource("ws", nil, nil, nil)
+	_, err := FetchLinearIssuesWithOptions(context.Background(), src, "test-token", LinearOptions{Mode: "cycles"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported linear mode "cycles"`)
+
+	_, err = FetchLinearIssuesWithOptions(context.Background(), src, "test-token", LinearOptions{
+		Mode:   LinearModeProjects,
+		Filter: LinearFilter{Labels: []string{"bug"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only supports the project filter")
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
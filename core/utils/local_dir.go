@@ -0,0 +1,360 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkPolicy controls how ReadLocalDirectory treats symbolic links.
+type SymlinkPolicy string
+
+const (
+	// SymlinkSkip ignores symbolic links. It is the default.
+	SymlinkSkip SymlinkPolicy = "skip"
+	// SymlinkFollow reads linked files and descends into linked directories.
+	SymlinkFollow SymlinkPolicy = "follow"
+	// SymlinkError fails when a symbolic link is encountered.
+	SymlinkError SymlinkPolicy = "error"
+)
+
+// DirectoryOptions configures reading a local directory as a context source.
+// Patterns are slash-separated globs relative to the directory root in which
+// "**" matches any number of directories, e.g. "**/*.md".
+type DirectoryOptions struct {
+	// Include selects files to read; all files are read when empty.
+	Include []string
+	// Exclude removes matching files. A matching directory is skipped entirely.
+	Exclude []string
+	// Gitignore skips files ignored by .gitignore files found in the directory
+	// tree and, when the directory is inside a git repository, in its parent
+	// directories up to the repository root.
+	Gitignore bool
+	// MaxFileSize skips files larger than this many bytes. Zero means no limit.
+	MaxFileSize int64
+	// MaxTotalSize fails when the selected files exceed this many bytes in total.
+	// Zero means no limit.
+	MaxTotalSize int64
+	// Symlinks is the symbolic link policy; empty means SymlinkSkip.
+	Symlinks SymlinkPolicy
+}
+
+// LocalFile is a file read by ReadLocalDirectory.
+type LocalFile struct {
+	// Path is relative to the directory root and slash-separated.
+	Path    string
+	Content []byte
+}
+
+// SplitGlobRoot splits a path such as "docs/adr/**/*.md" into the directory
+// to walk ("docs/adr") and the pattern relative to it ("**/*.md"). The pattern
+// is empty when p contains no glob characters.
+func SplitGlobRoot(p string) (root, pattern string) {
+	segments := strings.Split(filepath.ToSlash(p), "/")
+	for i, s := range segments {
+		if strings.ContainsAny(s, "*?[") {
+			root = strings.Join(segments[:i], "/")
+			if root == "" && i > 0 {
+				root = "/"
+			}
+			if root == "" {
+				root = "."
+			}
+			return filepath.FromSlash(root), strings.Join(segments[i:], "/")
+		}
+	}
+	return p, ""
+}
+
+// ReadLocalDirectory reads the files under root selected by opts, sorted by path.
+// ".git" directories are always skipped.
+func ReadLocalDirectory(root string, opts DirectoryOptions) ([]LocalFile, error) {
+	if strings.TrimSpace(root) == "" {
+		return nil, fmt.Errorf("local directory path cannot be empty")
+	}
+	for _, p := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", p, err)
+		}
+	}
+	switch opts.Symlinks {
+	case "", SymlinkSkip, SymlinkFollow, SymlinkError:
+	default:
+		return nil, fmt.Errorf("unknown symlink policy %q", opts.Symlinks)
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local directory %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("local path %s is not a directory", root)
+	}
+
+	var ignores []gitignoreRule
+	if opts.Gitignore {
+		if ignores, err = parentGitignores(root); err != nil {
+			return nil, err
+		}
+	}
+	w := &dirWalker{opts: opts, visited: map[string]bool{}}
+	if err := w.walk(root, "", ignores); err != nil {
+		return nil, err
+	}
+	return w.files, nil
+}
+
+// parentGitignores reads the .gitignore files of the directories between the
+// root of the git repository containing dir and dir itself, outermost first.
+// Rules are scoped to dir so they apply to paths relative to it. Nothing is
+// read when dir is not inside a repository.
+func parentGitignores(dir string) ([]gitignoreRule, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	if isGitCheckout(abs) {
+		// dir is the repository root; the walk reads its .gitignore.
+		return nil, nil
+	}
+	var parents []string
+	for d := filepath.Dir(abs); ; d = filepath.Dir(d) {
+		parents = append(parents, d)
+		if isGitCheckout(d) {
+			break
+		}
+		if filepath.Dir(d) == d {
+			return nil, nil
+		}
+	}
+
+	var rules []gitignoreRule
+	for i := len(parents) - 1; i >= 0; i-- {
+		prefix, err := filepath.Rel(parents[i], abs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+		}
+		parentRules, err := readGitignore(filepath.Join(parents[i], ".gitignore"), "")
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range parentRules {
+			r.prefix = filepath.ToSlash(prefix)
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+type dirWalker struct {
+	opts    DirectoryOptions
+	files   []LocalFile
+	total   int64
+	visited map[string]bool // resolved directories, guards against symlink cycles
+}
+
+func (w *dirWalker) walk(dir, rel string, ignores []gitignoreRule) error {
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		if w.visited[real] {
+			return nil
+		}
+		w.visited[real] = true
+	}
+
+	if w.opts.Gitignore {
+		rules, err := readGitignore(filepath.Join(dir, ".gitignore"), rel)
+		if err != nil {
+			return err
+		}
+		ignores = append(ignores[:len(ignores):len(ignores)], rules...)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		full := filepath.Join(dir, e.Name())
+		relPath := path.Join(rel, e.Name())
+
+		mode := e.Type()
+		if mode&fs.ModeSymlink != 0 {
+			switch w.opts.Symlinks {
+			case SymlinkFollow:
+				target, err := os.Stat(full)
+				if err != nil {
+					return fmt.Errorf("failed to resolve symlink %s: %w", full, err)
+				}
+				mode = target.Mode().Type()
+			case SymlinkError:
+				return fmt.Errorf("symlink not allowed in local directory source: %s", full)
+			default:
+				slog.Debug("Skipping symlink in local directory source", "path", full)
+				continue
+			}
+		}
+
+		isDir := mode.IsDir()
+		if isDir && e.Name() == ".git" {
+			continue
+		}
+		if matchAny(w.opts.Exclude, relPath) || gitignored(ignores, relPath, isDir) {
+			continue
+		}
+		if isDir {
+			if err := w.walk(full, relPath, ignores); err != nil {
+				return err
+			}
+			continue
+		}
+		if !mode.IsRegular() {
+			continue
+		}
+		if len(w.opts.Include) > 0 && !matchAny(w.opts.Include, relPath) {
+			continue
+		}
+		if err := w.readFile(full, relPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *dirWalker) readFile(full, rel string) error {
+	info, err := os.Stat(full)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", full, err)
+	}
+	if w.opts.MaxFileSize > 0 && info.Size() > w.opts.MaxFileSize {
+		slog.Warn("Skipping file larger than size limit", "path", full, "size", info.Size(), "limit", w.opts.MaxFileSize)
+		return nil
+	}
+	content, err := os.ReadFile(full)
+	if err != nil {
+		return fmt.Errorf("failed to read local file %s: %w", full, err)
+	}
+	w.total += int64(len(content))
+	if w.opts.MaxTotalSize > 0 && w.total > w.opts.MaxTotalSize {
+		return fmt.Errorf("local directory files exceed total size limit of %d bytes at %s", w.opts.MaxTotalSize, rel)
+	}
+	w.files = append(w.files, LocalFile{Path: rel, Content: content})
+	return nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if MatchGlob(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchGlob reports whether the slash-separated name matches pattern, where
+// "**" matches zero or more path segments and other segments follow path.Match.
+func MatchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pat, name []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			pat = pat[1:]
+			if len(pat) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pat, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pat[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pat, name = pat[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// gitignoreRule is a single .gitignore pattern scoped to the directory (base)
+// of the file it was read from. Rules read from a parent of the walked
+// directory carry the walked directory's path relative to that parent (prefix).
+type gitignoreRule struct {
+	base    string
+	prefix  string
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+func readGitignore(file, base string) ([]gitignoreRule, error) {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	var rules []gitignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := gitignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		// Patterns without an inner slash match at any depth below base.
+		if strings.HasPrefix(line, "/") {
+			line = line[1:]
+		} else if !strings.Contains(line, "/") {
+			line = "**/" + line
+		}
+		r.pattern = line
+		rules = append(rules, r)
+	}
+	return rules, scanner.Err()
+}
+
+// gitignored applies rules in order; the last matching rule wins.
+func gitignored(rules []gitignoreRule, rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		sub := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") {
+				continue
+			}
+			sub = strings.TrimPrefix(rel, r.base+"/")
+		}
+		if r.prefix != "" {
+			sub = path.Join(r.prefix, sub)
+		}
+		if MatchGlob(r.pattern, sub) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
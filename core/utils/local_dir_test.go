@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o644))
+	}
+}
+
+func localPaths(files []LocalFile) []string {
+	var out []string
+	for _, f := range files {
+		out = append(out, f.Path)
+	}
+	return out
+}
+
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"**/*.md", "a.md", true},
+		{"**/*.md", "x/y/a.md", true},
+		{"**/*.md", "x/a.txt", false},
+		{"docs/*.md", "docs/a.md", true},
+		{"docs/*.md", "docs/x/a.md", false},
+		{"**/draft-*", "adr/draft-1.md", true},
+		{"drafts/**", "drafts", true},
+		{"drafts/**", "drafts/a.md", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchGlob(tt.pattern, tt.name), "%s ~ %s", tt.pattern, tt.name)
+	}
+}
+
+func TestSplitGlobRoot(t *testing.T) {
+	t.Parallel()
+	root, pattern := SplitGlobRoot("docs/adr/**/*.md")
+	assert.Equal(t, filepath.FromSlash("docs/adr"), root)
+	assert.Equal(t, "**/*.md", pattern)
+
+	root, pattern = SplitGlobRoot("*.md")
+	assert.Equal(t, ".", root)
+	assert.Equal(t, "*.md", pattern)
+
+	root, pattern = SplitGlobRoot("docs/adr")
+	assert.Equal(t, "docs/adr", root)
+	assert.Empty(t, pattern)
+}
+
+func TestReadLocalDirectory_IncludeExclude(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"0001-intro.md":         "intro",
+		"sub/0002-design.md":    "design",
+		"sub/draft-0003.md":     "draft",
+		"drafts/0004.md":        "wip",
+		"notes.txt":             "notes",
+		".git/HEAD":             "ref",
+		"sub/deeper/0005-x.md":  "x",
+		"sub/deeper/image.png":  "png",
+		"sub/deeper/README.txt": "readme",
+	})
+
+	files, err := ReadLocalDirectory(root, DirectoryOptions{
+		Include: []string{"**/*.md"},
+		Exclude: []string{"**/draft-*", "drafts/**"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0001-intro.md", "sub/0002-design.md", "sub/deeper/0005-x.md"}, localPaths(files))
+	assert.Equal(t, "intro", string(files[0].Content))
+}
+
+func TestReadLocalDirectory_Gitignore(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":         "*.log\nbuild/\n/secret.md\n!keep.log\n",
+		"a.md":               "a",
+		"debug.log":          "log",
+		"keep.log":           "keep",
+		"build/out.md":       "out",
+		"secret.md":          "s",
+		"sub/secret.md":      "not anchored",
+		"sub/.gitignore":     "local.md\n",
+		"sub/local.md":       "local",
+		"other/local.md":     "other",
+		"sub/nested/app.log": "log",
+	})
+
+	files, err := ReadLocalDirectory(root, DirectoryOptions{Gitignore: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{".gitignore", "a.md", "keep.log", "other/local.md", "sub/.gitignore", "sub/secret.md"}, localPaths(files))
+
+	all, err := ReadLocalDirectory(root, DirectoryOptions{})
+	require.NoError(t, err)
+	assert.Len(t, all, 11)
+}
+
+func TestReadLocalDirectory_ParentGitignore(t *testing.T) {
+	t.Parallel()
+	repo := t.TempDir()
+	writeTree(t, repo, map[string]string{
+		".git/HEAD":           "ref: refs/heads/main\n",
+		".gitignore":          "*.log\n/docs/drafts/\n",
+		"docs/.gitignore":     "private.md\n",
+		"docs/a.md":           "a",
+		"docs/debug.log":      "log",
+		"docs/drafts/d.md":    "draft",
+		"docs/private.md":     "p",
+		"docs/sub/b.md":       "b",
+		"docs/sub/c.log":      "log",
+		"docs/sub/private.md": "p",
+	})
+
+	files, err := ReadLocalDirectory(filepath.Join(repo, "docs", "sub"), DirectoryOptions{Gitignore: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.md"}, localPaths(files))
+
+	files, err = ReadLocalDirectory(filepath.Join(repo, "docs"), DirectoryOptions{Gitignore: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{".gitignore", "a.md", "sub/b.md"}, localPaths(files))
+
+	// Outside a repository only the walked tree's .gitignore files apply.
+	require.NoError(t, os.RemoveAll(filepath.Join(repo, ".git")))
+	files, err = ReadLocalDirectory(filepath.Join(repo, "docs"), DirectoryOptions{Gitignore: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{".gitignore", "a.md", "debug.log", "drafts/d.md", "sub/b.md", "sub/c.log"}, localPaths(files))
+}
+
+func TestReadLocalDirectory_SizeLimits(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"small.md": "12345", "big.md": "1234567890"})
+
+	files, err := ReadLocalDirectory(root, DirectoryOptions{MaxFileSize: 5})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"small.md"}, localPaths(files))
+
+	_, err = ReadLocalDirectory(root, DirectoryOptions{MaxTotalSize: 12})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceed total size limit of 12 bytes")
+}
+
+func TestReadLocalDirectory_Symlinks(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeTree(t, root, map[string]string{"a.md": "a"})
+	writeTree(t, outside, map[string]string{"linked/b.md": "b", "c.md": "c"})
+	require.NoError(t, os.Symlink(filepath.Join(outside, "linked"), filepath.Join(root, "dir-link")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "c.md"), filepath.Join(root, "file-link.md")))
+	require.NoError(t, os.Symlink(root, filepath.Join(root, "loop")))
+
+	files, err := ReadLocalDirectory(root, DirectoryOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.md"}, localPaths(files))
+
+	files, err = ReadLocalDirectory(root, DirectoryOptions{Symlinks: SymlinkFollow})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.md", "dir-link/b.md", "file-link.md"}, localPaths(files))
+
+	_, err = ReadLocalDirectory(root, DirectoryOptions{Symlinks: SymlinkError})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "symlink not allowed")
+}
+
+func TestReadLocalDirectory_Errors(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"a.md": "a"})
+
+	_, err := ReadLocalDirectory(filepath.Join(root, "a.md"), DirectoryOptions{})
+	assert.ErrorContains(t, err, "is not a directory")
+
+	_, err = ReadLocalDirectory(root, DirectoryOptions{Include: []string{"[bad"}})
+	assert.ErrorContains(t, err, "invalid glob pattern")
+
+	_, err = ReadLocalDirectory(root, DirectoryOptions{Symlinks: "sometimes"})
+	assert.ErrorContains(t, err, "unknown symlink policy")
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"context"
+	"io"
+)
+
+// ProgressFunc receives the cumulative number of bytes transferred and the
+// expected total, which is -1 when unknown.
+type ProgressFunc func(bytes, total int64)
+
+type progressKey struct{}
+
+// WithProgress returns a context whose downloads report progress to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// readAllWithProgress reads r to the end, reporting progress to the
+// ProgressFunc carried by ctx, if any.
+func readAllWithProgress(ctx context.Context, r io.Reader, total int64) ([]byte, error) {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	if fn == nil {
+		return io.ReadAll(r)
+	}
+	return io.ReadAll(&progressReader{r: r, total: total, fn: fn})
+}
+
+type progressReader struct {
+	r     io.Reader
+	read  int64
+	total int64
+	fn    ProgressFunc
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.read += int64(n)
+		p.fn(p.read, p.total)
+	}
+	return n, err
+}
@@ -23,3 +23,15 @@ func ExportBitbucketAPIBaseURL() string { return bitbucketAPIBaseURL }
 
 // SetBitbucketAPIBaseURL overrides the Bitbucket API base URL (for cross-package tests).
 func SetBitbucketAPIBaseURL(url string) { bitbucketAPIBaseURL = url }
+
+// ExportGitLabAPIBaseURL returns the current GitLab API base URL (for cross-package tests).
+func ExportGitLabAPIBaseURL() string { return gitlabAPIBaseURL }
+
+// SetGitLabAPIBaseURL overrides the GitLab API base URL (for cross-package tests).
+func SetGitLabAPIBaseURL(url string) { gitlabAPIBaseURL = url }
+
+// ExportGiteaAPIBaseURL returns the current Gitea API base URL (for cross-package tests).
+func ExportGiteaAPIBaseURL() string { return giteaAPIBaseURL }
+
+// SetGiteaAPIBaseURL overrides the Gitea API base URL (for cross-package tests).
+func SetGiteaAPIBaseURL(url string) { giteaAPIBaseURL = url }
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+// tokenEncoding loads the cl100k_base encoding once; building it is expensive.
+var tokenEncoding = sync.OnceValues(func() (*tiktoken.Tiktoken, error) {
+	return tiktoken.GetEncoding("cl100k_base")
+})
+
+// CountTokens estimates the number of tokens in text using cl100k_base encoding.
+func CountTokens(text string) int {
+	enc, err := tokenEncoding()
+	if err != nil {
+		// Fallback: rough estimate of 4 chars per token.
+		return len(text) / 4
+	}
+	return len(enc.Encode(text, nil, nil))
+}
+
+// TruncateToTokens returns the longest prefix of text that fits in maxTokens
+// tokens, as counted by CountTokens.
+func TruncateToTokens(text string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	enc, err := tokenEncoding()
+	if err != nil {
+		// Fallback mirrors CountTokens: 4 bytes per token, cut at a rune boundary.
+		n := maxTokens * 4
+		if n >= len(text) {
+			return text
+		}
+		for n > 0 && !utf8.RuneStart(text[n]) {
+			n--
+		}
+		return text[:n]
+	}
+	tokens := enc.Encode(text, nil, nil)
+	if len(tokens) <= maxTokens {
+		return text
+	}
+	// A token boundary may split a multi-byte rune; drop the partial rune.
+	prefix := enc.Decode(tokens[:maxTokens])
+	for len(prefix) > 0 && !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateToTokens(t *testing.T) {
+	t.Parallel()
+	text := strings.Repeat("hello world ", 50)
+
+	assert.Equal(t, text, TruncateToTokens(text, CountTokens(text)))
+	assert.Empty(t, TruncateToTokens(text, 0))
+
+	cut := TruncateToTokens(text, 10)
+	assert.True(t, strings.HasPrefix(text, cut))
+	assert.LessOrEqual(t, CountTokens(cut), 10)
+	assert.Positive(t, len(cut))
+
+	multiByte := strings.Repeat("héllo wörld ", 20)
+	assert.True(t, utf8.ValidString(TruncateToTokens(multiByte, 7)))
+}
@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TransformKind selects the operation of a Transform.
+type TransformKind string
+
+const (
+	// TransformJSONPath replaces JSON content with the value at Path.
+	TransformJSONPath TransformKind = "json_path"
+	// TransformFilterLines keeps the lines matching Pattern, or drops them when Invert is set.
+	TransformFilterLines TransformKind = "filter_lines"
+	// TransformStripFrontMatter removes a leading YAML ("---") or TOML ("+++") front-matter block.
+	TransformStripFrontMatter TransformKind = "strip_front_matter"
+	// TransformCodeFence wraps the content in a Markdown code fence tagged with Language.
+	TransformCodeFence TransformKind = "code_fence"
+	// TransformHeading prepends a Markdown heading with Text at Level (default 1).
+	TransformHeading TransformKind = "heading"
+	// TransformTrimLines keeps the first Lines lines.
+	TransformTrimLines TransformKind = "trim_lines"
+)
+
+// Transform is a single step of a content transform pipeline. Only the fields
+// used by Kind are read.
+type Transform struct {
+	Kind TransformKind
+
+	// Path is the JSON path for TransformJSONPath: dot-separated keys with
+	// optional array indexes, e.g. "$.items[0].name" or "items.0.name". A "*"
+	// index selects every element. String results are written as-is, other
+	// values as indented JSON.
+	Path string
+
+	// Pattern is the regular expression for TransformFilterLines.
+	Pattern string
+	// Invert drops matching lines instead of keeping them.
+	Invert bool
+
+	// Language tags the fence of TransformCodeFence.
+	Language string
+
+	// Text and Level configure TransformHeading.
+	Text  string
+	Level int
+
+	// Lines is the number of lines kept by TransformTrimLines.
+	Lines int
+}
+
+// ApplyTransforms runs transforms over content in order.
+func ApplyTransforms(content string, transforms []Transform) (string, error) {
+	for i, t := range transforms {
+		var err error
+		content, err = t.apply(content)
+		if err != nil {
+			return "", fmt.Errorf("transform %d (%s) failed: %w", i, t.Kind, err)
+		}
+	}
+	return content, nil
+}
+
+func (t Transform) apply(content string) (string, error) {
+	switch t.Kind {
+	case TransformJSONPath:
+		return extractJSONPath(content, t.Path)
+	case TransformFilterLines:
+		re, err := regexp.Compile(t.Pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid pattern %q: %w", t.Pattern, err)
+		}
+		return filterLines(content, re, t.Invert), nil
+	case TransformStripFrontMatter:
+		return stripFrontMatter(content), nil
+	case TransformCodeFence:
+		return codeFence(content, t.Language), nil
+	case TransformHeading:
+		level := t.Level
+		if level == 0 {
+			level = 1
+		}
+		if level < 1 || level > 6 {
+			return "", fmt.Errorf("heading level must be between 1 and 6, got %d", t.Level)
+		}
+		return strings.Repeat("#", level) + " " + t.Text + "\n\n" + content, nil
+	case TransformTrimLines:
+		if t.Lines <= 0 {
+			return "", fmt.Errorf("lines must be positive, got %d", t.Lines)
+		}
+		return trimLines(content, t.Lines), nil
+	default:
+		return "", fmt.Errorf("unknown transform kind %q", t.Kind)
+	}
+}
+
+// extractJSONPath returns the value at path within the JSON document content.
+func extractJSONPath(content, path string) (string, error) {
+	var doc any
+	if err := json.Unmarshal([]byte(content), &doc); err != nil {
+		return "", fmt.Errorf("content is not valid JSON: %w", err)
+	}
+	segments, err := splitJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+	value, err := lookupJSONPath(doc, segments)
+	if err != nil {
+		return "", fmt.Errorf("json path %q: %w", path, err)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	out, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal json path result: %w", err)
+	}
+	return string(out), nil
+}
+
+// splitJSONPath turns "$.a.b[0]" or "a.b.0" into ["a", "b", "0"].
+func splitJSONPath(path string) ([]string, error) {
+	p := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	p = strings.ReplaceAll(p, "[", ".")
+	p = strings.ReplaceAll(p, "]", "")
+	var segments []string
+	for _, s := range strings.Split(p, ".") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	if len(segments) == 0 && p != "" {
+		return nil, fmt.Errorf("invalid json path %q", path)
+	}
+	return segments, nil
+}
+
+func lookupJSONPath(value any, segments []string) (any, error) {
+	for i, seg := range segments {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[seg]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", seg)
+			}
+			value = next
+		case []any:
+			if seg == "*" {
+				all := make([]any, 0, len(v))
+				for _, elem := range v {
+					r, err := lookupJSONPath(elem, segments[i+1:])
+					if err != nil {
+						return nil, err
+					}
+					all = append(all, r)
+				}
+				return all, nil
+			}
+			idx, err := strconv.Atoi(seg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index %q", seg)
+			}
+			if idx < 0 {
+				idx += len(v)
+			}
+			if idx < 0 || idx >= len(v) {
+				return nil, fmt.Errorf("index %s out of range (length %d)", seg, len(v))
+			}
+			value = v[idx]
+		default:
+			return nil, fmt.Errorf("cannot select %q from a scalar value", seg)
+		}
+	}
+	return value, nil
+}
+
+func filterLines(content string, re *regexp.Regexp, invert bool) string {
+	lines := strings.SplitAfter(content, "\n")
+	var b strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		if re.MatchString(strings.TrimRight(line, "\r\n")) != invert {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// stripFrontMatter removes a front-matter block delimited by "---" (YAML) or
+// "+++" (TOML) lines at the very start of content.
+func stripFrontMatter(content string) string {
+	for _, delim := range []string{"---", "+++"} {
+		first, rest, ok := strings.Cut(content, "\n")
+		if !ok || strings.TrimRight(first, "\r") != delim {
+			continue
+		}
+		for rest != "" {
+			var line string
+			line, rest, _ = strings.Cut(rest, "\n")
+			if strings.TrimRight(line, "\r") == delim {
+				return strings.TrimLeft(rest, "\r\n")
+			}
+		}
+	}
+	return content
+}
+
+// codeFence wraps content in a backtick fence longer than any backtick run
+// inside it, so the content cannot close the fence early.
+func codeFence(content, language string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return fence + language + "\n" + content + fence + "\n"
+}
+
+func trimLines(content string, n int) string {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) <= n {
+		return content
+	}
+	return strings.Join(lines[:n], "")
+}
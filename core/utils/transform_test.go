@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTransforms(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		content    string
+		transforms []Transform
+		want       string
+		wantErr    string
+	}{
+		{
+			name:       "json path string value",
+			content:    `{"items":[{"name":"first"},{"name":"second"}]}`,
+			transforms: []Transform{{Kind: TransformJSONPath, Path: "$.items[1].name"}},
+			want:       "second",
+		},
+		{
+			name:       "json path dotted index and object value",
+			content:    `{"items":[{"name":"first","tags":["a"]}]}`,
+			transforms: []Transform{{Kind: TransformJSONPath, Path: "items.0.tags"}},
+			want:       "[\n  \"a\"\n]",
+		},
+		{
+			name:       "json path wildcard",
+			content:    `{"items":[{"id":1},{"id":2}]}`,
+			transforms: []Transform{{Kind: TransformJSONPath, Path: "$.items[*].id"}},
+			want:       "[\n  1,\n  2\n]",
+		},
+		{
+			name:       "json path missing key",
+			content:    `{"a":1}`,
+			transforms: []Transform{{Kind: TransformJSONPath, Path: "b"}},
+			wantErr:    `transform 0 (json_path) failed: json path "b": key "b" not found`,
+		},
+		{
+			name:       "json path on invalid json",
+			content:    "not json",
+			transforms: []Transform{{Kind: TransformJSONPath, Path: "a"}},
+			wantErr:    "content is not valid JSON",
+		},
+		{
+			name:       "filter lines",
+			content:    "ERROR one\ninfo two\nERROR three\n",
+			transforms: []Transform{{Kind: TransformFilterLines, Pattern: "^ERROR"}},
+			want:       "ERROR one\nERROR three\n",
+		},
+		{
+			name:       "filter lines inverted",
+			content:    "ERROR one\ninfo two\nERROR three",
+			transforms: []Transform{{Kind: TransformFilterLines, Pattern: "^ERROR", Invert: true}},
+			want:       "info two\n",
+		},
+		{
+			name:       "filter lines invalid pattern",
+			content:    "x",
+			transforms: []Transform{{Kind: TransformFilterLines, Pattern: "("}},
+			wantErr:    "invalid pattern",
+		},
+		{
+			name:       "strip yaml front matter",
+			content:    "---\ntitle: Doc\n---\n\n# Body\n",
+			transforms: []Transform{{Kind: TransformStripFrontMatter}},
+			want:       "# Body\n",
+		},
+		{
+			name:       "strip toml front matter",
+			content:    "+++\ntitle = \"Doc\"\n+++\nBody",
+			transforms: []Transform{{Kind: TransformStripFrontMatter}},
+			want:       "Body",
+		},
+		{
+			name:       "unterminated front matter is kept",
+			content:    "---\ntitle: Doc\n",
+			transforms: []Transform{{Kind: TransformStripFrontMatter}},
+			want:       "---\ntitle: Doc\n",
+		},
+		{
+			name:       "code fence",
+			content:    "package main",
+			transforms: []Transform{{Kind: TransformCodeFence, Language: "go"}},
+			want:       "```go\npackage main\n```\n",
+		},
+		{
+			name:       "code fence longer than inner fence",
+			content:    "```sh\nls\n```\n",
+			transforms: []Transform{{Kind: TransformCodeFence, Language: "markdown"}},
+			want:       "````markdown\n```sh\nls\n```\n````\n",
+		},
+		{
+			name:       "heading",
+			content:    "body",
+			transforms: []Transform{{Kind: TransformHeading, Text: "Build output", Level: 2}},
+			want:       "## Build output\n\nbody",
+		},
+		{
+			name:       "heading invalid level",
+			content:    "body",
+			transforms: []Transform{{Kind: TransformHeading, Text: "x", Level: 7}},
+			wantErr:    "heading level must be between 1 and 6",
+		},
+		{
+			name:       "trim lines",
+			content:    "1\n2\n3\n4\n",
+			transforms: []Transform{{Kind: TransformTrimLines, Lines: 2}},
+			want:       "1\n2\n",
+		},
+		{
+			name:       "trim lines shorter content",
+			content:    "1\n2",
+			transforms: []Transform{{Kind: TransformTrimLines, Lines: 5}},
+			want:       "1\n2",
+		},
+		{
+			name:    "pipeline runs in order",
+			content: `{"log":"ok a\nfail b\nok c\nok d"}`,
+			transforms: []Transform{
+				{Kind: TransformJSONPath, Path: "log"},
+				{Kind: TransformFilterLines, Pattern: "^ok"},
+				{Kind: TransformTrimLines, Lines: 2},
+				{Kind: TransformCodeFence, Language: "text"},
+				{Kind: TransformHeading, Text: "Log"},
+			},
+			want: "# Log\n\n```text\nok a\nok c\n```\n",
+		},
+		{
+			name:       "unknown kind",
+			content:    "x",
+			transforms: []Transform{{Kind: "upper"}},
+			wantErr:    `unknown transform kind "upper"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ApplyTransforms(tt.content, tt.transforms)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -3,7 +3,6 @@ package utils
 import (
 	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 )
@@ -28,29 +27,36 @@ func ValidateURL(rawURL string) error {
 // uses an http or https scheme. Non-2xx status codes are treated as errors.
 // Retry logic is NOT handled here — callers are expected to retry as needed.
 func FetchURL(ctx context.Context, rawURL string) ([]byte, error) {
+	body, _, err := FetchURLWithContentType(ctx, rawURL)
+	return body, err
+}
+
+// FetchURLWithContentType is FetchURL that also returns the Content-Type
+// header of the response.
+func FetchURLWithContentType(ctx context.Context, rawURL string) ([]byte, string, error) {
 	if err := ValidateURL(rawURL); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request for %s: %w", rawURL, err)
+		return nil, "", fmt.Errorf("failed to create request for %s: %w", rawURL, err)
 	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", rawURL, err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("url fetch %s returned status %d", rawURL, resp.StatusCode)
+		return nil, "", fmt.Errorf("url fetch %s returned status %d", rawURL, resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readAllWithProgress(ctx, resp.Body, resp.ContentLength)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body from %s: %w", rawURL, err)
+		return nil, "", fmt.Errorf("failed to read response body from %s: %w", rawURL, err)
 	}
 
-	return body, nil
+	return body, resp.Header.Get("Content-Type"), nil
 }
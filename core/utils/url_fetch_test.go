@@ -143,3 +143,25 @@ func TestFetchURL_ContextCancellation(t *testing.T) {
 	_, err := FetchURL(ctx, server.URL)
 	require.Error(t, err)
 }
+
+func TestFetchURL_ReportsProgress(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	var lastBytes, lastTotal int64
+	calls := 0
+	ctx := WithProgress(context.Background(), func(bytes, total int64) {
+		calls++
+		lastBytes, lastTotal = bytes, total
+	})
+
+	body, err := FetchURL(ctx, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(body))
+	assert.Positive(t, calls)
+	assert.Equal(t, int64(11), lastBytes)
+	assert.Equal(t, int64(11), lastTotal)
+}
@@ -7,6 +7,7 @@ require (
 	github.com/opensdd/osdd-api/clients/go v0.8.3
 	github.com/pkoukk/tiktoken-go v0.1.8
 	github.com/stretchr/testify v1.11.1
+	golang.org/x/net v0.46.0
 	google.golang.org/protobuf v1.36.10
 	gopkg.in/yaml.v3 v3.0.1
 )